@@ -0,0 +1,262 @@
+// Package admin 提供运维用的 HTTP 接口：查看 Group 的统计信息和最热的 key，
+// 删除单个 key 或者清空整个 Group，导出和导入快照，以及查看当前的一致性哈希环。
+// 所有请求需要带上令牌，应当只在内网的管理端口上提供：
+//
+//	h := admin.NewHandler("secret", admin.WithRing(pool))
+//	http.Handle("/admin/", http.StripPrefix("/admin", h))
+//
+// 接口列表（路径相对于挂载的位置，group 和 key 需要经过 URL 编码）：
+//
+//	GET    /groups                     全部 Group 的统计信息
+//	GET    /groups/<group>             单个 Group 的统计信息
+//	GET    /groups/<group>/hotkeys?n=  最热的 n 个 key（默认 10），需要 WithHotKeyDetection
+//	DELETE /groups/<group>/keys        清空本节点上 Group 的缓存
+//	DELETE /groups/<group>/keys/<key>  删除 key，与 Group.Remove 一样通知其他节点
+//	GET    /groups/<group>/snapshot    导出 mainCache 的快照
+//	PUT    /groups/<group>/snapshot    从请求体导入快照
+//	GET    /ring?key=                  全部节点、被移出的节点以及负责 key 的节点
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	gocache "go-cache"
+	"go-cache/hotkey"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultHotKeys hotkeys 接口默认返回的 key 的个数
+const defaultHotKeys = 10
+
+// Ring 节点选择器，HTTPPool 和 gRPC 的 Pool 都实现了这个接口。
+// 同时实现 Owner(key string) string 时 /ring 返回负责 key 的节点，
+// 实现 Ejected() []string 时返回被健康检查移出一致性哈希环的节点
+type Ring interface {
+	Peers() []string
+}
+
+// Handler 实现 http.Handler
+type Handler struct {
+	token string
+	ring  Ring
+}
+
+// Option 构造 Handler 时的可选配置
+type Option func(*Handler)
+
+// WithRing 通过 /ring 查看 ring 中的节点
+func WithRing(ring Ring) Option {
+	return func(h *Handler) {
+		h.ring = ring
+	}
+}
+
+// NewHandler 创建 Handler，请求需要通过 Authorization: Bearer <token> 带上令牌。
+// token 不能为空，否则所有请求都会被拒绝
+func NewHandler(token string, opts ...Option) *Handler {
+	h := &Handler{token: token}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// GroupStats 一个 Group 的统计信息
+type GroupStats struct {
+	Name      string
+	Stats     gocache.Stats
+	MainCache gocache.CacheStats
+	HotCache  gocache.CacheStats
+}
+
+// RingInfo /ring 的响应
+type RingInfo struct {
+	Peers   []string
+	Ejected []string `json:",omitempty"`
+	// 请求带有 key 参数时负责它的节点
+	Owner string `json:",omitempty"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	switch {
+	case path == "groups":
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		names := gocache.GroupNames()
+		all := make([]GroupStats, 0, len(names))
+		for _, name := range names {
+			if g := gocache.GetGroup(name); g != nil {
+				all = append(all, groupStats(g))
+			}
+		}
+		writeJSON(w, all)
+	case strings.HasPrefix(path, "groups/"):
+		h.serveGroup(w, r, strings.TrimPrefix(path, "groups/"))
+	case path == "ring":
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		h.serveRing(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveGroup 处理 /groups/<group> 以及它下面的路径
+func (h *Handler) serveGroup(w http.ResponseWriter, r *http.Request, path string) {
+	name, rest, _ := strings.Cut(path, "/")
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		http.Error(w, "bad group name", http.StatusBadRequest)
+		return
+	}
+	g := gocache.GetGroup(name)
+	if g == nil {
+		http.Error(w, "no such group: "+name, http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case rest == "":
+		if allow(w, r, http.MethodGet) {
+			writeJSON(w, groupStats(g))
+		}
+	case rest == "hotkeys":
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		n := defaultHotKeys
+		if s := r.URL.Query().Get("n"); s != "" {
+			if n, err = strconv.Atoi(s); err != nil || n <= 0 {
+				http.Error(w, "bad n", http.StatusBadRequest)
+				return
+			}
+		}
+		keys := g.HottestKeys(n)
+		if keys == nil {
+			keys = []hotkey.KeyStat{}
+		}
+		writeJSON(w, keys)
+	case rest == "keys":
+		if !allow(w, r, http.MethodDelete) {
+			return
+		}
+		log.Printf("[GeeCache] Admin purge group %s", name)
+		g.Purge()
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(rest, "keys/"):
+		if !allow(w, r, http.MethodDelete) {
+			return
+		}
+		key, err := url.PathUnescape(strings.TrimPrefix(rest, "keys/"))
+		if err != nil {
+			http.Error(w, "bad key", http.StatusBadRequest)
+			return
+		}
+		if err := g.Remove(r.Context(), key); err != nil {
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case rest == "snapshot":
+		if !allow(w, r, http.MethodGet, http.MethodPut) {
+			return
+		}
+		if r.Method == http.MethodPut {
+			if err := g.Restore(r.Body); err != nil {
+				code := http.StatusBadRequest
+				if errors.Is(err, gocache.ErrGroupClosed) {
+					code = http.StatusServiceUnavailable
+				}
+				http.Error(w, err.Error(), code)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		// 已经开始写入响应体之后无法再返回错误状态码，只能中断响应
+		if err := g.Snapshot(w); err != nil {
+			log.Printf("[GeeCache] Admin snapshot of group %s failed: %v", name, err)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) serveRing(w http.ResponseWriter, r *http.Request) {
+	if h.ring == nil {
+		http.Error(w, "no ring configured", http.StatusNotFound)
+		return
+	}
+	info := RingInfo{Peers: h.ring.Peers()}
+	if e, ok := h.ring.(interface{ Ejected() []string }); ok {
+		info.Ejected = e.Ejected()
+	}
+	if key := r.URL.Query().Get("key"); key != "" {
+		if o, ok := h.ring.(interface{ Owner(key string) string }); ok {
+			info.Owner = o.Owner(key)
+		}
+	}
+	writeJSON(w, info)
+}
+
+// authorized 检查请求是否带有令牌
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func groupStats(g *gocache.Group) GroupStats {
+	return GroupStats{
+		Name:      g.Name(),
+		Stats:     g.Stats(),
+		MainCache: g.CacheStats(gocache.MainCache),
+		HotCache:  g.CacheStats(gocache.HotCache),
+	}
+}
+
+// allow 检查请求的方法，不允许时返回 405
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("[GeeCache] Admin failed to write response", err)
+	}
+}
+
+// statusOf 返回错误对应的状态码
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, gocache.ErrEmptyKey), errors.Is(err, gocache.ErrKeyTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, gocache.ErrGroupClosed):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
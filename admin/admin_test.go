@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	gocache "go-cache"
+	"go-cache/hotkey"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeRing struct{}
+
+func (fakeRing) Peers() []string         { return []string{"a", "b", "c"} }
+func (fakeRing) Ejected() []string       { return []string{"c"} }
+func (fakeRing) Owner(key string) string { return "b" }
+
+func TestHandler(t *testing.T) {
+	g := gocache.NewGroup("admin", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key + "!"), nil
+		}), gocache.WithHotKeyDetection(10, 0, nil))
+	g.Get(context.Background(), "Tom")
+	g.Get(context.Background(), "a/b")
+
+	srv := httptest.NewServer(http.StripPrefix("/admin", NewHandler("secret", WithRing(fakeRing{}))))
+	defer srv.Close()
+	do := func(method, path string, body io.Reader) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+"/admin"+path, body)
+		req.Header.Set("Authorization", "Bearer secret")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	decode := func(res *http.Response, v any) {
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status %v", res.Status)
+		}
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stats GroupStats
+	decode(do("GET", "/groups/admin", nil), &stats)
+	if stats.Name != "admin" || stats.Stats.Gets != 2 || stats.MainCache.Items != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	var all []GroupStats
+	decode(do("GET", "/groups", nil), &all)
+	if len(all) == 0 {
+		t.Fatalf("all groups should be listed")
+	}
+	var hot []hotkey.KeyStat
+	decode(do("GET", "/groups/admin/hotkeys?n=1", nil), &hot)
+	if len(hot) != 1 {
+		t.Fatalf("expected one hot key, got %v", hot)
+	}
+	var ring RingInfo
+	decode(do("GET", "/ring?key=Tom", nil), &ring)
+	if len(ring.Peers) != 3 || len(ring.Ejected) != 1 || ring.Owner != "b" {
+		t.Fatalf("unexpected ring %+v", ring)
+	}
+
+	snapshot := do("GET", "/groups/admin/snapshot", nil)
+	b, _ := io.ReadAll(snapshot.Body)
+
+	if res := do("DELETE", "/groups/admin/keys/a%2Fb", nil); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("remove: %v", res.Status)
+	}
+	if _, err := g.Peek("a/b"); err == nil {
+		t.Fatalf("key should be removed")
+	}
+	if res := do("DELETE", "/groups/admin/keys", nil); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("purge: %v", res.Status)
+	}
+	if _, err := g.Peek("Tom"); err == nil {
+		t.Fatalf("group should be purged")
+	}
+	if res := do("PUT", "/groups/admin/snapshot", strings.NewReader(string(b))); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("restore: %v", res.Status)
+	}
+	if v, err := g.Peek("a/b"); err != nil || v.String() != "a/b!" {
+		t.Fatalf("snapshot should be restored, got %q %v", v.String(), err)
+	}
+
+	if res := do("POST", "/groups/admin/keys", nil); res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %v", res.Status)
+	}
+	if res := do("GET", "/groups/unknown", nil); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", res.Status)
+	}
+}
+
+func TestHandlerToken(t *testing.T) {
+	for _, token := range []string{"secret", ""} {
+		h := NewHandler(token)
+		for _, auth := range []string{"", "Bearer wrong", "Bearer "} {
+			req := httptest.NewRequest("GET", "/groups", nil)
+			if auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("token %q auth %q: expected 401, got %d", token, auth, w.Code)
+			}
+		}
+	}
+}
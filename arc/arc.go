@@ -0,0 +1,185 @@
+package arc
+
+import "container/list"
+
+// Cache arc (Adaptive Replacement Cache) 缓存淘汰策略。
+// 记录分布在 T1(只访问过一次) 和 T2(访问过多次) 两个链表中，
+// B1、B2 分别是从 T1、T2 淘汰出去的“幽灵”记录，只保存 key 和大小。
+// 幽灵命中时调整 T1 的目标大小 p，从而在“最近”与“频繁”之间自适应。
+// 与 lru.Cache 拥有相同的 Value 接口和内存预算，可以直接替换。
+type Cache struct {
+	// 允许使用的最大内存
+	maxBytes int64
+	// T1 的目标内存
+	p int64
+	// 四个链表，头部是最近访问的记录
+	t1, t2, b1, b2 *list.List
+	// 四个链表各自占用的内存
+	t1Bytes, t2Bytes, b1Bytes, b2Bytes int64
+	// 键是字符串，值是所在链表中节点型指针。
+	cache map[string]*list.Element
+	// 某条记录被移除时的回调函数，可以为 nil。
+	OnEvicted func(key string, value Value)
+}
+
+type entry struct {
+	key   string
+	value Value
+	// 记录占用的内存，幽灵记录保留淘汰前的大小
+	size int64
+	// 记录所在的链表
+	ll *list.List
+}
+
+// Value 接口，与 lru.Value 相同，用于返回值所占用的内存大小。
+type Value interface {
+	Len() int
+}
+
+// New 方便实例化 Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:  maxBytes,
+		t1:        list.New(),
+		t2:        list.New(),
+		b1:        list.New(),
+		b2:        list.New(),
+		cache:     make(map[string]*list.Element),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Len 返回缓存中实际存储的记录数，不包含幽灵记录
+func (c *Cache) Len() int {
+	return c.t1.Len() + c.t2.Len()
+}
+
+// Add 新增/修改
+func (c *Cache) Add(key string, value Value) {
+	size := int64(len(key)) + int64(value.Len())
+	inB2 := false
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		switch kv.ll {
+		case c.t1, c.t2:
+			// 已缓存，视为一次访问，移入 T2
+			c.unlink(ele)
+			kv.value, kv.size = value, size
+			c.push(c.t2, kv)
+		case c.b1:
+			// 幽灵命中 B1，说明 T1 太小，增大 p
+			c.p = min64(c.maxBytes, c.p+size*max64(1, c.b2Bytes/max64(1, c.b1Bytes)))
+			c.unlink(ele)
+			kv.value, kv.size = value, size
+			c.push(c.t2, kv)
+		case c.b2:
+			// 幽灵命中 B2，说明 T2 太小，减小 p
+			c.p = max64(0, c.p-size*max64(1, c.b1Bytes/max64(1, c.b2Bytes)))
+			c.unlink(ele)
+			kv.value, kv.size = value, size
+			c.push(c.t2, kv)
+			inB2 = true
+		}
+	} else {
+		c.push(c.t1, &entry{key: key, value: value, size: size})
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.t1Bytes+c.t2Bytes {
+		c.replace(inB2)
+	}
+	c.trimGhosts()
+}
+
+// Get 获取 value，命中的记录会移入 T2
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		if kv.ll != c.t1 && kv.ll != c.t2 {
+			return nil, false
+		}
+		c.unlink(ele)
+		c.push(c.t2, kv)
+		return kv.value, true
+	}
+	return
+}
+
+// RemoveOldest 按 arc 的替换规则淘汰一条记录。
+// 方法名与 lru.Cache 保持一致，方便替换。
+func (c *Cache) RemoveOldest() {
+	c.replace(false)
+	c.trimGhosts()
+}
+
+// replace 根据 p 决定从 T1 还是 T2 淘汰最久未访问的记录，并将其降级为幽灵记录
+func (c *Cache) replace(inB2 bool) {
+	var from, to *list.List
+	if c.t1.Len() > 0 && (c.t1Bytes > c.p || (inB2 && c.t1Bytes == c.p) || c.t2.Len() == 0) {
+		from, to = c.t1, c.b1
+	} else if c.t2.Len() > 0 {
+		from, to = c.t2, c.b2
+	} else {
+		return
+	}
+	ele := from.Back()
+	kv := ele.Value.(*entry)
+	c.unlink(ele)
+	value := kv.value
+	kv.value = nil
+	if c.maxBytes != 0 {
+		// 不限制内存时幽灵记录没有意义
+		c.push(to, kv)
+	}
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, value)
+	}
+}
+
+// trimGhosts 限制幽灵记录的规模：T1+B1 与全部链表分别不超过 maxBytes 与 2*maxBytes
+func (c *Cache) trimGhosts() {
+	for c.b1.Len() > 0 && c.t1Bytes+c.b1Bytes > c.maxBytes {
+		c.unlink(c.b1.Back())
+	}
+	for c.b2.Len() > 0 && c.t1Bytes+c.t2Bytes+c.b1Bytes+c.b2Bytes > 2*c.maxBytes {
+		c.unlink(c.b2.Back())
+	}
+}
+
+func (c *Cache) push(l *list.List, kv *entry) {
+	kv.ll = l
+	c.cache[kv.key] = l.PushFront(kv)
+	*c.bytesOf(l) += kv.size
+}
+
+func (c *Cache) unlink(ele *list.Element) {
+	kv := ele.Value.(*entry)
+	kv.ll.Remove(ele)
+	*c.bytesOf(kv.ll) -= kv.size
+	delete(c.cache, kv.key)
+}
+
+func (c *Cache) bytesOf(l *list.List) *int64 {
+	switch l {
+	case c.t1:
+		return &c.t1Bytes
+	case c.t2:
+		return &c.t2Bytes
+	case c.b1:
+		return &c.b1Bytes
+	default:
+		return &c.b2Bytes
+	}
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
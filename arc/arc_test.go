@@ -0,0 +1,62 @@
+package arc
+
+import (
+	"reflect"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestGet(t *testing.T) {
+	arc := New(int64(0), nil)
+	arc.Add("key1", String("1234"))
+	if v, ok := arc.Get("key1"); !ok || string(v.(String)) != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	if _, ok := arc.Get("key2"); ok {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+func TestFrequentSurvivesScan(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	arc := New(int64(12), callback)
+	arc.Add("k1", String("v1"))
+	arc.Get("k1")
+	arc.Add("k2", String("v2"))
+	arc.Add("k3", String("v3"))
+	arc.Add("k4", String("v4"))
+
+	expect := []string{"k2"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s but got %s", expect, keys)
+	}
+	if _, ok := arc.Get("k1"); !ok || arc.Len() != 3 {
+		t.Fatalf("frequent key k1 should survive the scan")
+	}
+}
+
+func TestGhostHit(t *testing.T) {
+	arc := New(int64(8), nil)
+	arc.Add("k1", String("v1"))
+	arc.Add("k2", String("v2"))
+	arc.Get("k2")
+	arc.Add("k3", String("v3"))
+	if _, ok := arc.Get("k1"); ok {
+		t.Fatalf("k1 should be evicted")
+	}
+	arc.Add("k1", String("v1"))
+	if arc.p == 0 {
+		t.Fatalf("ghost hit in B1 should grow p")
+	}
+	if ele, ok := arc.cache["k1"]; !ok || ele.Value.(*entry).ll != arc.t2 {
+		t.Fatalf("ghost hit should insert k1 into T2")
+	}
+}
@@ -0,0 +1,108 @@
+package go_cache
+
+import (
+	"errors"
+	"go-cache/lru"
+	"sync"
+	"time"
+)
+
+// Backoff 加载失败后缓存错误的时间：第 n 次连续失败后，在 Initial * Multiplier^(n-1)
+// 的时间内（最长为 Max）直接返回上一次的错误
+type Backoff struct {
+	// 第一次失败后缓存错误的时间，默认 100ms
+	Initial time.Duration
+	// 缓存错误的最长时间，默认 30s
+	Max time.Duration
+	// 每次连续失败后时间增长的倍数，默认 2
+	Multiplier float64
+}
+
+// window 返回第 failures 次连续失败后缓存错误的时间
+func (b Backoff) window(failures int) time.Duration {
+	d := float64(b.Initial)
+	for i := 1; i < failures && d < float64(b.Max); i++ {
+		d *= b.Multiplier
+	}
+	if d > float64(b.Max) {
+		return b.Max
+	}
+	return time.Duration(d)
+}
+
+// WithErrorBackoff 加载失败后按 b 缓存错误，期间同一个 key 的 Get 直接返回该错误而不访问数据源，
+// 避免故障的数据源被每个请求反复访问；加载成功、Set 或 Remove 之后恢复正常。
+// ErrNotFound、ErrLoadThrottled 以及调用方的 ctx 结束导致的错误不会被缓存，被抑制的加载计入 Stats().SuppressedLoads。
+func WithErrorBackoff(b Backoff) GroupOption {
+	if b.Initial <= 0 {
+		b.Initial = 100 * time.Millisecond
+	}
+	if b.Max <= 0 {
+		b.Max = 30 * time.Second
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 2
+	}
+	return func(g *Group) {
+		g.errCache = &errorCache{
+			backoff: b,
+			lru:     lru.NewTyped[string, loadFailure](0, nil, nil, lru.WithMaxEntries(errorCacheEntries)),
+		}
+	}
+}
+
+// errorCache 最多记录的失败 key 数量
+const errorCacheEntries = 10000
+
+// loadFailure 一个 key 连续加载失败的记录
+type loadFailure struct {
+	err      error
+	failures int
+	until    time.Time
+}
+
+// errorCache 记录加载失败的 key
+type errorCache struct {
+	backoff Backoff
+	mu      sync.Mutex
+	lru     *lru.TypedCache[string, loadFailure]
+}
+
+// get 返回 key 在退避期间内缓存的错误
+func (c *errorCache) get(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, ok := c.lru.Peek(key)
+	if !ok || time.Now().After(f.until) {
+		return nil
+	}
+	return f.err
+}
+
+// fail 记录一次加载失败
+func (c *errorCache) fail(key string, err error) {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrLoadThrottled) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, _ := c.lru.Peek(key)
+	f.failures++
+	f.err = err
+	f.until = time.Now().Add(c.backoff.window(f.failures))
+	c.lru.Add(key, f)
+}
+
+// reset 清除 key 的失败记录
+func (c *errorCache) reset(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Remove(key)
+}
+
+// clear 清除所有失败记录
+func (c *errorCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Clear()
+}
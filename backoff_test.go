@@ -0,0 +1,55 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoffWindow(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	for failures, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := b.window(failures); got != want {
+			t.Fatalf("window(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
+
+func TestErrorBackoff(t *testing.T) {
+	var loads int32
+	var healthy int32
+	unavailable := errors.New("database unavailable")
+	gee := NewGroup("error-backoff", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			if atomic.LoadInt32(&healthy) == 0 {
+				return nil, unavailable
+			}
+			return []byte(key), nil
+		}), WithErrorBackoff(Backoff{Initial: 20 * time.Millisecond}))
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := gee.Get(ctx, "Tom"); !errors.Is(err, unavailable) {
+			t.Fatalf("expected cached error, got %v", err)
+		}
+	}
+	if loads != 1 || gee.Stats().SuppressedLoads != 4 {
+		t.Fatalf("loads during backoff should be suppressed, got %d loads %+v", loads, gee.Stats())
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	gee.Get(ctx, "Tom")
+	time.Sleep(25 * time.Millisecond)
+	if _, err := gee.Get(ctx, "Tom"); err == nil || loads != 2 {
+		t.Fatalf("second failure should double the window, got %d loads", loads)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	gee.Remove(ctx, "Tom")
+	if v, err := gee.Get(ctx, "Tom"); err != nil || v.String() != "Tom" {
+		t.Fatalf("Remove should clear the cached error, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitState 节点熔断器的状态
+type CircuitState int
+
+const (
+	// CircuitClosed 正常请求节点
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 节点的错误率过高，暂时不再请求，它负责的 key 改为请求其他副本或者从本地加载
+	CircuitOpen
+	// CircuitHalfOpen 断开一段时间之后放行一个探测请求，成功时关闭，失败时重新断开
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig 节点熔断器的配置，零值字段使用默认值
+type CircuitBreakerConfig struct {
+	// 统计错误率的时间窗口，默认 10s
+	Window time.Duration
+	// 窗口内的请求数达到 MinRequests 之后才会断开，默认 20
+	MinRequests int
+	// 窗口内的错误率达到 ErrorRate 时断开，默认 0.5
+	ErrorRate float64
+	// 断开之后经过 OpenDuration 放行一个探测请求，默认 5s
+	OpenDuration time.Duration
+	// 节点的熔断器状态变化时调用，不能阻塞
+	OnStateChange func(peer PeerGetter, state CircuitState)
+}
+
+// WithPeerCircuitBreaker 为每个节点维护一个熔断器：节点的错误率过高时暂时不再请求它，
+// 它负责的 key 改为请求其他副本或者从本地加载，避免一个反复故障的节点使每次 Get 都要等到失败。
+// 节点按 PeerGetter 区分，因此 PeerGetter 需要能够作为 map 的 key（例如指针）。
+func WithPeerCircuitBreaker(cfg CircuitBreakerConfig) GroupOption {
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 20
+	}
+	if cfg.ErrorRate <= 0 {
+		cfg.ErrorRate = 0.5
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = 5 * time.Second
+	}
+	return func(g *Group) {
+		g.breakers = &breakers{cfg: cfg, m: make(map[PeerGetter]*breaker)}
+	}
+}
+
+// errCircuitOpen 节点的熔断器处于断开状态
+var errCircuitOpen = errors.New("go-cache: peer circuit breaker is open")
+
+// breakers 每个节点的熔断器
+type breakers struct {
+	cfg CircuitBreakerConfig
+
+	mu sync.Mutex
+	m  map[PeerGetter]*breaker
+}
+
+// breaker 单个节点的熔断器状态，由 breakers.mu 保护
+type breaker struct {
+	state CircuitState
+	// 当前窗口的开始时间、请求数和失败数
+	windowStart        time.Time
+	requests, failures int
+	openedAt           time.Time
+	// 半开状态下是否已经放行了探测请求
+	probing bool
+}
+
+// allow 判断是否可以请求 peer，半开状态下只放行一个探测请求，放行之后需要调用 record
+func (b *breakers) allow(peer PeerGetter) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br := b.get(peer)
+	switch br.state {
+	case CircuitOpen:
+		if time.Since(br.openedAt) < b.cfg.OpenDuration {
+			return false
+		}
+		b.setState(peer, br, CircuitHalfOpen)
+		br.probing = true
+		return true
+	case CircuitHalfOpen:
+		if br.probing {
+			return false
+		}
+		br.probing = true
+	}
+	return true
+}
+
+// record 记录一次请求 peer 的结果。ErrNotFound 说明节点正常；
+// 请求被取消（例如 hedged 请求中较慢的一个）不计入统计
+func (b *breakers) record(peer PeerGetter, err error) {
+	if b == nil {
+		return
+	}
+	failed := err != nil && !errors.Is(err, ErrNotFound)
+	canceled := errors.Is(err, context.Canceled)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br := b.get(peer)
+	now := time.Now()
+	switch br.state {
+	case CircuitHalfOpen:
+		br.probing = false
+		switch {
+		case canceled:
+		case failed:
+			br.openedAt = now
+			b.setState(peer, br, CircuitOpen)
+		default:
+			br.windowStart, br.requests, br.failures = now, 0, 0
+			b.setState(peer, br, CircuitClosed)
+		}
+	case CircuitClosed:
+		if canceled {
+			return
+		}
+		if now.Sub(br.windowStart) >= b.cfg.Window {
+			br.windowStart, br.requests, br.failures = now, 0, 0
+		}
+		br.requests++
+		if failed {
+			br.failures++
+		}
+		if br.requests >= b.cfg.MinRequests && float64(br.failures) >= b.cfg.ErrorRate*float64(br.requests) {
+			br.openedAt = now
+			b.setState(peer, br, CircuitOpen)
+		}
+	}
+}
+
+func (b *breakers) get(peer PeerGetter) *breaker {
+	br, ok := b.m[peer]
+	if !ok {
+		br = &breaker{windowStart: time.Now()}
+		b.m[peer] = br
+	}
+	return br
+}
+
+func (b *breakers) setState(peer PeerGetter, br *breaker, state CircuitState) {
+	br.state = state
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(peer, state)
+	}
+}
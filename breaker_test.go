@@ -0,0 +1,82 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-cache/gocachepb"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var states []CircuitState
+	peer := &fakePeer{}
+	var g Group
+	WithPeerCircuitBreaker(CircuitBreakerConfig{
+		MinRequests:  4,
+		OpenDuration: 20 * time.Millisecond,
+		OnStateChange: func(p PeerGetter, state CircuitState) {
+			states = append(states, state)
+		},
+	})(&g)
+	b := g.breakers
+	boom := errors.New("boom")
+
+	for _, err := range []error{nil, ErrNotFound, boom, boom} {
+		if !b.allow(peer) {
+			t.Fatalf("closed circuit should allow requests")
+		}
+		b.record(peer, err)
+	}
+	if b.allow(peer) {
+		t.Fatalf("circuit should be open after 2 failures out of 4")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.allow(peer) || b.allow(peer) {
+		t.Fatalf("half-open circuit should allow exactly one probe")
+	}
+	b.record(peer, context.Canceled)
+	if !b.allow(peer) {
+		t.Fatalf("canceled probe should let another probe through")
+	}
+	b.record(peer, boom)
+	if b.allow(peer) {
+		t.Fatalf("failed probe should open the circuit again")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	b.allow(peer)
+	b.record(peer, nil)
+	if !b.allow(peer) {
+		t.Fatalf("successful probe should close the circuit")
+	}
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if !reflect.DeepEqual(states, want) {
+		t.Fatalf("expected state changes %v but got %v", want, states)
+	}
+}
+
+func TestPeerCircuitBreaker(t *testing.T) {
+	var calls int32
+	down := peerFunc(func(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("connection refused")
+	})
+	gee := NewGroup("peer-breaker", 2<<10, localGetter, WithPeers(replicaList{&down}),
+		WithPeerCircuitBreaker(CircuitBreakerConfig{MinRequests: 2, OpenDuration: time.Minute}))
+	for i := 0; i < 10; i++ {
+		if view, _ := gee.Get(context.Background(), fmt.Sprint("key", i)); view.String() != "local" {
+			t.Fatalf("failing peer should fall back to local load, got %s", view)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("peer should not be requested after the circuit opens, got %d calls", calls)
+	}
+	if n := gee.Stats().PeerErrors; n != 2 {
+		t.Fatalf("requests skipped by the breaker should not count as peer errors, got %d", n)
+	}
+}
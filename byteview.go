@@ -1,10 +1,43 @@
 package go_cache
 
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"unsafe"
+
+	"github.com/cespare/xxhash/v2"
+)
+
 // ByteView 缓存值的抽象与封装
 type ByteView struct {
 	b []byte
 }
 
+// NewByteViewFromString 直接引用字符串的数据创建视图，不会复制。
+// 字符串和 ByteView 都是不可变的，所以共享是安全的。
+func NewByteViewFromString(s string) ByteView {
+	if s == "" {
+		return ByteView{}
+	}
+	return ByteView{b: unsafe.Slice(unsafe.StringData(s), len(s))}
+}
+
+// NewByteViewFromReader 从 r 中读取全部数据创建视图，数据超过 limit 字节时返回错误
+func NewByteViewFromReader(r io.Reader, limit int64) (ByteView, error) {
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return ByteView{}, err
+	}
+	if int64(len(b)) > limit {
+		return ByteView{}, fmt.Errorf("go-cache: value exceeds limit of %d bytes", limit)
+	}
+	return ByteView{b: b}, nil
+}
+
 // Len 返回数据视图的长度
 func (v ByteView) Len() int {
 	return len(v.b)
@@ -15,13 +48,95 @@ func (v ByteView) ByteSlice() []byte {
 	return cloneBytes(v.b)
 }
 
+// CopyTo 把数据复制到 dst 中，返回复制的字节数，调用方可以复用 dst 避免分配内存
+func (v ByteView) CopyTo(dst []byte) int {
+	return copy(dst, v.b)
+}
+
+// bufPool 复用 ReleasableByteSlice 分配的切片
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		return new([]byte)
+	},
+}
+
+// ReleasableByteSlice 从缓冲池中取出切片并复制数据，用完后调用 release 归还。
+// release 之后不能再使用 b，适合频繁读取的热点路径减少 GC 压力。
+func (v ByteView) ReleasableByteSlice() (b []byte, release func()) {
+	p := bufPool.Get().(*[]byte)
+	if cap(*p) < len(v.b) {
+		*p = make([]byte, len(v.b))
+	}
+	b = (*p)[:len(v.b)]
+	copy(b, v.b)
+	return b, func() {
+		*p = b[:0]
+		bufPool.Put(p)
+	}
+}
+
 // String 以字符串形式返回数据，必要时进行复制
 func (v ByteView) String() string {
 	return string(v.b)
 }
 
+// Slice 返回 [from, to) 范围内的数据视图，与原视图共享底层数据，不会复制。
+// ByteView 是不可变的，所以共享是安全的；越界时与切片一样会 panic。
+func (v ByteView) Slice(from, to int) ByteView {
+	return ByteView{b: v.b[from:to:to]}
+}
+
+// Equal 判断两个视图的数据是否相同
+func (v ByteView) Equal(other ByteView) bool {
+	return bytes.Equal(v.b, other.b)
+}
+
+// Hash64 返回数据的 xxhash64 值，可用于去重
+func (v ByteView) Hash64() uint64 {
+	return xxhash.Sum64(v.b)
+}
+
+// SHA256 返回数据的 SHA-256 摘要，可用于完整性校验
+func (v ByteView) SHA256() [32]byte {
+	return sha256.Sum256(v.b)
+}
+
+// Reader 返回读取数据的 io.Reader，不会复制数据
+func (v ByteView) Reader() io.Reader {
+	return bytes.NewReader(v.b)
+}
+
+// WriteTo 实现 io.WriterTo，把数据直接写入 w，不会复制数据
+func (v ByteView) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(v.b)
+	if err == nil && n != len(v.b) {
+		err = io.ErrShortWrite
+	}
+	return int64(n), err
+}
+
+// ReadAt 实现 io.ReaderAt，从 off 处开始把数据复制到 p 中
+func (v ByteView) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("go-cache: ByteView.ReadAt: negative offset")
+	}
+	if off >= int64(len(v.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, v.b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
 func cloneBytes(b []byte) []byte {
 	c := make([]byte, len(b))
 	copy(c, b)
 	return c
 }
+
+// hashKey 返回 key 的 xxhash64 值，Group 内部按 key 分段（keyLock、write-behind 协程、热点副本等）时统一使用
+func hashKey(key string) uint64 {
+	return xxhash.Sum64String(key)
+}
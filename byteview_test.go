@@ -0,0 +1,108 @@
+package go_cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestByteViewReader(t *testing.T) {
+	v := ByteView{b: []byte("hello world")}
+	b, err := io.ReadAll(v.Reader())
+	if err != nil || string(b) != "hello world" {
+		t.Fatalf("Reader failed")
+	}
+
+	var buf bytes.Buffer
+	if n, err := v.WriteTo(&buf); err != nil || n != 11 || buf.String() != "hello world" {
+		t.Fatalf("WriteTo failed")
+	}
+
+	p := make([]byte, 5)
+	if n, err := v.ReadAt(p, 6); err != nil || n != 5 || string(p) != "world" {
+		t.Fatalf("ReadAt failed")
+	}
+	if n, err := v.ReadAt(p, 8); err != io.EOF || n != 3 {
+		t.Fatalf("ReadAt at the end should return io.EOF")
+	}
+}
+
+func TestByteViewSlice(t *testing.T) {
+	v := ByteView{b: []byte("hello world")}
+	s := v.Slice(6, 11)
+	if s.String() != "world" || s.Len() != 5 {
+		t.Fatalf("Slice failed")
+	}
+	if &s.b[0] != &v.b[6] {
+		t.Fatalf("Slice should not copy the data")
+	}
+	if cap(s.b) != 5 {
+		t.Fatalf("Slice should not expose data beyond the range")
+	}
+}
+
+func TestByteViewEqual(t *testing.T) {
+	v1 := ByteView{b: []byte("hello")}
+	v2 := ByteView{b: []byte("hello")}
+	v3 := ByteView{b: []byte("world")}
+	if !v1.Equal(v2) || v1.Equal(v3) {
+		t.Fatalf("Equal failed")
+	}
+	if v1.Hash64() != v2.Hash64() || v1.Hash64() == v3.Hash64() {
+		t.Fatalf("Hash64 failed")
+	}
+	if v1.SHA256() != sha256.Sum256([]byte("hello")) {
+		t.Fatalf("SHA256 failed")
+	}
+}
+
+func TestNewByteView(t *testing.T) {
+	if v := NewByteViewFromString("hello"); v.String() != "hello" || v.Len() != 5 {
+		t.Fatalf("NewByteViewFromString failed")
+	}
+	if v := NewByteViewFromString(""); v.Len() != 0 {
+		t.Fatalf("NewByteViewFromString of empty string failed")
+	}
+	if v, err := NewByteViewFromReader(strings.NewReader("hello"), 5); err != nil || v.String() != "hello" {
+		t.Fatalf("NewByteViewFromReader failed")
+	}
+	if _, err := NewByteViewFromReader(strings.NewReader("hello"), 4); err == nil {
+		t.Fatalf("NewByteViewFromReader should fail when exceeding the limit")
+	}
+}
+
+func TestByteViewCopy(t *testing.T) {
+	v := ByteView{b: []byte("hello")}
+	dst := make([]byte, 3)
+	if n := v.CopyTo(dst); n != 3 || string(dst) != "hel" {
+		t.Fatalf("CopyTo failed")
+	}
+	b, release := v.ReleasableByteSlice()
+	if string(b) != "hello" {
+		t.Fatalf("ReleasableByteSlice failed")
+	}
+	b[0] = 'j'
+	if v.String() != "hello" {
+		t.Fatalf("ReleasableByteSlice should return a copy")
+	}
+	release()
+}
+
+func BenchmarkByteSlice(b *testing.B) {
+	v := ByteView{b: bytes.Repeat([]byte("x"), 4096)}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = v.ByteSlice()
+	}
+}
+
+func BenchmarkReleasableByteSlice(b *testing.B) {
+	v := ByteView{b: bytes.Repeat([]byte("x"), 4096)}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, release := v.ReleasableByteSlice()
+		release()
+	}
+}
@@ -1,8 +1,12 @@
 package go_cache
 
 import (
+	"fmt"
 	"go-cache/lru"
+	"io"
+	"log"
 	"sync"
+	"time"
 )
 
 // 并发控制
@@ -10,25 +14,244 @@ type cache struct {
 	mu         sync.Mutex
 	lru        *lru.Cache
 	cacheBytes int64
+	// 超过该大小的值压缩后再存储，0 表示不压缩
+	compressThreshold int
+	// 记录被淘汰、过期或删除时调用，调用时持有 mu
+	onEvicted func(key string)
+	// 每条记录的版本，记录被删除时随之删除
+	versions map[string]int64
+}
+
+// entryInfo 记录的写入时间、过期时间等信息以及版本
+type entryInfo struct {
+	lru.EntryInfo
+	version int64
 }
 
 func (c *cache) add(key string, value ByteView) {
+	c.addWithTTL(key, value, 0)
+}
+
+// addWithTTL 写入在 ttl 之后过期的值，ttl <= 0 表示永不过期
+func (c *cache) addWithTTL(key string, value ByteView, ttl time.Duration) {
+	c.addWithVersion(key, value, ttl, 0)
+}
+
+// addWithVersion 写入版本为 version 的值，version 为 0 表示没有版本
+func (c *cache) addWithVersion(key string, value ByteView, ttl time.Duration, version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	c.lru.AddWithTTL(key, c.stored(value), ttl)
+	// 准入策略可能拒绝写入
+	if version == 0 || !c.lru.Contains(key) {
+		delete(c.versions, key)
+		return
+	}
+	if c.versions == nil {
+		c.versions = make(map[string]int64)
+	}
+	c.versions[key] = version
+}
+
+// version 返回 key 的版本，不存在或者没有版本时返回 0
+func (c *cache) version(key string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil || !c.lru.Contains(key) {
+		return 0
+	}
+	return c.versions[key]
+}
+
+// newerThan 判断 key 已有的值版本是否不低于 version，version 为 0 时总是返回 false
+func (c *cache) newerThan(key string, version int64) bool {
+	return version != 0 && c.version(key) >= version
+}
+
+// lazyInit 第一次写入时创建 lru，调用时需要持有 mu
+func (c *cache) lazyInit() {
+	if c.lru != nil {
+		return
+	}
+	c.lru = lru.New(c.cacheBytes, func(key string, _ lru.Value) {
+		delete(c.versions, key)
+		if c.onEvicted != nil {
+			c.onEvicted(key)
+		}
+	})
+}
+
+// stored 返回存入 lru 的值，超过 compressThreshold 并且压缩后变小时返回 compressedValue
+func (c *cache) stored(value ByteView) lru.Value {
+	if c.compressThreshold > 0 && value.Len() > c.compressThreshold {
+		if cv, ok := compress(value.b); ok {
+			return cv
+		}
+	}
+	return value
+}
+
+// clear 删除所有值
+func (c *cache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru != nil {
+		c.lru.Clear()
+	}
+}
+
+// snapshot 把所有未过期的值写入 w，压缩的值解压后写入
+func (c *cache) snapshot(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	return c.lru.Snapshot(w, func(v lru.Value) ([]byte, error) {
+		switch v := v.(type) {
+		case ByteView:
+			return v.b, nil
+		case compressedValue:
+			bv, err := v.decompress()
+			return bv.b, err
+		}
+		return nil, fmt.Errorf("unexpected value type %T", v)
+	})
+}
+
+// restore 读取 snapshot 写入的值并添加到缓存中，快照中不保存版本，恢复的值使用 newVersion 生成的新版本
+func (c *cache) restore(r io.Reader, newVersion func() int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	err := c.lru.Restore(r, func(b []byte) (lru.Value, error) {
+		return c.stored(ByteView{b: b}), nil
+	})
+	if err != nil {
+		return err
+	}
+	if c.versions == nil {
+		c.versions = make(map[string]int64)
+	}
+	for _, key := range c.lru.Keys() {
+		if _, ok := c.versions[key]; !ok {
+			c.versions[key] = newVersion()
+		}
+	}
+	return nil
+}
+
+// remove 删除 key 对应的值
+func (c *cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru != nil {
+		c.lru.Remove(key)
+	}
+}
+
+// close 停止底层 lru 缓存的后台协程，之后不能再写入
+func (c *cache) close() {
+	c.mu.Lock()
+	l := c.lru
+	c.mu.Unlock()
+	if l != nil {
+		l.Close()
+	}
+}
+
+// removeUnlessNewer 删除 key，key 在缓存中的版本高于 version 时保留
+func (c *cache) removeUnlessNewer(key string, version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru != nil && c.versions[key] <= version {
+		c.lru.Remove(key)
+	}
+}
+
+// removeOldest 淘汰最久未访问的值，缓存为空时返回 false
+func (c *cache) removeOldest() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil || c.lru.Len() == 0 {
+		return false
+	}
+	c.lru.RemoveOldest()
+	return true
+}
+
+// stats 返回缓存的统计信息
+func (c *cache) stats() CacheStats {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.lru == nil {
-		c.lru = lru.New(c.cacheBytes, nil)
+		return CacheStats{}
+	}
+	s := c.lru.Stats()
+	return CacheStats{
+		Bytes:     s.Bytes,
+		Items:     int64(c.lru.Len()),
+		Gets:      s.Hits + s.Misses,
+		Hits:      s.Hits,
+		Evictions: s.Evictions,
 	}
-	c.lru.Add(key, value)
+}
+
+// bytes 返回缓存占用的内存
+func (c *cache) bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		return 0
+	}
+	return c.lru.Stats().Bytes
 }
 
 func (c *cache) get(key string) (value ByteView, ok bool) {
+	value, _, ok = c.getEntry(key)
+	return
+}
+
+// getWithExpire 获取值以及它的过期时间，过期时间为零值表示永不过期
+func (c *cache) getWithExpire(key string) (value ByteView, expire time.Time, ok bool) {
+	value, info, ok := c.getEntry(key)
+	return value, info.Expire, ok
+}
+
+// getEntry 获取值以及它的写入时间、过期时间、版本等信息
+func (c *cache) getEntry(key string) (value ByteView, info entryInfo, ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.lru == nil {
 		return
 	}
-	if v, ok := c.lru.Get(key); ok {
-		return v.(ByteView), ok
+	v, ok := c.lru.Get(key)
+	if !ok {
+		return
 	}
-	return
+	info.EntryInfo, _ = c.lru.GetEntryInfo(key)
+	info.version = c.versions[key]
+	switch v := v.(type) {
+	case ByteView:
+		return v, info, true
+	case compressedValue:
+		bv, err := v.decompress()
+		if err != nil {
+			log.Println("[GeeCache] failed to decompress value of", key, err)
+			c.lru.Remove(key)
+			return ByteView{}, entryInfo{}, false
+		}
+		return bv, info, true
+	}
+	return ByteView{}, entryInfo{}, false
+}
+
+// entryInfo 返回记录的信息，不影响访问顺序和统计信息
+func (c *cache) entryInfo(key string) (entryInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru == nil {
+		return entryInfo{}, false
+	}
+	info, ok := c.lru.GetEntryInfo(key)
+	return entryInfo{EntryInfo: info, version: c.versions[key]}, ok
 }
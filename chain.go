@@ -0,0 +1,37 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+)
+
+// Level Chain 中的一层数据源
+type Level struct {
+	Getter Getter
+	// Fallthrough 判断该层返回的错误是否应该继续尝试下一层，返回 false 时直接返回该错误。
+	// nil 表示任何错误都继续尝试下一层
+	Fallthrough func(err error) bool
+}
+
+// Chain 把多层数据源组合为一个 Getter，缓存未命中时按顺序尝试，返回第一个成功的结果，
+// 例如 Redis -> 数据库 -> 默认值。所有层都失败时返回最后一层的错误，ctx 结束时不再尝试后面的层。
+func Chain(levels ...Level) Getter {
+	return GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		var err error
+		for _, l := range levels {
+			var b []byte
+			if b, err = l.Getter.Get(ctx, key); err == nil {
+				return b, nil
+			}
+			if ctx.Err() != nil || (l.Fallthrough != nil && !l.Fallthrough(err)) {
+				return nil, err
+			}
+		}
+		return nil, err
+	})
+}
+
+// FallthroughOnNotFound 只有数据源中不存在 key（ErrNotFound）时才尝试下一层，可以用作 Level.Fallthrough
+func FallthroughOnNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,38 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	level := func(name string, values map[string]string, err error) Getter {
+		return GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+			calls = append(calls, name)
+			if v, ok := values[key]; ok {
+				return []byte(v), nil
+			}
+			return nil, err
+		})
+	}
+	unavailable := errors.New("database unavailable")
+	getter := Chain(
+		Level{Getter: level("redis", map[string]string{"Tom": "630"}, ErrNotFound)},
+		Level{Getter: level("db", map[string]string{"Jack": "589"}, unavailable), Fallthrough: FallthroughOnNotFound},
+		Level{Getter: level("default", map[string]string{"Tom": "0", "Jack": "0", "Sam": "0"}, ErrNotFound)},
+	)
+
+	if v, _ := getter.Get(context.Background(), "Tom"); string(v) != "630" || len(calls) != 1 {
+		t.Fatalf("first level should serve Tom, got %q after %v", v, calls)
+	}
+	calls = nil
+	if v, _ := getter.Get(context.Background(), "Jack"); string(v) != "589" || len(calls) != 2 {
+		t.Fatalf("second level should serve Jack, got %q after %v", v, calls)
+	}
+	calls = nil
+	if _, err := getter.Get(context.Background(), "Sam"); err != unavailable || len(calls) != 2 {
+		t.Fatalf("error not classified as fall through should be returned, got %v after %v", err, calls)
+	}
+}
@@ -0,0 +1,12 @@
+package go_cache
+
+// WithClientOnly 本节点只作为集群的客户端：Get 通过 WithPeers 的 PeerPicker 从负责 key 的节点获取，
+// 结果只放入 hotCache（可以使用全部的 cacheBytes），不会写入 mainCache，也就不负责任何 key，
+// 适合无状态的前端服务读取缓存集群。PeerPicker 也应当把本节点排除在外（例如 http.WithClientOnly），
+// 其他节点不应当把本节点加入它们的节点列表。
+// 没有可用的节点时从 Getter 加载但不缓存，此时 NewGroup 的 getter 可以为 nil，没有 Getter 时返回 ErrNoPeer。
+func WithClientOnly() GroupOption {
+	return func(g *Group) {
+		g.clientOnly = true
+	}
+}
@@ -0,0 +1,58 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"testing"
+)
+
+func TestClientOnly(t *testing.T) {
+	peer := &fakePeer{}
+	gee := NewGroup("client-only", 2<<10, nil, WithClientOnly(), WithPeers(peer))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		v, info, err := gee.GetWithInfo(ctx, "remote-Tom")
+		if err != nil || v.String() != "peer:remote-Tom" {
+			t.Fatalf("should load from the peer, got %q, %v", v, err)
+		}
+		if i == 0 && info.Source != SourcePeer {
+			t.Fatalf("expected SourcePeer, got %v", info.Source)
+		}
+	}
+	if _, ok := gee.hotCache.get("remote-Tom"); !ok {
+		t.Fatalf("frequently read keys should still go to hotCache")
+	}
+
+	// 本来由本节点负责的 key 没有 Getter 时返回 ErrNoPeer
+	if _, err := gee.Get(ctx, "Tom"); !errors.Is(err, ErrNoPeer) {
+		t.Fatalf("expected ErrNoPeer, got %v", err)
+	}
+	if err := gee.ServePeerSet(ctx, &gocachepb.SetRequest{Key: "Jack", Value: []byte("589")}); err != nil {
+		t.Fatal(err)
+	}
+	if gee.mainCache.lru != nil {
+		t.Fatalf("client-only group should not allocate mainCache")
+	}
+	if !gee.Info().ClientOnly {
+		t.Fatalf("Info should report client-only mode")
+	}
+}
+
+func TestClientOnlyGetter(t *testing.T) {
+	loads := 0
+	gee := NewGroup("client-only-getter", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			loads++
+			return []byte(key), nil
+		}), WithClientOnly())
+	for i := 0; i < 2; i++ {
+		if v, err := gee.Get(context.Background(), "Tom"); err != nil || v.String() != "Tom" {
+			t.Fatalf("should fall back to the getter, got %q, %v", v, err)
+		}
+	}
+	if loads != 2 {
+		t.Fatalf("values loaded locally should not be cached, got %d loads", loads)
+	}
+}
@@ -0,0 +1,118 @@
+package clock
+
+// Cache clock (第二次机会) 缓存淘汰策略。
+// 记录保存在一个环形数组中，每条记录有一个引用位，Get 命中时只需要设置引用位，
+// 不需要移动任何节点，适合读多写少、对 Get 延迟敏感的场景。
+// 淘汰时指针沿环扫描，引用位为 true 的记录清除引用位后跳过，遇到引用位为 false 的记录将其淘汰。
+// 与 lru.Cache 拥有相同的 Value 接口和内存预算，可以直接替换。
+type Cache struct {
+	// 允许使用的最大内存
+	maxBytes int64
+	// 当前已使用的内存
+	nbytes int64
+	// 环形数组，被删除的位置为 nil，记录在 free 中等待复用
+	slots []*entry
+	free  []int
+	// 指针，指向下一个待检查的位置
+	hand int
+	// 键是字符串，值是记录在 slots 中的下标
+	cache map[string]int
+	// 某条记录被移除时的回调函数，可以为 nil。
+	OnEvicted func(key string, value Value)
+}
+
+type entry struct {
+	key   string
+	value Value
+	// 引用位
+	ref bool
+}
+
+// Value 接口，与 lru.Value 相同，用于返回值所占用的内存大小。
+type Value interface {
+	Len() int
+}
+
+// New 方便实例化 Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:  maxBytes,
+		cache:     make(map[string]int),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Len 返回缓存的记录数
+func (c *Cache) Len() int {
+	return len(c.cache)
+}
+
+// Add 新增/修改
+func (c *Cache) Add(key string, value Value) {
+	if i, ok := c.cache[key]; ok {
+		kv := c.slots[i]
+		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		kv.value = value
+		kv.ref = true
+	} else {
+		kv := &entry{key: key, value: value}
+		if n := len(c.free); n > 0 {
+			i := c.free[n-1]
+			c.free = c.free[:n-1]
+			c.slots[i] = kv
+			c.cache[key] = i
+		} else {
+			c.cache[key] = len(c.slots)
+			c.slots = append(c.slots, kv)
+		}
+		c.nbytes += int64(len(key)) + int64(value.Len())
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Get 获取 value，命中时只设置引用位
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if i, ok := c.cache[key]; ok {
+		kv := c.slots[i]
+		kv.ref = true
+		return kv.value, true
+	}
+	return
+}
+
+// RemoveOldest 按 clock 算法淘汰一条记录。
+// 方法名与 lru.Cache 保持一致，方便替换。
+func (c *Cache) RemoveOldest() {
+	if len(c.cache) == 0 {
+		return
+	}
+	for {
+		if c.hand >= len(c.slots) {
+			c.hand = 0
+		}
+		kv := c.slots[c.hand]
+		switch {
+		case kv == nil:
+		case kv.ref:
+			kv.ref = false
+		default:
+			c.remove(c.hand)
+			c.hand++
+			return
+		}
+		c.hand++
+	}
+}
+
+func (c *Cache) remove(i int) {
+	kv := c.slots[i]
+	c.slots[i] = nil
+	c.free = append(c.free, i)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
@@ -0,0 +1,48 @@
+package clock
+
+import (
+	"reflect"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestGet(t *testing.T) {
+	clock := New(int64(0), nil)
+	clock.Add("key1", String("1234"))
+	if v, ok := clock.Get("key1"); !ok || string(v.(String)) != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	if _, ok := clock.Get("key2"); ok {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+func TestSecondChance(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	clock := New(int64(12), callback)
+	clock.Add("k1", String("v1"))
+	clock.Add("k2", String("v2"))
+	clock.Add("k3", String("v3"))
+	clock.Get("k1")
+	clock.Add("k4", String("v4"))
+	clock.Add("k5", String("v5"))
+
+	expect := []string{"k2", "k3"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s but got %s", expect, keys)
+	}
+	if clock.Len() != 3 || clock.nbytes != 12 {
+		t.Fatalf("expected 3 entries and 12 bytes but got %d and %d", clock.Len(), clock.nbytes)
+	}
+	if _, ok := clock.Get("k1"); !ok {
+		t.Fatalf("referenced key k1 should get a second chance")
+	}
+}
@@ -0,0 +1,60 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec 负责缓存值与 Go 类型之间的转换，msgpack、protobuf 等格式实现该接口即可使用
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec 使用 encoding/json 编解码，是默认的 Codec
+var JSONCodec Codec = jsonCodec{}
+
+// GobCodec 使用 encoding/gob 编解码
+var GobCodec Codec = gobCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// WithCodec 设置 GetInto 使用的 Codec，默认为 JSONCodec
+func WithCodec(c Codec) GroupOption {
+	return func(g *Group) {
+		g.codec = c
+	}
+}
+
+// GetInto 获取 key 对应的值，并使用 Group 的 Codec 解码到 v 中，v 必须是指针
+func (g *Group) GetInto(ctx context.Context, key string, v interface{}) error {
+	view, err := g.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return g.codec.Unmarshal(view.b, v)
+}
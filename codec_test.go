@@ -0,0 +1,30 @@
+package go_cache
+
+import (
+	"context"
+	"testing"
+)
+
+type score struct {
+	Name  string
+	Score int
+}
+
+func TestGetInto(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": JSONCodec, "gob": GobCodec} {
+		t.Run(name, func(t *testing.T) {
+			gee := NewGroup("codec-"+name, 2<<10, GetterFunc(
+				func(ctx context.Context, key string) ([]byte, error) {
+					return codec.Marshal(score{Name: key, Score: 630})
+				}), WithCodec(codec))
+
+			var s score
+			if err := gee.GetInto(context.Background(), "Tom", &s); err != nil {
+				t.Fatal(err)
+			}
+			if s != (score{Name: "Tom", Score: 630}) {
+				t.Fatalf("unexpected value %+v", s)
+			}
+		})
+	}
+}
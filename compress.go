@@ -0,0 +1,44 @@
+package go_cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// compressedValue 压缩后存入 lru 的值，Len 返回压缩后的大小，
+// 因此同样的 cacheBytes 可以容纳更多记录
+type compressedValue struct {
+	b []byte
+}
+
+func (v compressedValue) Len() int {
+	return len(v.b)
+}
+
+// compress 使用 gzip 压缩数据，压缩后没有变小时 ok 为 false
+func compress(b []byte) (compressedValue, bool) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return compressedValue{}, false
+	}
+	if err := w.Close(); err != nil || buf.Len() >= len(b) {
+		return compressedValue{}, false
+	}
+	return compressedValue{b: buf.Bytes()}, true
+}
+
+// decompress 还原压缩的数据
+func (v compressedValue) decompress() (ByteView, error) {
+	r, err := gzip.NewReader(bytes.NewReader(v.b))
+	if err != nil {
+		return ByteView{}, err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return ByteView{}, err
+	}
+	return ByteView{b: b}, nil
+}
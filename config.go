@@ -0,0 +1,94 @@
+package go_cache
+
+import (
+	"errors"
+	"time"
+)
+
+// Config Group 的配置，与对应的 GroupOption 含义相同，零值字段表示不设置
+type Config struct {
+	Name       string
+	CacheBytes int64
+	Getter     Getter
+	// 见 WithTTL、WithStaleWhileRevalidate、WithNegativeTTL
+	TTL         time.Duration
+	StaleTTL    time.Duration
+	NegativeTTL time.Duration
+	// 见 WithTTLJitter
+	TTLJitter float64
+	// 见 WithEarlyRefresh
+	EarlyRefreshBeta float64
+	// 见 WithCompression
+	CompressThreshold int
+	// 见 WithMaxValueSize、WithMaxKeySize
+	MaxValueSize int
+	MaxKeySize   int
+	// 见 WithLoadTimeout、WithMaxConcurrentLoads
+	LoadTimeout        time.Duration
+	MaxConcurrentLoads int
+	// 见 WithClientOnly，设置后 Getter 可以为 nil
+	ClientOnly bool
+}
+
+// Validate 检查配置是否合法
+func (c Config) Validate() error {
+	switch {
+	case c.Name == "":
+		return errors.New("go-cache: Name is required")
+	case c.Getter == nil && !c.ClientOnly:
+		return errors.New("go-cache: Getter is required")
+	case c.CacheBytes < 0:
+		return errors.New("go-cache: CacheBytes must not be negative")
+	case c.TTL < 0 || c.StaleTTL < 0 || c.NegativeTTL < 0 || c.LoadTimeout < 0:
+		return errors.New("go-cache: durations must not be negative")
+	case c.EarlyRefreshBeta < 0:
+		return errors.New("go-cache: EarlyRefreshBeta must not be negative")
+	case c.TTLJitter < 0 || c.TTLJitter >= 1:
+		return errors.New("go-cache: TTLJitter must be in [0, 1)")
+	case (c.StaleTTL > 0 || c.EarlyRefreshBeta > 0) && c.TTL == 0:
+		return errors.New("go-cache: StaleTTL and EarlyRefreshBeta require TTL")
+	case c.CompressThreshold < 0 || c.MaxValueSize < 0 || c.MaxKeySize < 0 || c.MaxConcurrentLoads < 0:
+		return errors.New("go-cache: sizes and limits must not be negative")
+	}
+	return nil
+}
+
+// Options 把配置中除 Name、CacheBytes、Getter 以外的字段转换为 GroupOption
+func (c Config) Options() []GroupOption {
+	opts := []GroupOption{
+		WithTTL(c.TTL),
+		WithStaleWhileRevalidate(c.StaleTTL),
+		WithNegativeTTL(c.NegativeTTL),
+		WithTTLJitter(c.TTLJitter),
+		WithEarlyRefresh(c.EarlyRefreshBeta),
+		WithCompression(c.CompressThreshold),
+		WithMaxValueSize(c.MaxValueSize, nil),
+		WithMaxKeySize(c.MaxKeySize),
+		WithLoadTimeout(c.LoadTimeout),
+	}
+	if c.MaxConcurrentLoads > 0 {
+		opts = append(opts, WithMaxConcurrentLoads(c.MaxConcurrentLoads))
+	}
+	if c.ClientOnly {
+		opts = append(opts, WithClientOnly())
+	}
+	return opts
+}
+
+// NewGroupFromConfig 检查配置后创建 Group，opts 在 cfg 之后应用，可以用来设置 Config 中没有的选项
+func NewGroupFromConfig(cfg Config, opts ...GroupOption) (*Group, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewGroup(cfg.Name, cfg.CacheBytes, cfg.Getter, append(cfg.Options(), opts...)...), nil
+}
+
+// WithCacheBytes 设置缓存允许使用的最大内存，覆盖 NewGroup 的 cacheBytes 参数
+func WithCacheBytes(n int64) GroupOption {
+	return func(g *Group) {
+		g.cacheBytes = n
+		g.mainCache.cacheBytes = n
+		g.hotCache.cacheBytes = n / 8
+		g.negCache.cacheBytes = n / 8
+	}
+}
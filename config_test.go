@@ -0,0 +1,45 @@
+package go_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewGroupFromConfig(t *testing.T) {
+	getter := GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte(key), nil
+	})
+	for _, cfg := range []Config{
+		{Getter: getter},
+		{Name: "config"},
+		{Name: "config", Getter: getter, CacheBytes: -1},
+		{Name: "config", Getter: getter, StaleTTL: time.Second},
+	} {
+		if _, err := NewGroupFromConfig(cfg); err == nil {
+			t.Fatalf("invalid config %+v should be rejected", cfg)
+		}
+	}
+
+	cfg := Config{Name: "config", Getter: getter, CacheBytes: 2 << 10, TTL: time.Minute, MaxConcurrentLoads: 2}
+	g, err := NewGroupFromConfig(cfg, WithCacheBytes(4<<10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := g.Info()
+	if info.CacheBytes != 4<<10 || info.TTL != time.Minute || info.MaxConcurrentLoads != 2 {
+		t.Fatalf("unexpected config %+v", info.Config)
+	}
+
+	cfg = Config{Name: "config-client-only", ClientOnly: true}
+	g, err = NewGroupFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Info().ClientOnly {
+		t.Fatal("ClientOnly should be applied")
+	}
+	if _, err := g.Get(context.Background(), "key"); err != ErrNoPeer {
+		t.Fatalf("expected ErrNoPeer without peers, got %v", err)
+	}
+}
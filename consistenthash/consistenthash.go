@@ -0,0 +1,166 @@
+// Package consistenthash 一致性哈希，把 key 映射到节点上，增删节点时只有少量 key 需要迁移
+package consistenthash
+
+import (
+	"hash/crc32"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Hash 把字节映射到 uint64 的哈希函数，所有节点需要使用相同的哈希函数
+type Hash func(data []byte) uint64
+
+// XXHash 64 位的 xxHash，默认的哈希函数，速度快，短 key 的分布也很均匀
+func XXHash(data []byte) uint64 {
+	return xxhash.Sum64(data)
+}
+
+// CRC32 crc32.ChecksumIEEE，旧版本默认的哈希函数，从旧版本滚动升级时所有节点可以先使用它，
+// 保持 key 所属的节点不变。短 key（例如数字 ID）的分布不均匀，不建议长期使用
+func CRC32(data []byte) uint64 {
+	return uint64(crc32.ChecksumIEEE(data))
+}
+
+// FNV64a 64 位的 FNV-1a
+func FNV64a(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+// Map 一致性哈希环，不是并发安全的
+type Map struct {
+	hash Hash
+	// 每个真实节点对应的虚拟节点数量
+	replicas int
+	// 排好序的虚拟节点的哈希值
+	keys []uint64
+	// 虚拟节点的哈希值到真实节点名称的映射
+	hashMap map[uint64]string
+	// 真实节点的权重
+	nodes map[string]int
+}
+
+// New 实例化 Map，fn 为 nil 时使用 XXHash
+func New(replicas int, fn Hash) *Map {
+	if replicas <= 0 {
+		replicas = 1
+	}
+	m := &Map{
+		replicas: replicas,
+		hash:     fn,
+		hashMap:  make(map[uint64]string),
+		nodes:    make(map[string]int),
+	}
+	if m.hash == nil {
+		m.hash = XXHash
+	}
+	return m
+}
+
+// Add 添加权重为 1 的真实节点，已经存在的节点会被忽略
+func (m *Map) Add(nodes ...string) {
+	for _, node := range nodes {
+		m.add(node, 1)
+	}
+	m.sort()
+}
+
+// AddWeighted 添加权重为 weight 的真实节点，它的虚拟节点数量为 replicas*weight，
+// 负责的 key 与权重大致成正比，weight <= 0 时视为 1。已经存在的节点会被忽略
+func (m *Map) AddWeighted(node string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	m.add(node, weight)
+	m.sort()
+}
+
+func (m *Map) add(node string, weight int) {
+	if _, ok := m.nodes[node]; ok {
+		return
+	}
+	m.nodes[node] = weight
+	for i := 0; i < m.replicas*weight; i++ {
+		h := m.hash([]byte(strconv.Itoa(i) + node))
+		if owner, ok := m.hashMap[h]; ok {
+			// 虚拟节点冲突时保留名称较小的节点，使结果与添加顺序无关
+			if owner > node {
+				m.hashMap[h] = node
+			}
+			continue
+		}
+		m.hashMap[h] = node
+		m.keys = append(m.keys, h)
+	}
+}
+
+func (m *Map) sort() {
+	sort.Slice(m.keys, func(i, j int) bool { return m.keys[i] < m.keys[j] })
+}
+
+// Remove 删除真实节点及其全部虚拟节点
+func (m *Map) Remove(node string) {
+	if _, ok := m.nodes[node]; !ok {
+		return
+	}
+	delete(m.nodes, node)
+	// 被删除的虚拟节点可能与其他节点冲突过，重建整个环最简单可靠
+	nodes := m.nodes
+	m.hashMap = make(map[uint64]string, len(m.keys))
+	m.keys = nil
+	m.nodes = make(map[string]int, len(nodes))
+	for n, weight := range nodes {
+		m.add(n, weight)
+	}
+	m.sort()
+}
+
+// Get 返回 key 所属的真实节点，环为空时返回空字符串
+func (m *Map) Get(key string) string {
+	if len(m.keys) == 0 {
+		return ""
+	}
+	h := m.hash([]byte(key))
+	// 顺时针找到第一个不小于 h 的虚拟节点，超出末尾时回到环的起点
+	idx := sort.Search(len(m.keys), func(i int) bool { return m.keys[i] >= h })
+	return m.hashMap[m.keys[idx%len(m.keys)]]
+}
+
+// GetN 从 key 所在的位置开始顺时针返回最多 n 个不同的真实节点，第一个与 Get 的结果相同，
+// 用于把 key 保存在多个副本上
+func (m *Map) GetN(key string, n int) []string {
+	if len(m.keys) == 0 || n <= 0 {
+		return nil
+	}
+	if n > len(m.nodes) {
+		n = len(m.nodes)
+	}
+	h := m.hash([]byte(key))
+	idx := sort.Search(len(m.keys), func(i int) bool { return m.keys[i] >= h })
+	nodes := make([]string, 0, n)
+	for i := 0; i < len(m.keys) && len(nodes) < n; i++ {
+		node := m.hashMap[m.keys[(idx+i)%len(m.keys)]]
+		if !contains(nodes, node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func contains(nodes []string, node string) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// Len 返回真实节点的数量
+func (m *Map) Len() int {
+	return len(m.nodes)
+}
@@ -0,0 +1,148 @@
+package consistenthash
+
+import (
+	"hash/crc32"
+	"strconv"
+	"testing"
+)
+
+func TestHashing(t *testing.T) {
+	hash := New(3, func(key []byte) uint64 {
+		i, _ := strconv.Atoi(string(key))
+		return uint64(i)
+	})
+
+	// 虚拟节点为 2, 4, 6, 12, 14, 16, 22, 24, 26
+	hash.Add("6", "4", "2")
+
+	testCases := map[string]string{
+		"2":  "2",
+		"11": "2",
+		"23": "4",
+		"27": "2",
+	}
+	for k, v := range testCases {
+		if hash.Get(k) != v {
+			t.Errorf("Asking for %s, should have yielded %s", k, v)
+		}
+	}
+
+	// 新增虚拟节点 8, 18, 28
+	hash.Add("8")
+	testCases["27"] = "8"
+	for k, v := range testCases {
+		if hash.Get(k) != v {
+			t.Errorf("Asking for %s, should have yielded %s", k, v)
+		}
+	}
+
+	hash.Remove("8")
+	testCases["27"] = "2"
+	for k, v := range testCases {
+		if hash.Get(k) != v {
+			t.Errorf("Asking for %s, should have yielded %s", k, v)
+		}
+	}
+	if hash.Len() != 3 {
+		t.Errorf("expected 3 nodes but got %d", hash.Len())
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	a := New(50, nil)
+	b := New(50, nil)
+	a.Add("http://a", "http://b", "http://c")
+	b.Add("http://c", "http://a", "http://b")
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		if a.Get(key) != b.Get(key) {
+			t.Fatalf("%s: result should not depend on the order of Add", key)
+		}
+	}
+}
+
+func TestCollision(t *testing.T) {
+	constant := func([]byte) uint64 { return 1 }
+	a := New(1, constant)
+	b := New(1, constant)
+	a.Add("x", "y")
+	b.Add("y", "x")
+	if a.Get("k") != "x" || b.Get("k") != "x" {
+		t.Fatalf("colliding nodes should resolve deterministically")
+	}
+	a.Remove("x")
+	if a.Get("k") != "y" {
+		t.Fatalf("remaining node should take over after Remove")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	if New(3, nil).Get("k") != "" {
+		t.Fatalf("empty ring should return empty string")
+	}
+}
+
+func TestGetN(t *testing.T) {
+	hash := New(3, func(key []byte) uint64 {
+		i, _ := strconv.Atoi(string(key))
+		return uint64(i)
+	})
+	// 虚拟节点为 2, 4, 6, 12, 14, 16, 22, 24, 26
+	hash.Add("6", "4", "2")
+
+	if got := hash.GetN("23", 2); len(got) != 2 || got[0] != "4" || got[1] != "6" {
+		t.Fatalf("expected [4 6] but got %v", got)
+	}
+	if got := hash.GetN("27", 5); len(got) != 3 || got[0] != hash.Get("27") {
+		t.Fatalf("should return every node once starting from the owner, got %v", got)
+	}
+	if got := New(3, nil).GetN("k", 2); got != nil {
+		t.Fatalf("empty ring should return nil, got %v", got)
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	pickers := map[string]WeightedPicker{"ring": New(50, nil), "rendezvous": NewRendezvous(nil)}
+	for name, p := range pickers {
+		p.AddWeighted("small", 1)
+		p.AddWeighted("large", 3)
+		counts := make(map[string]int)
+		const keys = 40000
+		for i := 0; i < keys; i++ {
+			counts[p.Get(strconv.Itoa(i))]++
+		}
+		if share := float64(counts["large"]) / keys; share < 0.65 || share > 0.85 {
+			t.Fatalf("%s: large node owns %.2f of the keys, expected about 0.75", name, share)
+		}
+	}
+
+	// Remove 重建环时保留其他节点的权重
+	m := New(10, nil)
+	m.AddWeighted("a", 2)
+	m.Add("b", "c")
+	m.Remove("c")
+	if len(m.keys) != 30 {
+		t.Fatalf("expected 30 virtual nodes after Remove, got %d", len(m.keys))
+	}
+}
+
+func TestNumericKeys(t *testing.T) {
+	for name, fn := range map[string]Hash{"xxhash": XXHash, "fnv64a": FNV64a} {
+		m := New(50, fn)
+		nodes := []string{"10.0.0.1:8001", "10.0.0.2:8001", "10.0.0.3:8001", "10.0.0.4:8001"}
+		m.Add(nodes...)
+		counts := make(map[string]int)
+		const keys = 40000
+		for i := 0; i < keys; i++ {
+			counts[m.Get(strconv.Itoa(i))]++
+		}
+		for _, node := range nodes {
+			if share := float64(counts[node]) / keys; share < 0.15 || share > 0.35 {
+				t.Fatalf("%s: %s owns %.2f of the numeric keys, expected about 0.25", name, node, share)
+			}
+		}
+	}
+	if CRC32([]byte("Tom")) != uint64(crc32.ChecksumIEEE([]byte("Tom"))) {
+		t.Fatalf("CRC32 should match crc32.ChecksumIEEE")
+	}
+}
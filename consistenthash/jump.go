@@ -0,0 +1,80 @@
+package consistenthash
+
+import "github.com/cespare/xxhash/v2"
+
+// Jump 跳跃一致性哈希（Lamping & Veach）：把 key 映射到按添加顺序编号的节点上，
+// 不需要额外的内存，查找时不分配内存，比哈希环快得多。只有在末尾添加或删除节点时迁移的 key 最少，
+// 删除中间的节点会改变后面节点的编号，因此适合成员稳定、按固定顺序编号的集群。不是并发安全的
+type Jump struct {
+	// 为 nil 时使用 xxhash.Sum64String，避免把 key 转换为 []byte
+	hash  Hash
+	nodes []string
+	index map[string]int
+}
+
+// NewJump 实例化 Jump，fn 为 nil 时使用 XXHash
+func NewJump(fn Hash) *Jump {
+	return &Jump{hash: fn, index: make(map[string]int)}
+}
+
+// Add 按顺序在末尾添加节点，已经存在的节点会被忽略
+func (j *Jump) Add(nodes ...string) {
+	for _, node := range nodes {
+		if _, ok := j.index[node]; ok {
+			continue
+		}
+		j.index[node] = len(j.nodes)
+		j.nodes = append(j.nodes, node)
+	}
+}
+
+// Get 返回 key 所属的节点，没有节点时返回空字符串
+func (j *Jump) Get(key string) string {
+	if len(j.nodes) == 0 {
+		return ""
+	}
+	return j.nodes[JumpHash(j.sum(key), len(j.nodes))]
+}
+
+// GetN 返回 key 所属的节点以及编号在它之后的节点，最多 n 个，用于把 key 保存在多个副本上
+func (j *Jump) GetN(key string, n int) []string {
+	if len(j.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	if n > len(j.nodes) {
+		n = len(j.nodes)
+	}
+	b := JumpHash(j.sum(key), len(j.nodes))
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = j.nodes[(b+i)%len(j.nodes)]
+	}
+	return nodes
+}
+
+// Len 返回节点的数量
+func (j *Jump) Len() int {
+	return len(j.nodes)
+}
+
+func (j *Jump) sum(key string) uint64 {
+	if j.hash != nil {
+		return j.hash([]byte(key))
+	}
+	return xxhash.Sum64String(key)
+}
+
+// JumpHash 返回 key 所属的桶，范围为 [0, buckets)，buckets <= 0 时返回 -1。
+// 桶的数量从 n 增加到 n+1 时只有 1/(n+1) 的 key 移动到新的桶
+func JumpHash(key uint64, buckets int) int {
+	if buckets <= 0 {
+		return -1
+	}
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,57 @@
+package consistenthash
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestJumpHash(t *testing.T) {
+	if JumpHash(42, 0) != -1 {
+		t.Fatalf("no buckets should return -1")
+	}
+	// 增加一个桶时 key 要么不动，要么移动到新的桶
+	for key := uint64(0); key < 10000; key++ {
+		prev := JumpHash(key, 1)
+		if prev != 0 {
+			t.Fatalf("single bucket should always be 0, got %d", prev)
+		}
+		for n := 2; n <= 16; n++ {
+			b := JumpHash(key, n)
+			if b != prev && b != n-1 {
+				t.Fatalf("key %d moved from %d to %d when growing to %d buckets", key, prev, b, n)
+			}
+			prev = b
+		}
+	}
+}
+
+func TestJump(t *testing.T) {
+	j := NewJump(nil)
+	nodes := []string{"10.0.0.1:8001", "10.0.0.2:8001", "10.0.0.3:8001", "10.0.0.4:8001"}
+	j.Add(nodes...)
+	j.Add(nodes[0])
+	if j.Len() != 4 {
+		t.Fatalf("expected 4 nodes but got %d", j.Len())
+	}
+	counts := make(map[string]int)
+	const keys = 40000
+	for i := 0; i < keys; i++ {
+		counts[j.Get(strconv.Itoa(i))]++
+	}
+	for _, node := range nodes {
+		if share := float64(counts[node]) / keys; share < 0.2 || share > 0.3 {
+			t.Fatalf("%s owns %.2f of the keys, expected about 0.25", node, share)
+		}
+	}
+
+	got := j.GetN("Tom", 6)
+	if len(got) != 4 || got[0] != j.Get("Tom") {
+		t.Fatalf("should return every node once starting from the owner, got %v", got)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { j.Get("Tom") }); allocs != 0 {
+		t.Fatalf("Get should not allocate, got %v allocations", allocs)
+	}
+	if NewJump(nil).Get("k") != "" || NewJump(nil).GetN("k", 2) != nil {
+		t.Fatalf("empty picker should return nothing")
+	}
+}
@@ -0,0 +1,150 @@
+package consistenthash
+
+import (
+	"math"
+	"sort"
+)
+
+// Picker 把 key 映射到节点，Map、Rendezvous 与 Jump 都实现了它，不是并发安全的
+type Picker interface {
+	// Add 添加节点，已经存在的节点会被忽略
+	Add(nodes ...string)
+	// Get 返回 key 所属的节点，没有节点时返回空字符串
+	Get(key string) string
+	// GetN 按优先级返回 key 所属的最多 n 个不同的节点，第一个与 Get 的结果相同
+	GetN(key string, n int) []string
+	// Len 返回节点的数量
+	Len() int
+}
+
+// WeightedPicker 可选接口，支持按权重添加节点，节点负责的 key 与权重大致成正比，Map 与 Rendezvous 实现了它
+type WeightedPicker interface {
+	Picker
+	AddWeighted(node string, weight int)
+}
+
+var (
+	_ WeightedPicker = (*Map)(nil)
+	_ WeightedPicker = (*Rendezvous)(nil)
+	_ Picker         = (*Jump)(nil)
+)
+
+// Rendezvous 最高随机权重（HRW）哈希：对每个节点计算 key 与节点组合的分数，分数最高的节点负责 key。
+// 不需要虚拟节点，节点数量较少时分布比哈希环更均匀，增删节点时只有该节点负责的 key 需要迁移，
+// 每次查找的开销与节点数量成正比，适合中小规模的集群。不是并发安全的
+type Rendezvous struct {
+	hash Hash
+	// 按名称排好序的节点以及它们的哈希值和权重
+	nodes   []string
+	hashes  []uint64
+	weights []float64
+}
+
+// NewRendezvous 实例化 Rendezvous，fn 为 nil 时使用 XXHash
+func NewRendezvous(fn Hash) *Rendezvous {
+	if fn == nil {
+		fn = XXHash
+	}
+	return &Rendezvous{hash: fn}
+}
+
+// Add 添加权重为 1 的节点，已经存在的节点会被忽略
+func (r *Rendezvous) Add(nodes ...string) {
+	for _, node := range nodes {
+		r.AddWeighted(node, 1)
+	}
+}
+
+// AddWeighted 添加权重为 weight 的节点，weight <= 0 时视为 1。已经存在的节点会被忽略
+func (r *Rendezvous) AddWeighted(node string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	i := sort.SearchStrings(r.nodes, node)
+	if i < len(r.nodes) && r.nodes[i] == node {
+		return
+	}
+	r.nodes = append(r.nodes, "")
+	copy(r.nodes[i+1:], r.nodes[i:])
+	r.nodes[i] = node
+	r.hashes = append(r.hashes, 0)
+	copy(r.hashes[i+1:], r.hashes[i:])
+	r.hashes[i] = r.hash([]byte(node))
+	r.weights = append(r.weights, 0)
+	copy(r.weights[i+1:], r.weights[i:])
+	r.weights[i] = float64(weight)
+}
+
+// Remove 删除节点
+func (r *Rendezvous) Remove(node string) {
+	i := sort.SearchStrings(r.nodes, node)
+	if i == len(r.nodes) || r.nodes[i] != node {
+		return
+	}
+	r.nodes = append(r.nodes[:i], r.nodes[i+1:]...)
+	r.hashes = append(r.hashes[:i], r.hashes[i+1:]...)
+	r.weights = append(r.weights[:i], r.weights[i+1:]...)
+}
+
+// Get 返回分数最高的节点，没有节点时返回空字符串。分数相同时选择名称较小的节点
+func (r *Rendezvous) Get(key string) string {
+	if len(r.nodes) == 0 {
+		return ""
+	}
+	h := r.hash([]byte(key))
+	best, bestScore := 0, r.score(h, 0)
+	for i := 1; i < len(r.nodes); i++ {
+		if s := r.score(h, i); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return r.nodes[best]
+}
+
+// GetN 按分数从高到低返回最多 n 个节点，第一个与 Get 的结果相同，用于把 key 保存在多个副本上
+func (r *Rendezvous) GetN(key string, n int) []string {
+	if len(r.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	if n > len(r.nodes) {
+		n = len(r.nodes)
+	}
+	h := r.hash([]byte(key))
+	idx := make([]int, len(r.nodes))
+	scores := make([]float64, len(r.nodes))
+	for i := range r.nodes {
+		idx[i] = i
+		scores[i] = r.score(h, i)
+	}
+	// nodes 按名称排序，稳定排序使分数相同时名称较小的节点在前
+	sort.SliceStable(idx, func(a, b int) bool { return scores[idx[a]] > scores[idx[b]] })
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = r.nodes[idx[i]]
+	}
+	return nodes
+}
+
+// Len 返回节点的数量
+func (r *Rendezvous) Len() int {
+	return len(r.nodes)
+}
+
+// score 返回哈希值为 key 的 key 在第 i 个节点上的分数 -weight/ln(u)，
+// u 为混合 key 与节点的哈希值得到的 (0, 1) 之间的均匀分布，节点得到最高分的概率与权重成正比
+func (r *Rendezvous) score(key uint64, i int) float64 {
+	u := (float64(mix(key, r.hashes[i])>>11) + 0.5) / (1 << 53)
+	return -r.weights[i] / math.Log(u)
+}
+
+// mix 混合 key 与节点的哈希值（splitmix64 的终结函数），
+// 使同一个 key 在不同节点上的分数相互独立
+func mix(key, node uint64) uint64 {
+	x := key ^ (node * 0x9e3779b97f4a7c15)
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,69 @@
+package consistenthash
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRendezvous(t *testing.T) {
+	a := NewRendezvous(nil)
+	b := NewRendezvous(nil)
+	a.Add("http://a", "http://b", "http://c")
+	b.Add("http://c", "http://a", "http://b", "http://a")
+	if a.Len() != 3 || b.Len() != 3 {
+		t.Fatalf("expected 3 nodes but got %d and %d", a.Len(), b.Len())
+	}
+	owners := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := "key" + strconv.Itoa(i)
+		if a.Get(key) != b.Get(key) {
+			t.Fatalf("%s: result should not depend on the order of Add", key)
+		}
+		owners[key] = a.Get(key)
+	}
+
+	// 只有被删除的节点负责的 key 需要迁移
+	a.Remove("http://b")
+	for key, owner := range owners {
+		if got := a.Get(key); owner != "http://b" && got != owner {
+			t.Fatalf("%s moved from %s to %s after removing another node", key, owner, got)
+		}
+	}
+}
+
+func TestRendezvousBalance(t *testing.T) {
+	r := NewRendezvous(nil)
+	nodes := []string{"10.0.0.1:8001", "10.0.0.2:8001", "10.0.0.3:8001", "10.0.0.4:8001"}
+	r.Add(nodes...)
+	counts := make(map[string]int)
+	const keys = 40000
+	for i := 0; i < keys; i++ {
+		counts[r.Get(strconv.Itoa(i))]++
+	}
+	for _, node := range nodes {
+		if share := float64(counts[node]) / keys; share < 0.2 || share > 0.3 {
+			t.Fatalf("%s owns %.2f of the keys, expected about 0.25", node, share)
+		}
+	}
+}
+
+func TestRendezvousGetN(t *testing.T) {
+	r := NewRendezvous(nil)
+	r.Add("a", "b", "c")
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		got := r.GetN(key, 5)
+		if len(got) != 3 || got[0] != r.Get(key) {
+			t.Fatalf("should return every node once starting from the owner, got %v", got)
+		}
+		if got[0] == got[1] || got[1] == got[2] || got[0] == got[2] {
+			t.Fatalf("nodes should be distinct, got %v", got)
+		}
+	}
+	if got := NewRendezvous(nil).GetN("k", 2); got != nil {
+		t.Fatalf("empty picker should return nil, got %v", got)
+	}
+	if NewRendezvous(nil).Get("k") != "" {
+		t.Fatalf("empty picker should return empty string")
+	}
+}
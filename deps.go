@@ -0,0 +1,26 @@
+package go_cache
+
+// DependsOn 声明 key 的值由 deps 计算而来，之后通过 Set 或 Remove 修改 deps 中的任意一个时，
+// key（以及依赖 key 的其他 key）会被一并从缓存中删除，避免缓存中残留过期的聚合结果。
+// 依赖关系只在 key 保存在缓存中期间有效，key 被淘汰后需要重新声明；再次调用会覆盖之前声明的依赖。
+func (g *Group) DependsOn(key string, deps ...string) {
+	g.deps.tag(key, deps)
+}
+
+// invalidateDependents 删除直接或间接依赖 key 的所有 key，依赖关系中存在环时每个 key 只处理一次
+func (g *Group) invalidateDependents(key string) {
+	visited := map[string]bool{key: true}
+	queue := []string{key}
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		for _, k := range g.deps.keysOf(dep) {
+			if visited[k] {
+				continue
+			}
+			visited[k] = true
+			g.removeLocally(k)
+			queue = append(queue, k)
+		}
+	}
+}
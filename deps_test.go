@@ -0,0 +1,42 @@
+package go_cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDependsOn(t *testing.T) {
+	store := &memStore{m: map[string]string{}}
+	gee := NewGroup("deps", 2<<10, store)
+	ctx := context.Background()
+
+	for _, key := range []string{"order:1", "order:2", "user:1:total", "report", "unrelated"} {
+		gee.Set(ctx, key, []byte("v"), 0)
+	}
+	gee.DependsOn("user:1:total", "order:1", "order:2")
+	gee.DependsOn("report", "user:1:total")
+	// 依赖关系中的环不会导致死循环
+	gee.DependsOn("order:2", "report")
+
+	if err := gee.Set(ctx, "order:1", []byte("updated"), 0); err != nil {
+		t.Fatal(err)
+	}
+	for key, cached := range map[string]bool{
+		"order:1":      true,
+		"user:1:total": false,
+		"report":       false,
+		"order:2":      false,
+		"unrelated":    true,
+	} {
+		if _, ok := gee.mainCache.get(key); ok != cached {
+			t.Fatalf("%s: expected cached=%v", key, cached)
+		}
+	}
+
+	gee.Set(ctx, "user:1:total", []byte("v"), 0)
+	gee.DependsOn("user:1:total", "order:1")
+	gee.Remove(ctx, "order:1")
+	if _, ok := gee.mainCache.get("user:1:total"); ok {
+		t.Fatalf("Remove should invalidate dependents")
+	}
+}
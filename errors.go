@@ -0,0 +1,61 @@
+package go_cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound Getter 在数据源中找不到 key 时应当返回该错误（或包装了该错误的错误），
+// 配置了 WithNegativeTTL 时这类结果会被缓存
+var ErrNotFound = errors.New("go-cache: not found")
+
+// ErrNoSetter Group.Set 要求 Getter 同时实现 Setter 接口（配置了 WithWriteBehind 时实现 BatchSetter 也可以），
+// 否则返回该错误
+var ErrNoSetter = errors.New("go-cache: getter does not implement Setter")
+
+// ErrWriteBufferFull write-behind 模式下缓冲区已满，本次 Set 没有生效
+var ErrWriteBufferFull = errors.New("go-cache: write-behind buffer is full")
+
+// ErrGroupClosed Group 已经调用过 Close
+var ErrGroupClosed = errors.New("go-cache: group is closed")
+
+// ErrLoadThrottled 同时进行的加载数量超过了 WithMaxConcurrentLoads 的限制
+var ErrLoadThrottled = errors.New("go-cache: too many concurrent loads")
+
+// ErrCacheMiss Group.Peek 在缓存中找不到 key
+var ErrCacheMiss = errors.New("go-cache: cache miss")
+
+// ErrEmptyKey key 为空字符串
+var ErrEmptyKey = errors.New("go-cache: key is required")
+
+// ErrKeyTooLarge key 超过了 WithMaxKeySize 的限制
+var ErrKeyTooLarge = errors.New("go-cache: key is too large")
+
+// ErrNoPeer 配置了 WithClientOnly 并且没有 Getter 的 Group 找不到可用的节点
+var ErrNoPeer = errors.New("go-cache: no peer available")
+
+// ErrClientOnly 配置了 WithClientOnly 的 Group 没有 mainCache，不能 Restore 快照
+var ErrClientOnly = errors.New("go-cache: group is client-only")
+
+// ErrVersionMismatch Group.Cas 的 expected 与 key 当前的版本不一致
+var ErrVersionMismatch = errors.New("go-cache: version mismatch")
+
+// LoadError 包装 Getter 返回的错误，记录出错的 Group 和 key，
+// 可以通过 errors.Is(err, ErrNotFound) 或 errors.As 判断原始错误
+type LoadError struct {
+	Group string
+	// 出错的 key，GetMulti 批量加载时为空
+	Key string
+	Err error
+}
+
+func (e *LoadError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("go-cache: group %q: load: %v", e.Group, e.Err)
+	}
+	return fmt.Sprintf("go-cache: group %q: load %q: %v", e.Group, e.Key, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
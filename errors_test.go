@@ -0,0 +1,50 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	unavailable := errors.New("database unavailable")
+	gee := NewGroup("errors", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			switch key {
+			case "missing":
+				return nil, ErrNotFound
+			case "broken":
+				return nil, unavailable
+			}
+			return []byte(key), nil
+		}), WithMaxKeySize(8))
+	ctx := context.Background()
+
+	_, err := gee.Get(ctx, "broken")
+	var le *LoadError
+	if !errors.As(err, &le) || le.Group != "errors" || le.Key != "broken" || !errors.Is(err, unavailable) {
+		t.Fatalf("backend error should be wrapped with group and key, got %v", err)
+	}
+	if _, err := gee.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := gee.Get(ctx, ""); err != ErrEmptyKey {
+		t.Fatalf("expected ErrEmptyKey, got %v", err)
+	}
+	if _, err := gee.Get(ctx, strings.Repeat("k", 9)); err != ErrKeyTooLarge {
+		t.Fatalf("expected ErrKeyTooLarge, got %v", err)
+	}
+	if _, err := gee.Peek("Tom"); err != ErrCacheMiss {
+		t.Fatalf("expected ErrCacheMiss, got %v", err)
+	}
+	gee.Get(ctx, "Tom")
+	if v, err := gee.Peek("Tom"); err != nil || v.String() != "Tom" {
+		t.Fatalf("unexpected result %q %v", v.String(), err)
+	}
+
+	gee.Close(ctx)
+	if _, err := gee.Get(ctx, "Tom"); err != ErrGroupClosed {
+		t.Fatalf("expected ErrGroupClosed, got %v", err)
+	}
+}
@@ -1,9 +1,18 @@
 package go_cache
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"go-cache/hotkey"
+	"go-cache/singleflight"
+	"go-cache/tinylfu"
 	"log"
+	"math"
+	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Group 负责与外部交互，控制缓存存储和获取的主流程
@@ -12,18 +21,124 @@ type Group struct {
 	name string
 	// 缓存未命中时获取源数据的回调(callback)
 	getter Getter
-	// 并发缓存
+	// mainCache 与 hotCache 共享的内存上限
+	cacheBytes int64
+	// 本节点负责的 key
 	mainCache cache
+	// 由其他节点负责、但在本节点访问频繁的 key，避免反复请求同一个节点，最多占用 cacheBytes 的 1/8
+	hotCache cache
+	// 统计从其他节点加载的 key 的访问频次，决定是否放入 hotCache
+	hotMu   sync.Mutex
+	hotKeys *tinylfu.TinyLFU
+	// 配置了 WithPeers 时用于选择负责 key 的节点
+	peers PeerPicker
+	// 保证相同的 key 并发未命中时只加载一次
+	loader *singleflight.Group
+	// 缓存数据源中不存在的 key，避免反复访问数据源
+	negCache    cache
+	negativeTTL time.Duration
+	// 缓存值的有效期，0 表示永不过期
+	ttl time.Duration
+	// 过期后仍然可以返回旧值的时间，期间由后台协程刷新
+	staleTTL time.Duration
+	// 提前刷新的 beta 系数，0 表示不提前刷新
+	earlyRefreshBeta float64
+	// 加载耗时的滑动平均值（纳秒），用于估算提前刷新的时机
+	loadDuration int64
+	// Set 的次数，加载期间发生过写入时不再缓存加载结果，避免覆盖更新的值
+	writes int64
+	// 配置了 WithWriteBehind 时异步写入数据源
+	writeBehind *writeBehind
+	// 统计信息
+	stats groupStats
+	// 每次从数据源加载之后调用
+	loadObservers []func(d time.Duration, err error)
+	// 配置了 WithTracer 时记录每次 Get
+	tracer Tracer
+	// GetInto 使用的编解码方式
+	codec Codec
+	// 超过该大小的值不写入缓存，0 表示不限制
+	maxValueSize int
+	onOversize   func(key string, size int)
+	// 单次加载的最长时间，0 表示不限制
+	loadTimeout time.Duration
+	// 并发加载的名额，nil 表示不限制
+	loadSem chan struct{}
+	// 标签到 key 的索引
+	tags tagIndex
+	// 被依赖的 key 到依赖它的 key 的索引
+	deps tagIndex
+	// key 的最大长度，0 表示不限制
+	maxKeySize int
+	// 调用 Close 之后为 1
+	closed int32
+	// 配置了 WithErrorBackoff 时缓存加载失败的错误
+	errCache *errorCache
+	// 有效期随机浮动的比例
+	ttlJitter float64
+	// 配置了 WithMissingKeyFilter 时记录数据源中不存在的 key
+	missing *missingFilter
+	// 配置了 WithHotKeyDetection 时统计每个 key 的访问频次
+	detectorMu sync.Mutex
+	detector   *hotkey.Detector
+	onHot      func(key string, qps float64)
+	// 配置了 WithHotKeyReplication 时热点 key 复制到的节点数，-1 表示全部节点
+	hotReplicas int
+	// 配置了 WithReadRepair 时检查副本是否一致的概率
+	readRepair float64
+	// 配置了 WithHintedHandoff 时暂存同步副本失败的写入
+	handoff *handoff
+	// 请求其他节点的超时时间、重试次数（-1 表示每个副本请求一次）以及 hedged 请求
+	peerTimeout time.Duration
+	peerRetries int
+	hedging     *hedging
+	// 配置了 WithPeerCircuitBreaker 时每个节点的熔断器
+	breakers *breakers
+	// Warm 的进度回调
+	warmProgress func(done, total int)
+	// 中间件以及组合了中间件之后的 Get、Set
+	getMiddleware []GetMiddleware
+	setMiddleware []SetMiddleware
+	getFunc       GetFunc
+	setFunc       SetFunc
+	// 最近分配或者见过的版本，保证本节点分配的版本单调递增
+	clock int64
+	// 按 key 分段的锁，串行化同一个 key 的 Set、Cas 以及其他节点同步的写入
+	keyLocks [keyLockStripes]sync.Mutex
+	// 配置了 WithClientOnly 时不负责任何 key，不使用 mainCache
+	clientOnly bool
 }
 
+// Getter 缓存未命中时，用于从数据源加载 key 对应的数据。
+// ctx 来自 Group.Get 的调用方，实现应当在 ctx 取消时尽快返回。
 type Getter interface {
-	Get(key string) ([]byte, error)
+	Get(ctx context.Context, key string) ([]byte, error)
 }
 
-type GetterFunc func(key string) ([]byte, error)
+// GetterFunc 函数类型实现 Getter 接口，方便直接传入函数作为 Getter
+type GetterFunc func(ctx context.Context, key string) ([]byte, error)
 
-func (f GetterFunc) Get(key string) ([]byte, error) {
-	return f(key)
+// Get 实现 Getter 接口
+func (f GetterFunc) Get(ctx context.Context, key string) ([]byte, error) {
+	return f(ctx, key)
+}
+
+// Setter 可选接口，Getter 同时实现 Setter 时，Group.Set 先写入数据源再更新缓存。
+// ttl 为调用方传入的有效期，数据源不支持过期时可以忽略。
+type Setter interface {
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// TTLGetter 可选接口，Getter 同时实现 TTLGetter 时，加载时调用 GetWithTTL 代替 Get，
+// 由数据源决定每个值的有效期，例如根据 HTTP 的 Cache-Control。ttl <= 0 时使用 WithTTL 设置的有效期
+type TTLGetter interface {
+	GetWithTTL(ctx context.Context, key string) (value []byte, ttl time.Duration, err error)
+}
+
+// BatchGetter 可选接口，Getter 同时实现 BatchGetter 时，GetMulti 把所有未命中的 key
+// 合并为一次调用。数据源中不存在的 key 不出现在返回的 map 中即可。
+type BatchGetter interface {
+	GetMulti(ctx context.Context, keys []string) (map[string][]byte, error)
 }
 
 var (
@@ -31,21 +146,160 @@ var (
 	groups = make(map[string]*Group)
 )
 
-func NewGroup(name string, cacheBytes int64, getter Getter) *Group {
-	if getter == nil {
-		panic("nil Getter")
+// GroupOption 构造 Group 时的可选配置
+type GroupOption func(*Group)
+
+// WithCompression 使用 gzip 压缩超过 threshold 字节的值后再存入缓存，读取时自动解压。
+// 适合 JSON 之类压缩率高的数据，同样的 cacheBytes 可以容纳更多记录。
+func WithCompression(threshold int) GroupOption {
+	return func(g *Group) {
+		g.mainCache.compressThreshold = threshold
+		g.hotCache.compressThreshold = threshold
+	}
+}
+
+// WithTTL 设置缓存值的有效期，过期后重新从数据源加载
+func WithTTL(ttl time.Duration) GroupOption {
+	return func(g *Group) {
+		g.ttl = ttl
 	}
+}
+
+// WithTTLJitter 写入缓存时让每个值的有效期在 [ttl*(1-fraction), ttl*(1+fraction)] 之间随机浮动，
+// 例如 0.1 表示 ±10%，避免同时加载的大量值在同一时刻过期，集中访问数据源
+func WithTTLJitter(fraction float64) GroupOption {
+	return func(g *Group) {
+		g.ttlJitter = fraction
+	}
+}
+
+// WithStaleWhileRevalidate 值过期后的 maxStale 时间内，Get 立即返回旧值，
+// 同时由一个后台协程通过 Getter 刷新，数据源很慢时也能保持稳定的延迟，代价是数据最多陈旧 maxStale。
+// 需要与 WithTTL 一起使用。
+func WithStaleWhileRevalidate(maxStale time.Duration) GroupOption {
+	return func(g *Group) {
+		g.staleTTL = maxStale
+	}
+}
+
+// WithEarlyRefresh 使用 XFetch 算法概率性地提前刷新即将过期的值：
+// 越接近过期时间、加载越慢，Get 触发后台刷新的概率越高，热点 key 因此几乎不会同步过期。
+// beta 越大越倾向于提前刷新，通常取 1。需要与 WithTTL 一起使用。
+func WithEarlyRefresh(beta float64) GroupOption {
+	return func(g *Group) {
+		g.earlyRefreshBeta = beta
+	}
+}
+
+// WithLoadObserver 每次从数据源加载之后调用 observe，参数为加载耗时和 Getter 返回的错误，
+// 可以用于统计加载延迟。observe 在加载的协程中同步执行，应当尽快返回。
+func WithLoadObserver(observe func(d time.Duration, err error)) GroupOption {
+	return func(g *Group) {
+		g.loadObservers = append(g.loadObservers, observe)
+	}
+}
+
+// WithMaxValueSize 超过 n 字节的值照常返回给调用方，但不写入缓存，避免单个大值挤掉整个缓存。
+// onOversize 不为 nil 时，每次拒绝写入都会调用它，被拒绝的次数也计入 Stats().OversizedValues。
+func WithMaxValueSize(n int, onOversize func(key string, size int)) GroupOption {
+	return func(g *Group) {
+		g.maxValueSize = n
+		g.onOversize = onOversize
+	}
+}
+
+// WithLoadTimeout 为每次加载的 ctx 加上超时时间 d，Getter 应当在 ctx 结束时尽快返回
+func WithLoadTimeout(d time.Duration) GroupOption {
+	return func(g *Group) {
+		g.loadTimeout = d
+	}
+}
+
+// WithMaxConcurrentLoads 限制同时访问数据源的加载数量（相同 key 的并发请求只算一次），
+// 超过 n 时直接返回 ErrLoadThrottled 而不是排队，以免冷启动时大量未命中压垮数据源
+func WithMaxConcurrentLoads(n int) GroupOption {
+	return func(g *Group) {
+		g.loadSem = make(chan struct{}, n)
+	}
+}
+
+// WithMaxKeySize 限制 key 的最大长度，超过 n 字节的 key 返回 ErrKeyTooLarge
+func WithMaxKeySize(n int) GroupOption {
+	return func(g *Group) {
+		g.maxKeySize = n
+	}
+}
+
+// WithHotKeyDetection 统计 Get 访问的 key 的频次，通过 HottestKeys 返回最热的 k 个 key。
+// threshold > 0 时，key 每秒的访问次数超过 threshold 会调用 onHot（每秒最多一次），
+// onHot 在 Get 的协程中同步执行，应当尽快返回
+func WithHotKeyDetection(k int, threshold float64, onHot func(key string, qps float64)) GroupOption {
+	return func(g *Group) {
+		g.onHot = onHot
+		g.detector = hotkey.New(k, hotKeyCounters, time.Second, threshold, g.onHotKey)
+	}
+}
+
+// WithNegativeTTL 缓存 Getter 返回 ErrNotFound 的结果 ttl 时间，
+// 期间再次获取该 key 直接返回 ErrNotFound，不会访问数据源。
+// 这类记录只保存 key，使用 cacheBytes 的 1/8 作为内存上限。
+func WithNegativeTTL(ttl time.Duration) GroupOption {
+	return func(g *Group) {
+		g.negativeTTL = ttl
+	}
+}
+
+// NewGroup 创建名为 name 的 Group，cacheBytes 为缓存允许使用的最大内存，
+// getter 在缓存未命中时加载源数据，只有配置了 WithClientOnly 时可以为 nil
+func NewGroup(name string, cacheBytes int64, getter Getter, opts ...GroupOption) *Group {
 	mu.Lock()
 	defer mu.Unlock()
 	g := &Group{
-		name:      name,
-		getter:    getter,
-		mainCache: cache{cacheBytes: cacheBytes},
+		name:        name,
+		getter:      getter,
+		cacheBytes:  cacheBytes,
+		mainCache:   cache{cacheBytes: cacheBytes},
+		hotCache:    cache{cacheBytes: cacheBytes / 8},
+		hotKeys:     tinylfu.New(hotKeyCounters),
+		codec:       JSONCodec,
+		loader:      &singleflight.Group{},
+		negCache:    cache{cacheBytes: cacheBytes / 8},
+		peerRetries: -1,
 	}
+	g.mainCache.onEvicted = func(key string) {
+		g.tags.untag(key)
+		g.deps.untag(key)
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.clientOnly {
+		// 不使用 mainCache，hotCache 可以使用全部内存
+		g.hotCache.cacheBytes = g.cacheBytes
+	} else if getter == nil {
+		panic("nil Getter")
+	}
+	g.buildMiddleware()
 	groups[name] = g
 	return g
 }
 
+// HottestKeys 返回最近一秒内访问最多的 n 个 key，没有配置 WithHotKeyDetection 时返回 nil
+func (g *Group) HottestKeys(n int) []hotkey.KeyStat {
+	if g.detector == nil {
+		return nil
+	}
+	g.detectorMu.Lock()
+	defer g.detectorMu.Unlock()
+	return g.detector.Top(n)
+}
+
+// Name 返回 Group 的名称
+func (g *Group) Name() string {
+	return g.name
+}
+
+// GetGroup 返回名为 name 的 Group，不存在时返回 nil
 func GetGroup(name string) *Group {
 	mu.RLock()
 	g := groups[name]
@@ -53,34 +307,589 @@ func GetGroup(name string) *Group {
 	return g
 }
 
-func (g *Group) Get(key string) (ByteView, error) {
-	if key == "" {
-		return ByteView{}, fmt.Errorf("key is required")
+// GroupNames 按名称顺序返回所有 Group 的名称
+func GroupNames() []string {
+	mu.RLock()
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
 	}
+	mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
 
-	if v, ok := g.mainCache.get(key); ok {
+// Get 从缓存中获取 key 对应的值，未命中时调用 load 加载并写入缓存
+func (g *Group) Get(ctx context.Context, key string) (ByteView, error) {
+	return g.getFunc(ctx, key)
+}
+
+// traceGet 是 WithGetMiddleware 中间件链的最内层
+func (g *Group) traceGet(ctx context.Context, key string) (ByteView, error) {
+	var span GetSpan
+	if g.tracer != nil {
+		ctx, span = g.tracer.StartGet(ctx, g.name, key)
+	}
+	v, info, err := g.get(ctx, key)
+	if span != nil {
+		span.End(info.Source, err)
+	}
+	if p, ok := ctx.Value(itemInfoKey{}).(*ItemInfo); ok {
+		*p = info
+	}
+	return v, err
+}
+
+// get 获取 key 对应的值以及值的来源
+func (g *Group) get(ctx context.Context, key string) (ByteView, ItemInfo, error) {
+	if err := g.checkKey(key); err != nil {
+		return ByteView{}, ItemInfo{}, err
+	}
+	g.stats.incr(&g.stats.gets)
+	if g.detector != nil {
+		g.detectorMu.Lock()
+		g.detector.Record(key)
+		g.detectorMu.Unlock()
+	}
+
+	if v, entry, ok := g.mainCache.getEntry(key); ok {
 		log.Println("[GeeCache] hit")
-		return v, nil
+		g.stats.incr(&g.stats.cacheHits)
+		if !entry.Expire.IsZero() && g.shouldRefresh(entry.Expire.Add(-g.staleTTL)) {
+			g.refresh(key)
+		}
+		return v, g.itemInfo(SourceCache, entry), nil
+	}
+	if v, entry, ok := g.hotCache.getEntry(key); ok {
+		g.stats.incr(&g.stats.cacheHits)
+		return v, g.itemInfo(SourceHotCache, entry), nil
+	}
+	if g.knownMissing(key) {
+		return ByteView{}, ItemInfo{}, ErrNotFound
+	}
+	if g.errCache != nil {
+		if err := g.errCache.get(key); err != nil {
+			g.stats.incr(&g.stats.suppressedLoads)
+			return ByteView{}, ItemInfo{}, err
+		}
+	}
+
+	v, source, err := g.load(ctx, key)
+	info := ItemInfo{Source: source}
+	if err == nil {
+		c := &g.mainCache
+		if source == SourcePeer {
+			c = &g.hotCache
+		}
+		if entry, ok := c.entryInfo(key); ok {
+			info = g.itemInfo(source, entry)
+		}
+	}
+	return v, info, err
+}
+
+// GetMulti 获取多个 key 对应的值，数据源中不存在的 key 不出现在结果中。
+// 先从缓存中读取，由其他节点负责的 key 按节点合并为一次请求（节点实现了 BatchPeerGetter 时），
+// 剩下未命中的 key 合并为一次 BatchGetter.GetMulti 调用，Getter 没有实现 BatchGetter 时逐个加载。
+// 批量加载与 Get 共用 singleflight、WithErrorBackoff 和 WithNegativeTTL，但不使用 TTLGetter 返回的有效期。
+func (g *Group) GetMulti(ctx context.Context, keys []string) (map[string]ByteView, error) {
+	result := make(map[string]ByteView, len(keys))
+	var misses []string
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if err := g.checkKey(key); err != nil {
+			return nil, err
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		g.stats.incr(&g.stats.gets)
+		if v, ok := g.mainCache.get(key); ok {
+			g.stats.incr(&g.stats.cacheHits)
+			result[key] = v
+			continue
+		}
+		if v, ok := g.hotCache.get(key); ok {
+			g.stats.incr(&g.stats.cacheHits)
+			result[key] = v
+			continue
+		}
+		if g.knownMissing(key) {
+			continue
+		}
+		if g.errCache != nil {
+			if err := g.errCache.get(key); err != nil {
+				g.stats.incr(&g.stats.suppressedLoads)
+				return nil, err
+			}
+		}
+		misses = append(misses, key)
+	}
+	if len(misses) == 0 {
+		return result, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if g.peers != nil {
+		// 其他节点负责的 key 向对应的节点获取，剩下的再从本地加载
+		var err error
+		if misses, err = g.getMultiFromPeers(ctx, misses, result); err != nil {
+			return nil, err
+		}
+		if len(misses) == 0 {
+			return result, nil
+		}
+	}
+	bg, ok := g.getter.(BatchGetter)
+	if !ok {
+		for _, key := range misses {
+			v, _, err := g.load(ctx, key)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result[key] = v
+		}
+		return result, nil
+	}
+
+	g.stats.add(&g.stats.loads, len(misses))
+	// 与 Get 共用 singleflight：正在加载的 key 等待已有的加载，其余的合并为一次 BatchGetter.GetMulti
+	results := g.loader.DoMulti(misses, func(keys []string) map[string]singleflight.Result {
+		return g.getMultiLocally(ctx, bg, keys)
+	})
+	for _, key := range misses {
+		res := results[key]
+		if errors.Is(res.Err, ErrNotFound) {
+			continue
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		r, _ := res.Val.(loadResult)
+		result[key] = r.value
+	}
+	return result, nil
+}
+
+// getMultiLocally 通过 BatchGetter 一次加载多个 key，与 getLocally 一样记录失败、不存在的 key 并写入 mainCache，
+// 返回每个 key 的结果。BatchGetter 不能返回每个值的有效期，这些值都使用 WithTTL 的有效期
+func (g *Group) getMultiLocally(ctx context.Context, bg BatchGetter, keys []string) map[string]singleflight.Result {
+	g.stats.add(&g.stats.loadsDeduped, len(keys))
+	results := make(map[string]singleflight.Result, len(keys))
+	fail := func(err error) map[string]singleflight.Result {
+		for _, key := range keys {
+			results[key] = singleflight.Result{Err: err}
+		}
+		return results
 	}
+	parent := ctx
+	ctx, done, err := g.startLoad(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer done()
+	writes := atomic.LoadInt64(&g.writes)
+	start := time.Now()
+	values, err := bg.GetMulti(ctx, keys)
+	g.recordLoad(time.Since(start), err)
+	if err != nil {
+		g.stats.add(&g.stats.localLoadErrs, len(keys))
+		le := &LoadError{Group: g.name, Err: err}
+		if g.errCache != nil && parent.Err() == nil {
+			for _, key := range keys {
+				g.errCache.fail(key, le)
+			}
+		}
+		return fail(le)
+	}
+	g.stats.add(&g.stats.localLoads, len(keys))
+	populate := atomic.LoadInt64(&g.writes) == writes
+	for _, key := range keys {
+		if g.errCache != nil {
+			g.errCache.reset(key)
+		}
+		bytes, ok := values[key]
+		if !ok {
+			if populate {
+				g.recordMissing(key)
+			}
+			results[key] = singleflight.Result{Err: &LoadError{Group: g.name, Key: key, Err: ErrNotFound}}
+			continue
+		}
+		v := ByteView{b: cloneBytes(bytes)}
+		if populate {
+			g.populateCache(key, v)
+		}
+		results[key] = singleflight.Result{Val: loadResult{v, SourceBackend}}
+	}
+	return results
+}
 
-	return g.load(key)
+// load 加载 key 对应的值，相同 key 的并发请求通过 singleflight 只加载一次
+func (g *Group) load(ctx context.Context, key string) (ByteView, Source, error) {
+	if err := ctx.Err(); err != nil {
+		return ByteView{}, SourceBackend, err
+	}
+	g.stats.incr(&g.stats.loads)
+	// 等待同一次加载的调用方也需要知道值的来源，所以和值一起返回
+	resi, err := g.loader.Do(key, func() (interface{}, error) {
+		g.stats.incr(&g.stats.loadsDeduped)
+		if value, ok, err := g.loadFromPeers(ctx, key); ok {
+			return loadResult{value, SourcePeer}, err
+		}
+		if g.getter == nil {
+			return loadResult{source: SourcePeer}, ErrNoPeer
+		}
+		value, err := g.getLocally(ctx, key)
+		return loadResult{value, SourceBackend}, err
+	})
+	res, _ := resi.(loadResult)
+	if err != nil {
+		return ByteView{}, res.source, err
+	}
+	return res.value, res.source, nil
 }
 
-func (g *Group) load(key string) (value ByteView, err error) {
-	return g.getLocally(key)
+// loadResult 一次加载的结果
+type loadResult struct {
+	value  ByteView
+	source Source
 }
 
 // 调用用户回调函数 g.getter.Get() 获取源数据，并且将源数据添加到缓存 mainCache 中
-func (g *Group) getLocally(key string) (ByteView, error) {
-	bytes, err := g.getter.Get(key)
+func (g *Group) getLocally(ctx context.Context, key string) (ByteView, error) {
+	parent := ctx
+	ctx, done, err := g.startLoad(ctx)
 	if err != nil {
 		return ByteView{}, err
 	}
+	defer done()
+	writes := atomic.LoadInt64(&g.writes)
+	start := time.Now()
+	ttl := g.ttl
+	var bytes []byte
+	if tg, ok := g.getter.(TTLGetter); ok {
+		var itemTTL time.Duration
+		if bytes, itemTTL, err = tg.GetWithTTL(ctx, key); itemTTL > 0 {
+			ttl = itemTTL
+		}
+	} else {
+		bytes, err = g.getter.Get(ctx, key)
+	}
+	g.recordLoad(time.Since(start), err)
+	if err != nil {
+		g.stats.incr(&g.stats.localLoadErrs)
+		if errors.Is(err, ErrNotFound) {
+			g.recordMissing(key)
+		}
+		le := &LoadError{Group: g.name, Key: key, Err: err}
+		if g.errCache != nil && parent.Err() == nil {
+			g.errCache.fail(key, le)
+		}
+		return ByteView{}, le
+	}
+	g.stats.incr(&g.stats.localLoads)
+	if g.errCache != nil {
+		g.errCache.reset(key)
+	}
 	value := ByteView{b: cloneBytes(bytes)}
-	g.populateCache(key, value)
+	if atomic.LoadInt64(&g.writes) == writes {
+		g.addToCache(&g.mainCache, key, value, ttl, g.nextVersion())
+	}
 	return value, nil
 }
 
+// Set 通过 Setter 将 value 写入数据源，成功后更新缓存，ttl <= 0 时使用 WithTTL 设置的有效期。
+// 写入数据源失败时删除缓存中的旧值并返回错误，Getter 未实现 Setter 时返回 ErrNoSetter
+// （配置了 WithWriteBehind 时实现 BatchSetter 也可以）。
+// 配置了 WithWriteBehind 时只更新缓存，数据源由后台协程异步写入。
+func (g *Group) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return g.setFunc(ctx, key, value, ttl)
+}
+
+// set 是 WithSetMiddleware 中间件链的最内层
+func (g *Group) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := g.checkKey(key); err != nil {
+		return err
+	}
+	mu := g.keyLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	_, err := g.setLocked(ctx, key, value, ttl)
+	return err
+}
+
+// setLocked 写入数据源并更新缓存，返回新值的版本，调用方需要持有 key 的 keyLock
+func (g *Group) setLocked(ctx context.Context, key string, value []byte, ttl time.Duration) (int64, error) {
+	setter, ok := g.getter.(Setter)
+	if !ok {
+		// write-behind 模式下由 BatchSetter 按批写入也可以
+		if _, batch := g.getter.(BatchSetter); !batch || g.writeBehind == nil {
+			return 0, ErrNoSetter
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	// 先让正在进行的加载放弃写入缓存，再写数据源，保证缓存中不会残留旧值
+	atomic.AddInt64(&g.writes, 1)
+	g.loader.Forget(key)
+	value = cloneBytes(value)
+	version := g.nextVersion()
+	if g.writeBehind != nil {
+		if err := g.writeBehind.enqueue(WriteEntry{Key: key, Value: value, TTL: ttl, version: version}); err != nil {
+			return 0, err
+		}
+	} else if err := setter.Set(ctx, key, value, ttl); err != nil {
+		g.mainCache.remove(key)
+		g.hotCache.remove(key)
+		return 0, err
+	}
+	g.forgetMissing(key)
+	g.hotCache.remove(key)
+	if ttl <= 0 {
+		ttl = g.ttl
+	}
+	g.addToCache(&g.mainCache, key, ByteView{b: value}, ttl, version)
+	g.invalidateDependents(key)
+	g.replicate(ctx, key, value, ttl, version)
+	return version, nil
+}
+
+// startLoad 在访问数据源之前调用：占用一个并发加载的名额，并为 ctx 加上 WithLoadTimeout 设置的超时。
+// 名额已满时返回 ErrLoadThrottled，否则加载结束后需要调用 done
+func (g *Group) startLoad(ctx context.Context) (_ context.Context, done func(), err error) {
+	release := func() {}
+	if g.loadSem != nil {
+		select {
+		case g.loadSem <- struct{}{}:
+			release = func() { <-g.loadSem }
+		default:
+			return ctx, nil, ErrLoadThrottled
+		}
+	}
+	if g.loadTimeout <= 0 {
+		return ctx, release, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.loadTimeout)
+	return ctx, func() {
+		cancel()
+		release()
+	}, nil
+}
+
+// shouldRefresh 判断有效期到 freshUntil 的值是否需要后台刷新：
+// 已经过期（处于 stale-while-revalidate 的窗口中）时一定刷新，
+// 否则按 XFetch 算法 now - delta*beta*ln(rand) >= freshUntil 概率性地提前刷新
+func (g *Group) shouldRefresh(freshUntil time.Time) bool {
+	now := time.Now()
+	if g.staleTTL > 0 && now.After(freshUntil) {
+		return true
+	}
+	if g.earlyRefreshBeta <= 0 {
+		return false
+	}
+	r := rand.Float64()
+	if r == 0 {
+		return true
+	}
+	delta := float64(atomic.LoadInt64(&g.loadDuration))
+	gap := time.Duration(-delta * g.earlyRefreshBeta * math.Log(r))
+	return !now.Add(gap).Before(freshUntil)
+}
+
+// recordLoad 记录一次从数据源加载的耗时，更新滑动平均值并通知 WithLoadObserver 注册的函数
+func (g *Group) recordLoad(d time.Duration, err error) {
+	for _, observe := range g.loadObservers {
+		observe(d, err)
+	}
+	for {
+		old := atomic.LoadInt64(&g.loadDuration)
+		avg := int64(d)
+		if old != 0 {
+			avg = old + (int64(d)-old)/8
+		}
+		if atomic.CompareAndSwapInt64(&g.loadDuration, old, avg) {
+			return
+		}
+	}
+}
+
+// refresh 在后台重新加载 key，相同 key 同时只会有一个刷新在进行
+func (g *Group) refresh(key string) {
+	g.loader.DoChan(key, func() (interface{}, error) {
+		value, err := g.getLocally(context.Background(), key)
+		return loadResult{value, SourceBackend}, err
+	})
+}
+
 func (g *Group) populateCache(key string, value ByteView) {
-	g.mainCache.add(key, value)
+	g.addToCache(&g.mainCache, key, value, g.ttl, g.nextVersion())
+}
+
+// 从其他节点加载的 key 访问频次达到该值后才放入 hotCache
+const hotKeyThreshold = 2
+
+// hotKeyCounters hotKeys 预计统计的 key 数量
+const hotKeyCounters = 10000
+
+// populateHotCache 记录一次从其他节点加载 key，访问足够频繁时放入 hotCache。
+// peerTTL 为值在负责它的节点上剩余的有效期，比 WithTTL 短时使用 peerTTL，避免比负责的节点缓存得更久；
+// version 为值在负责它的节点上的版本
+func (g *Group) populateHotCache(key string, value ByteView, peerTTL time.Duration, version int64) {
+	g.hotMu.Lock()
+	g.hotKeys.Record(key)
+	hot := g.hotKeys.Estimate(key) >= hotKeyThreshold
+	g.hotMu.Unlock()
+	if hot {
+		g.addToHotCache(key, value, peerTTL, version)
+	}
+}
+
+// addToHotCache 把其他节点负责的 key 写入 hotCache，有效期不超过 peerTTL
+func (g *Group) addToHotCache(key string, value ByteView, peerTTL time.Duration, version int64) {
+	ttl := g.ttl
+	if peerTTL > 0 && (ttl <= 0 || peerTTL < ttl) {
+		ttl = peerTTL
+	}
+	g.observeVersion(version)
+	g.addToCache(&g.hotCache, key, value, ttl, version)
+}
+
+// addToCache 写入有效期为 ttl、版本为 version 的值（超过 maxValueSize 时不写入），
+// 然后按 cacheBytes 淘汰 mainCache 与 hotCache 中的记录，hotCache 超过 mainCache 的 1/8 时优先淘汰 hotCache
+func (g *Group) addToCache(c *cache, key string, value ByteView, ttl time.Duration, version int64) {
+	if c == &g.mainCache && g.clientOnly {
+		return
+	}
+	if g.maxValueSize > 0 && value.Len() > g.maxValueSize {
+		g.stats.incr(&g.stats.oversizedValues)
+		if g.onOversize != nil {
+			g.onOversize(key, value.Len())
+		}
+		return
+	}
+	if ttl > 0 {
+		if g.ttlJitter > 0 {
+			ttl += time.Duration((rand.Float64()*2 - 1) * g.ttlJitter * float64(ttl))
+		}
+		// 过期后仍然保留 staleTTL，以便返回旧值
+		ttl += g.staleTTL
+	}
+	c.addWithVersion(key, value, ttl, version)
+	if g.cacheBytes <= 0 {
+		return
+	}
+	for {
+		mainBytes, hotBytes := g.mainCache.bytes(), g.hotCache.bytes()
+		if mainBytes+hotBytes <= g.cacheBytes {
+			return
+		}
+		victim := &g.mainCache
+		if hotBytes > mainBytes/8 {
+			victim = &g.hotCache
+		}
+		if !victim.removeOldest() {
+			return
+		}
+	}
+}
+
+// Remove 从缓存中删除 key 以及（直接或间接）依赖它的 key，正在进行的加载结果也不会再写入缓存，
+// 之后的 Get 会重新从数据源加载。数据源中的数据不受影响。
+// 配置了 WithPeers 并且节点支持时（PeerLister 和 PeerInvalidator），同时通知其他所有节点删除 key，
+// 包括负责 key 的节点和 hotCache 中保存了它的节点，通知失败不会返回错误。
+func (g *Group) Remove(ctx context.Context, key string) error {
+	if err := g.checkKey(key); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.removeLocally(key)
+	g.invalidateDependents(key)
+	g.broadcastRemove(ctx, key)
+	return nil
+}
+
+// Purge 清空本节点缓存的所有值，包括 hotCache 以及记录的不存在的 key 和加载失败的错误，
+// 正在进行的加载结果也不会再写入缓存。其他节点上的缓存不受影响。
+func (g *Group) Purge() {
+	atomic.AddInt64(&g.writes, 1)
+	g.mainCache.clear()
+	g.hotCache.clear()
+	g.negCache.clear()
+	if g.missing != nil {
+		g.missing.clear()
+	}
+	if g.errCache != nil {
+		g.errCache.clear()
+	}
+}
+
+// removeLocally 从本地的各级缓存中删除 key
+func (g *Group) removeLocally(key string) {
+	atomic.AddInt64(&g.writes, 1)
+	g.loader.Forget(key)
+	g.mainCache.remove(key)
+	g.hotCache.remove(key)
+	g.forgetMissing(key)
+	if g.errCache != nil {
+		g.errCache.reset(key)
+	}
+}
+
+// checkKey 检查 key 是否合法以及 Group 是否已经关闭
+func (g *Group) checkKey(key string) error {
+	switch {
+	case atomic.LoadInt32(&g.closed) == 1:
+		return ErrGroupClosed
+	case key == "":
+		return ErrEmptyKey
+	case g.maxKeySize > 0 && len(key) > g.maxKeySize:
+		return ErrKeyTooLarge
+	}
+	return nil
+}
+
+// Peek 只从缓存中获取 key 对应的值，不会加载，也不会触发刷新，未命中时返回 ErrCacheMiss
+func (g *Group) Peek(key string) (ByteView, error) {
+	if err := g.checkKey(key); err != nil {
+		return ByteView{}, err
+	}
+	if v, ok := g.mainCache.get(key); ok {
+		return v, nil
+	}
+	if v, ok := g.hotCache.get(key); ok {
+		return v, nil
+	}
+	return ByteView{}, ErrCacheMiss
+}
+
+// Close 停止 Group 的后台任务，之后的 Get、Set 等操作返回 ErrGroupClosed。
+// 配置了 WithWriteBehind 时等待缓冲区中的数据写入数据源
+// （WriteBehindConfig.DropOnClose 为 true 时直接丢弃），ctx 结束时提前返回 ctx.Err()。
+// 配置了 WithHintedHandoff 时尚未发送的写入被丢弃。
+func (g *Group) Close(ctx context.Context) error {
+	atomic.StoreInt32(&g.closed, 1)
+	if g.handoff != nil {
+		g.handoff.close()
+	}
+	var err error
+	if g.writeBehind != nil {
+		err = g.writeBehind.close(ctx)
+	}
+	if err == nil {
+		// 等待写入数据源完成之后再关闭缓存，写入失败时仍需删除缓存中的值
+		g.mainCache.close()
+		g.hotCache.close()
+		g.negCache.close()
+	}
+	return err
 }
@@ -1,10 +1,16 @@
 package go_cache
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var db = map[string]string{
@@ -14,12 +20,12 @@ var db = map[string]string{
 }
 
 func TestGetter(t *testing.T) {
-	var f Getter = GetterFunc(func(key string) ([]byte, error) {
+	var f Getter = GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
 		return []byte(key), nil
 	})
 
 	expect := []byte("key")
-	if v, _ := f.Get("key"); !reflect.DeepEqual(v, expect) {
+	if v, _ := f.Get(context.Background(), "key"); !reflect.DeepEqual(v, expect) {
 		t.Fatal("callback failed")
 	}
 }
@@ -29,7 +35,7 @@ func TestGetter(t *testing.T) {
 func TestGet(t *testing.T) {
 	loadCounts := make(map[string]int, len(db))
 	gee := NewGroup("scores", 2<<10, GetterFunc(
-		func(key string) ([]byte, error) {
+		func(ctx context.Context, key string) ([]byte, error) {
 			log.Println("[SlowDB] search key", key)
 			if v, ok := db[key]; ok {
 				if _, ok := loadCounts[key]; !ok {
@@ -42,15 +48,535 @@ func TestGet(t *testing.T) {
 		}))
 
 	for k, v := range db {
-		if view, err := gee.Get(k); err != nil || view.String() != v {
+		if view, err := gee.Get(context.Background(), k); err != nil || view.String() != v {
 			t.Fatal("failed to get value of Tom")
 		}
-		if _, err := gee.Get(k); err != nil || loadCounts[k] > 1 {
+		if _, err := gee.Get(context.Background(), k); err != nil || loadCounts[k] > 1 {
 			t.Fatalf("cache %s miss", k)
 		}
 	}
 
-	if view, err := gee.Get("unknown"); err == nil {
+	if view, err := gee.Get(context.Background(), "unknown"); err == nil {
 		t.Fatalf("the value of unknow should be empty, but %s got", view)
 	}
 }
+
+func TestCompression(t *testing.T) {
+	value := strings.Repeat("{\"score\":630}", 100)
+	gee := NewGroup("compressed", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(value), nil
+		}), WithCompression(64))
+
+	for i := 0; i < 2; i++ {
+		if view, err := gee.Get(context.Background(), "Tom"); err != nil || view.String() != value {
+			t.Fatalf("failed to get compressed value of Tom")
+		}
+	}
+	if gee.mainCache.lru.Stats().Bytes >= int64(len(value)) {
+		t.Fatalf("value should be stored compressed")
+	}
+}
+
+func TestGetCanceled(t *testing.T) {
+	gee := NewGroup("canceled", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := gee.Get(ctx, "Tom"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded but got %v", err)
+	}
+}
+
+func TestGetDedup(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	gee := NewGroup("dedup", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			<-release
+			return []byte(db[key]), nil
+		}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if view, err := gee.Get(context.Background(), "Tom"); err != nil || view.String() != "630" {
+				t.Errorf("failed to get value of Tom")
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if loads != 1 {
+		t.Fatalf("concurrent misses should load once but got %d", loads)
+	}
+}
+
+func TestNegativeTTL(t *testing.T) {
+	var loads int32
+	gee := NewGroup("negative", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+		}), WithNegativeTTL(20*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		if _, err := gee.Get(context.Background(), "unknown"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound but got %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("not found result should be cached, got %d loads", loads)
+	}
+	time.Sleep(30 * time.Millisecond)
+	gee.Get(context.Background(), "unknown")
+	if loads != 2 {
+		t.Fatalf("negative entry should expire, got %d loads", loads)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var loads int32
+	gee := NewGroup("stale", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			n := atomic.AddInt32(&loads, 1)
+			return []byte(fmt.Sprint(n)), nil
+		}), WithTTL(10*time.Millisecond), WithStaleWhileRevalidate(time.Second))
+
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "1" {
+		t.Fatalf("expected 1 but got %s", view)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "1" {
+		t.Fatalf("stale value should be served immediately, got %s", view)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "2" {
+		t.Fatalf("value should be refreshed in background, got %s", view)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var loads int32
+	gee := NewGroup("ttl", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return []byte(db[key]), nil
+		}), WithTTL(10*time.Millisecond))
+
+	gee.Get(context.Background(), "Tom")
+	gee.Get(context.Background(), "Tom")
+	time.Sleep(20 * time.Millisecond)
+	gee.Get(context.Background(), "Tom")
+	if loads != 2 {
+		t.Fatalf("expired value should be loaded again, got %d loads", loads)
+	}
+}
+
+func TestEarlyRefresh(t *testing.T) {
+	var loads int32
+	gee := NewGroup("early", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			time.Sleep(10 * time.Millisecond)
+			return []byte(db[key]), nil
+		}), WithTTL(30*time.Millisecond), WithEarlyRefresh(100))
+
+	gee.Get(context.Background(), "Tom")
+	for i := 0; i < 10; i++ {
+		if _, err := gee.Get(context.Background(), "Tom"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&loads) < 2 {
+		t.Fatalf("hot key should be refreshed before it expires")
+	}
+}
+
+type memStore struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (s *memStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.m[key]; ok {
+		return []byte(v), nil
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if key == "bad" {
+		return errors.New("write failed")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = string(value)
+	return nil
+}
+
+func TestSet(t *testing.T) {
+	store := &memStore{m: map[string]string{"Tom": "630"}}
+	gee := NewGroup("set", 2<<10, store, WithNegativeTTL(time.Minute))
+
+	if v, _ := gee.Get(context.Background(), "Tom"); v.String() != "630" {
+		t.Fatalf("unexpected value %q", v.String())
+	}
+	if err := gee.Set(context.Background(), "Tom", []byte("700"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if store.m["Tom"] != "700" {
+		t.Fatalf("value should be written to the backend")
+	}
+	if v, _ := gee.mainCache.get("Tom"); v.String() != "700" {
+		t.Fatalf("cache should be updated, got %q", v.String())
+	}
+
+	if _, err := gee.Get(context.Background(), "Jack"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	gee.Set(context.Background(), "Jack", []byte("589"), 0)
+	if v, err := gee.Get(context.Background(), "Jack"); err != nil || v.String() != "589" {
+		t.Fatalf("negative entry should be dropped by Set, got %q %v", v.String(), err)
+	}
+
+	if err := gee.Set(context.Background(), "bad", []byte("x"), 0); err == nil {
+		t.Fatalf("backend error should be returned")
+	}
+	if _, ok := gee.mainCache.get("bad"); ok {
+		t.Fatalf("failed write should not be cached")
+	}
+
+	readOnly := NewGroup("readonly", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	if err := readOnly.Set(context.Background(), "k", []byte("v"), 0); err != ErrNoSetter {
+		t.Fatalf("expected ErrNoSetter, got %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	store := &memStore{m: map[string]string{"Tom": "630"}}
+	gee := NewGroup("remove", 2<<10, store)
+
+	gee.Get(context.Background(), "Tom")
+	store.m["Tom"] = "700"
+	if err := gee.Remove(context.Background(), "Tom"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gee.mainCache.get("Tom"); ok {
+		t.Fatalf("key should be removed from cache")
+	}
+	if v, _ := gee.Get(context.Background(), "Tom"); v.String() != "700" {
+		t.Fatalf("removed key should be reloaded, got %q", v.String())
+	}
+	if err := gee.Remove(context.Background(), ""); err == nil {
+		t.Fatalf("empty key should be rejected")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	store := &memStore{m: map[string]string{"Tom": "630", "Jack": "589"}}
+	gee := NewGroup("purge", 2<<10, store, WithNegativeTTL(time.Minute))
+
+	gee.Get(context.Background(), "Tom")
+	gee.Get(context.Background(), "Jack")
+	gee.Get(context.Background(), "Sam")
+	gee.hotCache.add("Bob", ByteView{b: []byte("1")})
+	store.m["Sam"] = "567"
+	gee.Purge()
+	if s := gee.CacheStats(MainCache); s.Items != 0 {
+		t.Fatalf("main cache should be empty, got %d items", s.Items)
+	}
+	if _, ok := gee.hotCache.get("Bob"); ok {
+		t.Fatalf("hot cache should be empty")
+	}
+	if v, err := gee.Get(context.Background(), "Sam"); err != nil || v.String() != "567" {
+		t.Fatalf("missing keys should be forgotten, got %q %v", v.String(), err)
+	}
+}
+
+type batchGetter struct {
+	calls [][]string
+}
+
+func (b *batchGetter) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, errors.New("Get should not be called")
+}
+
+func (b *batchGetter) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	b.calls = append(b.calls, keys)
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		if v, ok := db[key]; ok {
+			values[key] = []byte(v)
+		}
+	}
+	return values, nil
+}
+
+func TestGetMulti(t *testing.T) {
+	getter := &batchGetter{}
+	gee := NewGroup("multi", 2<<10, getter, WithNegativeTTL(time.Minute))
+	gee.populateCache("Tom", ByteView{b: []byte("630")})
+
+	values, err := gee.GetMulti(context.Background(), []string{"Tom", "Jack", "Sam", "unknown", "Jack"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 || values["Jack"].String() != "589" || values["Sam"].String() != "567" {
+		t.Fatalf("unexpected values %v", values)
+	}
+	if len(getter.calls) != 1 || !reflect.DeepEqual(getter.calls[0], []string{"Jack", "Sam", "unknown"}) {
+		t.Fatalf("misses should be coalesced into one call, got %v", getter.calls)
+	}
+
+	values, _ = gee.GetMulti(context.Background(), []string{"Jack", "unknown"})
+	if len(values) != 1 || len(getter.calls) != 1 {
+		t.Fatalf("second call should be served from cache, got %v %v", values, getter.calls)
+	}
+}
+
+// blockingBatchGetter 在 release 关闭之前阻塞 GetMulti，fail 为 true 时返回错误
+type blockingBatchGetter struct {
+	calls   int32
+	release chan struct{}
+	fail    bool
+}
+
+func (b *blockingBatchGetter) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, errors.New("Get should not be called")
+}
+
+func (b *blockingBatchGetter) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	atomic.AddInt32(&b.calls, 1)
+	<-b.release
+	if b.fail {
+		return nil, errors.New("backend down")
+	}
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		values[key] = []byte("value of " + key)
+	}
+	return values, nil
+}
+
+func TestGetMultiSingleflight(t *testing.T) {
+	getter := &blockingBatchGetter{release: make(chan struct{})}
+	gee := NewGroup("multi-singleflight", 2<<10, getter)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values, err := gee.GetMulti(context.Background(), []string{"a", "b"})
+			if err != nil || values["a"].String() != "value of a" || values["b"].String() != "value of b" {
+				t.Errorf("unexpected result %v %v", values, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(getter.release)
+	wg.Wait()
+	if calls := atomic.LoadInt32(&getter.calls); calls != 1 {
+		t.Fatalf("concurrent GetMulti for the same keys should load once, got %d calls", calls)
+	}
+
+	failing := &blockingBatchGetter{release: make(chan struct{}), fail: true}
+	close(failing.release)
+	gee = NewGroup("multi-backoff", 2<<10, failing, WithErrorBackoff(Backoff{Initial: time.Minute}))
+	for i := 0; i < 2; i++ {
+		if _, err := gee.GetMulti(context.Background(), []string{"a", "b"}); err == nil {
+			t.Fatalf("failed batch load should return an error")
+		}
+	}
+	if failing.calls != 1 || gee.Stats().SuppressedLoads != 1 {
+		t.Fatalf("failure should be cached, got %d calls and %d suppressed loads", failing.calls, gee.Stats().SuppressedLoads)
+	}
+}
+
+func TestGetMultiFallback(t *testing.T) {
+	gee := NewGroup("multi-fallback", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			if v, ok := db[key]; ok {
+				return []byte(v), nil
+			}
+			return nil, ErrNotFound
+		}))
+	values, err := gee.GetMulti(context.Background(), []string{"Tom", "unknown"})
+	if err != nil || len(values) != 1 || values["Tom"].String() != "630" {
+		t.Fatalf("unexpected result %v %v", values, err)
+	}
+}
+
+func TestHotCache(t *testing.T) {
+	gee := NewGroup("hot", 144, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(db[key]), nil
+		}))
+
+	hot := ByteView{b: []byte("hot-value")}
+	gee.populateHotCache("peer-key", hot, 0, 0)
+	if _, ok := gee.hotCache.get("peer-key"); ok {
+		t.Fatalf("key seen once should not be promoted")
+	}
+	gee.populateHotCache("peer-key", hot, 0, 0)
+	if v, err := gee.Get(context.Background(), "peer-key"); err != nil || v.String() != "hot-value" {
+		t.Fatalf("frequently loaded key should be served from hotCache, got %q %v", v.String(), err)
+	}
+
+	for i := 0; i < 20; i++ {
+		gee.populateCache(fmt.Sprintf("key%02d", i), ByteView{b: []byte("value")})
+	}
+	if total := gee.mainCache.bytes() + gee.hotCache.bytes(); total > 144 {
+		t.Fatalf("both tiers should share cacheBytes, got %d", total)
+	}
+	if _, ok := gee.hotCache.get("peer-key"); ok {
+		t.Fatalf("hotCache larger than 1/8 of mainCache should be trimmed first")
+	}
+}
+
+func TestMaxValueSize(t *testing.T) {
+	var rejected []string
+	onOversize := func(key string, size int) {
+		rejected = append(rejected, key)
+	}
+	gee := NewGroup("max-value-size", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(strings.Repeat("x", len(key))), nil
+		}), WithMaxValueSize(4, onOversize))
+
+	if v, err := gee.Get(context.Background(), "large"); err != nil || v.Len() != 5 {
+		t.Fatalf("oversized value should still be returned, got %q %v", v.String(), err)
+	}
+	if _, ok := gee.mainCache.get("large"); ok {
+		t.Fatalf("oversized value should not be cached")
+	}
+	gee.Get(context.Background(), "tiny")
+	if _, ok := gee.mainCache.get("tiny"); !ok {
+		t.Fatalf("small value should be cached")
+	}
+	if !reflect.DeepEqual(rejected, []string{"large"}) || gee.Stats().OversizedValues != 1 {
+		t.Fatalf("rejection should be reported, got %v %d", rejected, gee.Stats().OversizedValues)
+	}
+}
+
+func TestLoadTimeout(t *testing.T) {
+	gee := NewGroup("load-timeout", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}), WithLoadTimeout(10*time.Millisecond))
+
+	if _, err := gee.Get(context.Background(), "Tom"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestMaxConcurrentLoads(t *testing.T) {
+	block := make(chan struct{})
+	started := make(chan struct{})
+	gee := NewGroup("max-concurrent-loads", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			started <- struct{}{}
+			<-block
+			return []byte(key), nil
+		}), WithMaxConcurrentLoads(1))
+
+	go gee.Get(context.Background(), "Tom")
+	<-started
+	if _, err := gee.Get(context.Background(), "Jack"); err != ErrLoadThrottled {
+		t.Fatalf("expected ErrLoadThrottled, got %v", err)
+	}
+	close(block)
+}
+
+type ttlGetter struct{}
+
+func (ttlGetter) Get(ctx context.Context, key string) ([]byte, error) {
+	v, _, err := ttlGetter{}.GetWithTTL(ctx, key)
+	return v, err
+}
+
+func (ttlGetter) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if key == "short" {
+		return []byte(key), time.Millisecond, nil
+	}
+	return []byte(key), 0, nil
+}
+
+func TestTTLGetter(t *testing.T) {
+	gee := NewGroup("ttl-getter", 2<<10, ttlGetter{}, WithTTL(time.Minute))
+	gee.Get(context.Background(), "short")
+	gee.Get(context.Background(), "default")
+
+	_, expire, _ := gee.mainCache.getWithExpire("default")
+	if d := time.Until(expire); d < 59*time.Second {
+		t.Fatalf("zero TTL should fall back to the group TTL, got %v", d)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := gee.mainCache.get("short"); ok {
+		t.Fatalf("value should expire after the TTL returned by the getter")
+	}
+}
+
+func TestTTLJitter(t *testing.T) {
+	gee := NewGroup("ttl-jitter", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}), WithTTL(time.Hour), WithTTLJitter(0.1))
+
+	expires := make(map[time.Duration]bool)
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		gee.Get(context.Background(), key)
+		_, expire, _ := gee.mainCache.getWithExpire(key)
+		d := expire.Sub(start)
+		if d < 54*time.Minute || d > 66*time.Minute+time.Second {
+			t.Fatalf("expiration %v out of the jitter range", d)
+		}
+		expires[d.Truncate(time.Second)] = true
+	}
+	if len(expires) < 10 {
+		t.Fatalf("expirations should be spread out, got %d distinct values", len(expires))
+	}
+}
+
+func TestHottestKeys(t *testing.T) {
+	var hot []string
+	onHot := func(key string, qps float64) {
+		hot = append(hot, key)
+	}
+	gee := NewGroup("hottest-keys", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}), WithHotKeyDetection(2, 50, onHot))
+
+	for i := 0; i < 100; i++ {
+		gee.Get(context.Background(), "Tom")
+		if i%10 == 0 {
+			gee.Get(context.Background(), "Jack")
+		}
+	}
+	gee.Get(context.Background(), "Sam")
+	top := gee.HottestKeys(1)
+	if len(top) != 1 || top[0].Key != "Tom" {
+		t.Fatalf("unexpected hottest keys %v", top)
+	}
+	if !reflect.DeepEqual(hot, []string{"Tom"}) {
+		t.Fatalf("only Tom should cross the threshold, got %v", hot)
+	}
+}
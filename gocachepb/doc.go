@@ -0,0 +1,4 @@
+// Package gocachepb 节点之间通信使用的 protobuf 消息，HTTP 与 gRPC 传输共用
+package gocachepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative -I .. ../gocachepb/gocache.proto
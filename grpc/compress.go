@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/golang/snappy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	// 注册 gzip 压缩
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// 响应支持的压缩算法，客户端会通过 grpc-accept-encoding 声明它能解压的全部算法
+const (
+	CompressorGzip   = "gzip"
+	CompressorSnappy = "snappy"
+)
+
+func init() {
+	encoding.RegisterCompressor(snappyCompressor{})
+}
+
+// snappyCompressor 实现 encoding.Compressor，使用 snappy 的流格式
+type snappyCompressor struct{}
+
+func (snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
+
+func (snappyCompressor) Name() string {
+	return CompressorSnappy
+}
+
+// WithCompression 值超过 minSize 字节时压缩响应，算法按 names 的顺序
+// 从客户端支持的算法中选择，默认优先使用更快的 snappy，其次是压缩率更高的 gzip
+func WithCompression(minSize int, names ...string) ServerOption {
+	return func(s *Server) {
+		if len(names) == 0 {
+			names = []string{CompressorSnappy, CompressorGzip}
+		}
+		s.compressMinSize = minSize
+		s.compressors = names
+	}
+}
+
+// setCompressor 值足够大时为响应选择压缩算法，size 为值的字节数，需要在发送第一条消息之前调用
+func (s *Server) setCompressor(ctx context.Context, size int) {
+	if len(s.compressors) == 0 || size <= s.compressMinSize {
+		return
+	}
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return
+	}
+	for _, name := range s.compressors {
+		for _, c := range supported {
+			if c == name {
+				grpc.SetSendCompressor(ctx, name)
+				return
+			}
+		}
+	}
+}
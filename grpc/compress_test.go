@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	gocache "go-cache"
+	"go-cache/gocachepb"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// payloadRecorder 记录服务端发送的最后一条消息压缩前后的大小
+type payloadRecorder struct {
+	mu                       sync.Mutex
+	length, compressedLength int
+}
+
+func (r *payloadRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+func (r *payloadRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+func (r *payloadRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *payloadRecorder) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if p, ok := s.(*stats.OutPayload); ok {
+		r.mu.Lock()
+		r.length, r.compressedLength = p.Length, p.CompressedLength
+		r.mu.Unlock()
+	}
+}
+
+func (r *payloadRecorder) compressed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.compressedLength < r.length
+}
+
+func TestCompression(t *testing.T) {
+	gocache.NewGroup("grpc-compress", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(strings.Repeat(key, 100)), nil
+		}))
+
+	for _, name := range []string{CompressorSnappy, CompressorGzip} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := &payloadRecorder{}
+		s := grpc.NewServer(grpc.StatsHandler(rec))
+		NewServer(WithCompression(200, name)).Register(s)
+		go s.Serve(lis)
+
+		pool := NewPool("self")
+		if err := pool.Set(lis.Addr().String()); err != nil {
+			t.Fatal(err)
+		}
+		peer, _ := pool.PickPeer("Tom")
+		// 100 字节的值不超过 minSize，不压缩
+		for _, tt := range []struct {
+			key        string
+			compressed bool
+		}{{"a", false}, {"Tom", true}} {
+			res := &gocachepb.GetResponse{}
+			if err := peer.Get(context.Background(), &gocachepb.GetRequest{Group: "grpc-compress", Key: tt.key}, res); err != nil {
+				t.Fatal(err)
+			}
+			if string(res.GetValue()) != strings.Repeat(tt.key, 100) {
+				t.Fatalf("%s: value should be decompressed, got %q", name, res.GetValue())
+			}
+			if rec.compressed() != tt.compressed {
+				t.Fatalf("%s: %s compressed should be %v", name, tt.key, tt.compressed)
+			}
+		}
+		pool.Close()
+		s.Stop()
+	}
+}
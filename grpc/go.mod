@@ -0,0 +1,21 @@
+module go-cache/grpc
+
+go 1.20
+
+require (
+	github.com/golang/snappy v0.0.4
+	go-cache v0.0.0
+	google.golang.org/grpc v1.59.0
+	google.golang.org/protobuf v1.32.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.14.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+	golang.org/x/text v0.12.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230822172742-b8732ec3820d // indirect
+)
+
+replace go-cache => ../
@@ -0,0 +1,684 @@
+// Package grpc 基于 gRPC 的节点通信，与 go-cache/http 的 HTTPPool 作用相同，
+// 但请求和响应使用 protobuf 编码，二进制的 key 和较大的值也能高效传输。
+// 它是一个独立的 module，只有使用 gRPC 的程序才会引入 gRPC 依赖：
+//
+//	pool := grpc.NewPool("10.0.0.1:8008")
+//	pool.Set("10.0.0.1:8008", "10.0.0.2:8008")
+//	s := grpclib.NewServer()
+//	grpc.NewServer().Register(s)
+//	g := gocache.NewGroup("scores", 2<<10, getter, gocache.WithPeers(pool))
+//
+// 在不可信的网络中运行时，服务端通过 grpclib.Creds(credentials.NewTLS(cfg)) 启用 TLS，
+// cfg.ClientAuth 设置为 tls.RequireAndVerifyClientCert 时要求客户端证书，Pool 通过 WithTLS 提供证书；
+// 或者通过 WithServerToken 和 WithToken 使用共享的令牌认证每个请求。
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	gocache "go-cache"
+	"go-cache/consistenthash"
+	"go-cache/gocachepb"
+	"go-cache/grpc/peerpb"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultReplicas 一致性哈希中每个节点默认的虚拟节点数量
+const DefaultReplicas = 50
+
+// DefaultChunkSize GetStream 每条消息携带的最大字节数
+const DefaultChunkSize = 64 << 10
+
+// Server 实现 peerpb.GroupCacheServer，为其他节点提供本节点的缓存。
+// codes.NotFound 只表示数据源中没有 key，本节点没有请求的 Group 时返回 codes.FailedPrecondition，
+// 使请求方继续尝试其他副本或从本地加载
+type Server struct {
+	peerpb.UnimplementedGroupCacheServer
+	chunkSize int
+	// 节点之间共享的令牌，为空表示不认证
+	token string
+	// 值超过 compressMinSize 字节时按 compressors 的顺序协商压缩算法，compressors 为空表示不压缩
+	compressMinSize int
+	compressors     []string
+}
+
+// ServerOption 构造 Server 时的可选配置
+type ServerOption func(*Server)
+
+// WithChunkSize 设置 GetStream 每条消息携带的最大字节数，默认为 DefaultChunkSize
+func WithChunkSize(n int) ServerOption {
+	return func(s *Server) {
+		s.chunkSize = n
+	}
+}
+
+// WithServerToken 拒绝没有带上令牌 token 的请求（codes.Unauthenticated），
+// 客户端通过 WithToken 设置相同的令牌
+func WithServerToken(token string) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// NewServer 实例化 Server
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{chunkSize: DefaultChunkSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register 把 Server 注册到 gRPC 服务上
+func (s *Server) Register(gs *grpc.Server) {
+	peerpb.RegisterGroupCacheServer(gs, s)
+}
+
+// Get 实现 peerpb.GroupCacheServer，调用方的 deadline 随 ctx 传递给 Group.Get
+func (s *Server) Get(ctx context.Context, req *gocachepb.GetRequest) (*gocachepb.GetResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	res, err := s.get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.setCompressor(ctx, len(res.GetValue()))
+	return res, nil
+}
+
+// GetStream 实现 peerpb.GroupCacheServer，把值分成不超过 chunkSize 字节的多段返回，
+// ttl、flags 和 version 只在第一段中
+func (s *Server) GetStream(req *gocachepb.GetRequest, stream peerpb.GroupCache_GetStreamServer) error {
+	if err := s.authorize(stream.Context()); err != nil {
+		return err
+	}
+	res, err := s.get(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	s.setCompressor(stream.Context(), len(res.GetValue()))
+	b := res.Value
+	for {
+		n := len(b)
+		if n > s.chunkSize {
+			n = s.chunkSize
+		}
+		res.Value = b[:n]
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+		if b = b[n:]; len(b) == 0 {
+			return nil
+		}
+		res = &gocachepb.GetResponse{}
+	}
+}
+
+// Set 实现 peerpb.GroupCacheServer，把其他副本同步过来的新值写入本节点的缓存
+func (s *Server) Set(ctx context.Context, req *gocachepb.SetRequest) (*gocachepb.SetResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	group := gocache.GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "no such group: %s", req.GetGroup())
+	}
+	if err := group.ServePeerSet(ctx, req); err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+	return &gocachepb.SetResponse{}, nil
+}
+
+// Invalidate 实现 peerpb.GroupCacheServer，从本节点的缓存中删除其他节点 Group.Remove 的 key
+func (s *Server) Invalidate(ctx context.Context, req *gocachepb.InvalidateRequest) (*gocachepb.InvalidateResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	group := gocache.GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "no such group: %s", req.GetGroup())
+	}
+	if err := group.ServePeerInvalidate(ctx, req); err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+	return &gocachepb.InvalidateResponse{}, nil
+}
+
+// GetMulti 实现 peerpb.GroupCacheServer，一次返回多个 key 的值
+func (s *Server) GetMulti(ctx context.Context, req *gocachepb.GetMultiRequest) (*gocachepb.GetMultiResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	group := gocache.GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "no such group: %s", req.GetGroup())
+	}
+	res := &gocachepb.GetMultiResponse{}
+	if err := group.ServePeerMulti(ctx, req, res); err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+	size := 0
+	for _, v := range res.GetValues() {
+		size += len(v.GetValue())
+	}
+	s.setCompressor(ctx, size)
+	return res, nil
+}
+
+// Ping 实现 peerpb.GroupCacheServer，用于 Pool.CheckHealth 检查本节点是否健康
+func (s *Server) Ping(ctx context.Context, req *peerpb.PingRequest) (*peerpb.PingResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return &peerpb.PingResponse{}, nil
+}
+
+// authorize 检查请求的 metadata 中是否带有 WithServerToken 设置的令牌
+func (s *Server) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(v, "Bearer "); ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid token")
+}
+
+func (s *Server) get(ctx context.Context, req *gocachepb.GetRequest) (*gocachepb.GetResponse, error) {
+	group := gocache.GetGroup(req.GetGroup())
+	if group == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "no such group: %s", req.GetGroup())
+	}
+	res := &gocachepb.GetResponse{}
+	if err := group.ServePeer(ctx, req.GetKey(), res); err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+	return res, nil
+}
+
+// codeOf 把 Group.Get 的错误转换为 gRPC 状态码
+func codeOf(err error) codes.Code {
+	switch {
+	case errors.Is(err, gocache.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, gocache.ErrEmptyKey), errors.Is(err, gocache.ErrKeyTooLarge):
+		return codes.InvalidArgument
+	case errors.Is(err, gocache.ErrLoadThrottled), errors.Is(err, gocache.ErrGroupClosed):
+		return codes.Unavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	default:
+		return codes.Internal
+	}
+}
+
+// Pool 实现 gocache.PeerPicker，根据一致性哈希选择节点，并为每个节点复用一个 gRPC 连接
+type Pool struct {
+	// 本节点的地址，例如 "10.0.0.2:8008"
+	self     string
+	replicas int
+	hash     consistenthash.Hash
+	// 配置了 WithClientOnly 时本节点不加入一致性哈希环
+	clientOnly bool
+	// 构造选择节点的 Picker，默认为一致性哈希环
+	newPicker   func() consistenthash.Picker
+	dialOptions []grpc.DialOption
+	// 每个 key 保存的副本数
+	replication int
+
+	mu sync.Mutex
+	// 全部节点（包括本节点），按 Set 传入的顺序
+	members []string
+	// 根据 key 选择节点的一致性哈希环，不包括被健康检查移出的节点
+	peers consistenthash.Picker
+	// 健康检查判定为不健康、暂时移出一致性哈希环的节点
+	ejected map[string]bool
+	// SetWeights 设置的节点权重，不在其中的节点权重为 1
+	weights map[string]int
+	// 每个节点对应的客户端，key 为节点地址
+	getters map[string]*grpcGetter
+}
+
+var (
+	_ gocache.PeerPicker    = (*Pool)(nil)
+	_ gocache.ReplicaPicker = (*Pool)(nil)
+	_ gocache.PeerLister    = (*Pool)(nil)
+)
+
+// Option 构造 Pool 时的可选配置
+type Option func(*Pool)
+
+// WithReplicas 设置一致性哈希中每个节点的虚拟节点数量，默认为 DefaultReplicas
+func WithReplicas(n int) Option {
+	return func(p *Pool) {
+		p.replicas = n
+	}
+}
+
+// WithHash 设置选择节点使用的哈希函数，默认为 consistenthash.XXHash，所有节点需要使用相同的哈希函数。
+// 从使用 crc32 的旧版本滚动升级时可以先传入 consistenthash.CRC32
+func WithHash(fn consistenthash.Hash) Option {
+	return func(p *Pool) {
+		p.hash = fn
+	}
+}
+
+// WithRendezvousHashing 使用 Rendezvous（HRW）哈希代替一致性哈希环选择节点，节点较少时分布更均匀，
+// 此时 WithReplicas 不生效。所有节点需要使用相同的配置
+func WithRendezvousHashing() Option {
+	return func(p *Pool) {
+		p.newPicker = func() consistenthash.Picker { return consistenthash.NewRendezvous(p.hash) }
+	}
+}
+
+// WithJumpHashing 使用跳跃一致性哈希选择节点，查找时不分配内存，比一致性哈希环快得多，此时 WithReplicas 不生效。
+// 节点按 Set 传入的顺序编号，所有节点需要以相同的顺序调用 Set，新的节点应当添加在末尾，
+// 删除或者健康检查移出中间的节点会使它之后的节点负责的 key 大量迁移，因此只适合成员稳定的集群。
+// 节点列表来自注册中心时应当使用 registry.OnChangeAppend 而不是会排序的 registry.OnChange
+func WithJumpHashing() Option {
+	return func(p *Pool) {
+		p.newPicker = func() consistenthash.Picker { return consistenthash.NewJump(p.hash) }
+	}
+}
+
+// WithClientOnly 本节点只作为集群的客户端，不加入选择节点的一致性哈希环，所有 key 都由其他节点负责，
+// 通常与 gocache.WithClientOnly 一起使用。Set 传入的节点列表可以包括也可以不包括本节点，
+// 其他节点的节点列表中不应当包括本节点
+func WithClientOnly() Option {
+	return func(p *Pool) {
+		p.clientOnly = true
+	}
+}
+
+// WithReplication 每个 key 保存在一致性哈希环上顺时针方向的 n 个节点上，默认为 1
+func WithReplication(n int) Option {
+	return func(p *Pool) {
+		p.replication = n
+	}
+}
+
+// WithDialOptions 追加连接其他节点时使用的 grpc.DialOption，默认不加密，
+// 可以通过 grpc.WithTransportCredentials 替换
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(p *Pool) {
+		p.dialOptions = append(p.dialOptions, opts...)
+	}
+}
+
+// WithTLS 使用 TLS 连接其他节点，代替默认的不加密连接，
+// 例如通过 cfg.Certificates 提供客户端证书、cfg.RootCAs 指定信任的 CA
+func WithTLS(cfg *tls.Config) Option {
+	return func(p *Pool) {
+		p.dialOptions = append(p.dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	}
+}
+
+// WithToken 每个请求通过 metadata 带上令牌 token，对方节点通过 WithServerToken 校验。
+// 不加密的连接中令牌可能被窃听，应当同时使用 WithTLS
+func WithToken(token string) Option {
+	return func(p *Pool) {
+		p.dialOptions = append(p.dialOptions, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+}
+
+// tokenCredentials 实现 credentials.PerRPCCredentials
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// NewPool 实例化本节点的 Pool，self 为本节点的地址
+func NewPool(self string, opts ...Option) *Pool {
+	p := &Pool{
+		self:        self,
+		replicas:    DefaultReplicas,
+		replication: 1,
+		dialOptions: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.newPicker == nil {
+		p.newPicker = func() consistenthash.Picker { return consistenthash.New(p.replicas, p.hash) }
+	}
+	return p
+}
+
+// Set 设置集群中的全部节点（包括本节点），可以在运行期间反复调用。
+// 已经存在的节点继续使用原来的连接，被移除的节点的连接等正在进行的请求结束后再关闭
+func (p *Pool) Set(peers ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	getters := make(map[string]*grpcGetter, len(peers))
+	for _, peer := range peers {
+		if peer == p.self {
+			continue
+		}
+		if g, ok := p.getters[peer]; ok {
+			getters[peer] = g
+			continue
+		}
+		conn, err := grpc.Dial(peer, p.dialOptions...)
+		if err != nil {
+			for peer, g := range getters {
+				if _, ok := p.getters[peer]; !ok {
+					g.conn.Close()
+				}
+			}
+			return fmt.Errorf("dial %s: %v", peer, err)
+		}
+		getters[peer] = &grpcGetter{conn: conn, client: peerpb.NewGroupCacheClient(conn)}
+	}
+	for peer, g := range p.getters {
+		if _, ok := getters[peer]; !ok {
+			go g.drain()
+		}
+	}
+	members := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		members[peer] = true
+	}
+	for peer := range p.ejected {
+		if !members[peer] {
+			delete(p.ejected, peer)
+		}
+	}
+	p.members = append([]string(nil), peers...)
+	p.getters = getters
+	p.rebuild()
+	return nil
+}
+
+// rebuild 用健康的节点重建一致性哈希环，调用方需要持有 p.mu
+func (p *Pool) rebuild() {
+	m := p.newPicker()
+	wp, weighted := m.(consistenthash.WeightedPicker)
+	for _, peer := range p.members {
+		if p.ejected[peer] || (p.clientOnly && peer == p.self) {
+			continue
+		}
+		if w, ok := p.weights[peer]; ok && weighted {
+			wp.AddWeighted(peer, w)
+		} else {
+			m.Add(peer)
+		}
+	}
+	p.peers = m
+}
+
+// SetWeights 设置节点的权重，节点负责的 key 与权重大致成正比，例如按内存大小设置权重，
+// 使内存较大的节点负责更多的 key。不在 weights 中的节点权重为 1，WithJumpHashing 时不生效。
+// 与 Set 一样，所有节点需要使用相同的权重
+func (p *Pool) SetWeights(weights map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.weights = make(map[string]int, len(weights))
+	for peer, w := range weights {
+		p.weights[peer] = w
+	}
+	p.rebuild()
+}
+
+// Peers 返回当前的全部节点，包括被健康检查移出一致性哈希环的节点
+func (p *Pool) Peers() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.members...)
+}
+
+// Ejected 按地址顺序返回被健康检查移出一致性哈希环的节点
+func (p *Pool) Ejected() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]string, 0, len(p.ejected))
+	for peer := range p.ejected {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// Owner 返回负责 key 的节点地址，没有调用过 Set 时返回空字符串
+func (p *Pool) Owner(key string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return ""
+	}
+	return p.peers.Get(key)
+}
+
+// PickPeer 实现 gocache.PeerPicker
+func (p *Pool) PickPeer(key string) (gocache.PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		return p.getters[peer], true
+	}
+	return nil, false
+}
+
+// PickReplicas 实现 gocache.ReplicaPicker，按一致性哈希的顺序返回负责 key 的其他副本
+func (p *Pool) PickReplicas(key string) ([]gocache.PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	var peers []gocache.PeerGetter
+	self := false
+	for _, peer := range p.peers.GetN(key, p.replication) {
+		if peer == p.self {
+			self = true
+			continue
+		}
+		peers = append(peers, p.getters[peer])
+	}
+	return peers, self
+}
+
+// ListPeers 实现 gocache.PeerLister，按地址顺序返回除本节点和不健康的节点之外的全部节点
+func (p *Pool) ListPeers() []gocache.PeerGetter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addrs := make([]string, 0, len(p.getters))
+	for addr := range p.getters {
+		if !p.ejected[addr] {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+	peers := make([]gocache.PeerGetter, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = p.getters[addr]
+	}
+	return peers
+}
+
+// Close 关闭所有连接
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var err error
+	for _, g := range p.getters {
+		if e := g.conn.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	p.members = nil
+	p.peers = nil
+	p.ejected = nil
+	p.getters = nil
+	return err
+}
+
+// grpcGetter 通过 gRPC 从其他节点获取缓存值，实现了 gocache.PeerGetter
+type grpcGetter struct {
+	conn   *grpc.ClientConn
+	client peerpb.GroupCacheClient
+
+	mu sync.Mutex
+	// 正在进行的请求数
+	inflight int
+	// 调用过 drain 之后为 true，不再接受新的请求
+	draining bool
+	// drain 等待请求结束时不为 nil，最后一个请求结束时关闭
+	idle chan struct{}
+}
+
+// errPeerRemoved 节点已经被 Set 移除，PickPeer 之后才开始的请求不再发送
+var errPeerRemoved = errors.New("peer has been removed")
+
+// begin 记录一个开始的请求，与 drain 在同一把锁内检查 draining，节点已被移除时返回 errPeerRemoved
+func (g *grpcGetter) begin() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.draining {
+		return errPeerRemoved
+	}
+	g.inflight++
+	return nil
+}
+
+// end 记录一个结束的请求
+func (g *grpcGetter) end() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.inflight--; g.inflight == 0 && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+}
+
+// drain 拒绝新的请求，等待正在进行的请求全部结束，然后关闭连接
+func (g *grpcGetter) drain() {
+	g.mu.Lock()
+	g.draining = true
+	if g.inflight > 0 {
+		if g.idle == nil {
+			g.idle = make(chan struct{})
+		}
+		idle := g.idle
+		g.mu.Unlock()
+		<-idle
+	} else {
+		g.mu.Unlock()
+	}
+	g.conn.Close()
+}
+
+var (
+	_ gocache.PeerGetter      = (*grpcGetter)(nil)
+	_ gocache.PeerSetter      = (*grpcGetter)(nil)
+	_ gocache.BatchPeerGetter = (*grpcGetter)(nil)
+	_ gocache.PeerInvalidator = (*grpcGetter)(nil)
+)
+
+// Get 通过 GetStream 获取值，ctx 的 deadline 会传递给对方节点，
+// 节点返回 codes.NotFound 时返回 gocache.ErrNotFound
+func (g *grpcGetter) Get(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+	if err := g.begin(); err != nil {
+		return err
+	}
+	defer g.end()
+	stream, err := g.client.GetStream(ctx, in)
+	if err != nil {
+		return fromStatus(err)
+	}
+	for first := true; ; first = false {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fromStatus(err)
+		}
+		if first {
+			out.Value = res.Value
+			out.Ttl = res.Ttl
+			out.Flags = res.Flags
+			out.Version = res.Version
+			continue
+		}
+		out.Value = append(out.Value, res.GetValue()...)
+	}
+}
+
+// Set 把新值写入对方节点的缓存
+func (g *grpcGetter) Set(ctx context.Context, in *gocachepb.SetRequest) error {
+	if err := g.begin(); err != nil {
+		return err
+	}
+	defer g.end()
+	if _, err := g.client.Set(ctx, in); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// Invalidate 从该节点的缓存中删除 key
+func (g *grpcGetter) Invalidate(ctx context.Context, in *gocachepb.InvalidateRequest) error {
+	if err := g.begin(); err != nil {
+		return err
+	}
+	defer g.end()
+	if _, err := g.client.Invalidate(ctx, in); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// GetMulti 通过一元调用一次获取多个 key，结果受 gRPC 单条消息大小的限制
+func (g *grpcGetter) GetMulti(ctx context.Context, in *gocachepb.GetMultiRequest, out *gocachepb.GetMultiResponse) error {
+	if err := g.begin(); err != nil {
+		return err
+	}
+	defer g.end()
+	res, err := g.client.GetMulti(ctx, in)
+	if err != nil {
+		return fromStatus(err)
+	}
+	out.Values = res.Values
+	out.Failed = res.Failed
+	return nil
+}
+
+// fromStatus 把 gRPC 状态码转换回 gocache 的错误
+func fromStatus(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return gocache.ErrNotFound
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	case codes.Canceled:
+		return context.Canceled
+	}
+	return err
+}
@@ -0,0 +1,365 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	gocache "go-cache"
+	"go-cache/consistenthash"
+	"go-cache/gocachepb"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+func startServer(t *testing.T, opts ...ServerOption) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	NewServer(opts...).Register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestPool(t *testing.T) {
+	gocache.NewGroup("grpc", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			switch key {
+			case "unknown":
+				return nil, gocache.ErrNotFound
+			case "slow":
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return []byte(strings.Repeat(key, 10)), nil
+		}), gocache.WithTTL(time.Minute))
+	addr := startServer(t, WithChunkSize(7))
+
+	pool := NewPool("self")
+	defer pool.Close()
+	if err := pool.Set(addr, "self"); err != nil {
+		t.Fatal(err)
+	}
+	var peer gocache.PeerGetter
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		if p, ok := pool.PickPeer(key); ok {
+			peer = p
+			break
+		}
+	}
+	if peer == nil {
+		t.Fatalf("some keys should be owned by the other peer")
+	}
+
+	get := func(ctx context.Context, group, key string) (*gocachepb.GetResponse, error) {
+		res := &gocachepb.GetResponse{}
+		err := peer.Get(ctx, &gocachepb.GetRequest{Group: group, Key: key}, res)
+		return res, err
+	}
+	res, err := get(context.Background(), "grpc", "Tom")
+	if err != nil || string(res.GetValue()) != strings.Repeat("Tom", 10) {
+		t.Fatalf("value should be reassembled from chunks, got %v %v", res, err)
+	}
+	if ttl := res.GetTtl().AsDuration(); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL should travel with the first chunk, got %v", ttl)
+	}
+	if _, err := get(context.Background(), "grpc", "unknown"); !errors.Is(err, gocache.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := get(context.Background(), "nosuchgroup", "Tom"); err == nil || errors.Is(err, gocache.ErrNotFound) {
+		t.Fatalf("unknown group should not map to ErrNotFound, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := get(ctx, "grpc", "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("deadline should be propagated, got %v", err)
+	}
+}
+
+func TestPoolReusesConnections(t *testing.T) {
+	pool := NewPool("self")
+	defer pool.Close()
+	pool.Set("self", "127.0.0.1:1", "127.0.0.1:2")
+	conn := pool.getters["127.0.0.1:1"].conn
+	pool.Set("self", "127.0.0.1:1")
+	if pool.getters["127.0.0.1:1"].conn != conn {
+		t.Fatalf("connection to an existing peer should be reused")
+	}
+	if _, ok := pool.getters["127.0.0.1:2"]; ok {
+		t.Fatalf("removed peer should be dropped")
+	}
+}
+
+func TestRendezvousHashing(t *testing.T) {
+	peers := []string{"self", "127.0.0.1:1", "127.0.0.1:2"}
+	pool := NewPool("self", WithRendezvousHashing())
+	defer pool.Close()
+	pool.Set(peers...)
+	r := consistenthash.NewRendezvous(nil)
+	r.Add(peers...)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		if owner := pool.Owner(key); owner != r.Get(key) {
+			t.Fatalf("%s: expected owner %s, got %s", key, r.Get(key), owner)
+		}
+	}
+}
+
+func TestJumpHashing(t *testing.T) {
+	peers := []string{"self", "127.0.0.1:1", "127.0.0.1:2"}
+	pool := NewPool("self", WithJumpHashing())
+	defer pool.Close()
+	pool.Set(peers...)
+	j := consistenthash.NewJump(nil)
+	j.Add(peers...)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		if owner := pool.Owner(key); owner != j.Get(key) {
+			t.Fatalf("%s: expected owner %s, got %s", key, j.Get(key), owner)
+		}
+	}
+}
+
+func TestSetWeights(t *testing.T) {
+	pool := NewPool("self")
+	defer pool.Close()
+	pool.Set("self", "127.0.0.1:1")
+	pool.SetWeights(map[string]int{"127.0.0.1:1": 3})
+	n := 0
+	for i := 0; i < 4000; i++ {
+		if pool.Owner(fmt.Sprint("key", i)) == "127.0.0.1:1" {
+			n++
+		}
+	}
+	if share := float64(n) / 4000; share < 0.65 || share > 0.85 {
+		t.Fatalf("peer with weight 3 owns %.2f of the keys, expected about 0.75", share)
+	}
+}
+
+func TestClientOnly(t *testing.T) {
+	pool := NewPool("self", WithClientOnly())
+	defer pool.Close()
+	pool.Set("self", "127.0.0.1:1", "127.0.0.1:2")
+	for i := 0; i < 100; i++ {
+		if owner := pool.Owner(fmt.Sprint("key", i)); owner == "self" {
+			t.Fatalf("client-only node should not own keys")
+		}
+	}
+}
+
+func TestUnaryGet(t *testing.T) {
+	gocache.NewGroup("grpc-unary", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	res, err := NewServer().Get(context.Background(), &gocachepb.GetRequest{Group: "grpc-unary", Key: "Tom"})
+	if err != nil || string(res.GetValue()) != "Tom" {
+		t.Fatalf("unexpected response %v %v", res, err)
+	}
+}
+
+func TestSetDrainsRemovedPeers(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	gocache.NewGroup("grpc-dynamic", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			close(started)
+			<-release
+			return []byte(key), nil
+		}))
+	addr := startServer(t)
+
+	pool := NewPool("self")
+	defer pool.Close()
+	pool.Set("self", addr)
+	var key string
+	for i := 0; ; i++ {
+		if key = fmt.Sprint("key", i); pool.peers.Get(key) == addr {
+			break
+		}
+	}
+	peer, _ := pool.PickPeer(key)
+
+	done := make(chan error)
+	go func() {
+		done <- peer.Get(context.Background(), &gocachepb.GetRequest{Group: "grpc-dynamic", Key: key}, &gocachepb.GetResponse{})
+	}()
+	<-started
+	pool.Set("self")
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("in-flight request should not be interrupted, got %v", err)
+	}
+
+	// 移除之后才开始的请求不再发送给该节点
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := peer.Get(context.Background(), &gocachepb.GetRequest{Group: "grpc-dynamic", Key: key}, &gocachepb.GetResponse{})
+		if errors.Is(err, errPeerRemoved) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("request to a drained peer should be rejected, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplicas(t *testing.T) {
+	gee := gocache.NewGroup("grpc-replicas", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("old"), nil
+		}))
+	addr := startServer(t)
+
+	pool := NewPool("self", WithReplication(2))
+	defer pool.Close()
+	if err := pool.Set(addr, "self"); err != nil {
+		t.Fatal(err)
+	}
+	peers, self := pool.PickReplicas("Tom")
+	if !self || len(peers) != 1 {
+		t.Fatalf("with 2 nodes and 2 replicas every node should hold every key, got %d %v", len(peers), self)
+	}
+	err := peers[0].(gocache.PeerSetter).Set(context.Background(),
+		&gocachepb.SetRequest{Group: "grpc-replicas", Key: "Tom", Value: []byte("new")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "new" {
+		t.Fatalf("Set should update the peer's cache, got %s", view)
+	}
+}
+
+func TestToken(t *testing.T) {
+	gocache.NewGroup("grpc-token", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	addr := startServer(t, WithServerToken("secret"))
+
+	get := func(opts ...Option) error {
+		pool := NewPool("self", opts...)
+		defer pool.Close()
+		if err := pool.Set(addr); err != nil {
+			t.Fatal(err)
+		}
+		peer, _ := pool.PickPeer("Tom")
+		return peer.Get(context.Background(), &gocachepb.GetRequest{Group: "grpc-token", Key: "Tom"}, &gocachepb.GetResponse{})
+	}
+	for _, opts := range [][]Option{nil, {WithToken("wrong")}} {
+		if err := get(opts...); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated but got %v", err)
+		}
+	}
+	if err := get(WithToken("secret")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	gocache.NewGroup("grpc-mtls", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	cert, roots := newCert(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    roots,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})))
+	NewServer().Register(s)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	get := func(cfg *tls.Config) error {
+		pool := NewPool("self", WithTLS(cfg))
+		defer pool.Close()
+		if err := pool.Set(lis.Addr().String()); err != nil {
+			t.Fatal(err)
+		}
+		peer, _ := pool.PickPeer("Tom")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		return peer.Get(ctx, &gocachepb.GetRequest{Group: "grpc-mtls", Key: "Tom"}, &gocachepb.GetResponse{})
+	}
+	if err := get(&tls.Config{RootCAs: roots}); err == nil {
+		t.Fatalf("request without a client certificate should fail")
+	}
+	if err := get(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newCert 生成一个自签名的证书，同时可以用作服务端和客户端证书
+func newCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gocache"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+func TestInvalidate(t *testing.T) {
+	gee := gocache.NewGroup("grpc-invalidate", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	addr := startServer(t)
+	pool := NewPool("self")
+	defer pool.Close()
+	if err := pool.Set("self", addr); err != nil {
+		t.Fatal(err)
+	}
+
+	gee.Get(context.Background(), "Tom")
+	err := pool.getters[addr].Invalidate(context.Background(), &gocachepb.InvalidateRequest{Group: "grpc-invalidate", Key: "Tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gee.Peek("Tom"); err == nil {
+		t.Fatalf("Invalidate should remove the key")
+	}
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"go-cache/grpc/peerpb"
+	"go-cache/healthcheck"
+	"log"
+	"sort"
+)
+
+// CheckHealth 定期调用其他节点的 Ping，把不健康的节点暂时移出一致性哈希环，
+// 它负责的 key 由环上的下一个节点接管，节点恢复之后再加回来。阻塞直到 ctx 结束时返回 ctx.Err()：
+//
+//	go pool.CheckHealth(ctx, healthcheck.Config{})
+func (p *Pool) CheckHealth(ctx context.Context, cfg healthcheck.Config) error {
+	return healthcheck.Run(ctx, cfg, p.otherPeers, p.ping, p.setHealthy)
+}
+
+// otherPeers 返回除本节点之外的全部节点
+func (p *Pool) otherPeers() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]string, 0, len(p.getters))
+	for peer := range p.getters {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// ping 调用 peer 的 Ping
+func (p *Pool) ping(ctx context.Context, peer string) error {
+	p.mu.Lock()
+	g, ok := p.getters[peer]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if g.begin() != nil {
+		return nil
+	}
+	defer g.end()
+	_, err := g.client.Ping(ctx, &peerpb.PingRequest{})
+	return err
+}
+
+// setHealthy 把 peer 移出或者加回一致性哈希环
+func (p *Pool) setHealthy(peer string, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.getters[peer]; !ok || p.ejected[peer] == !healthy {
+		return
+	}
+	if healthy {
+		log.Printf("[Server %s] Restore peer %s", p.self, peer)
+		delete(p.ejected, peer)
+	} else {
+		log.Printf("[Server %s] Eject unhealthy peer %s", p.self, peer)
+		if p.ejected == nil {
+			p.ejected = make(map[string]bool)
+		}
+		p.ejected[peer] = true
+	}
+	p.rebuild()
+}
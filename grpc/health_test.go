@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"go-cache/grpc/peerpb"
+	"go-cache/healthcheck"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestCheckHealth(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	s := grpc.NewServer()
+	NewServer().Register(s)
+	go s.Serve(lis)
+
+	pool := NewPool("self")
+	defer pool.Close()
+	if err := pool.Set("self", addr); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.CheckHealth(ctx, healthcheck.Config{Interval: 2 * time.Millisecond, Timeout: 100 * time.Millisecond, FailThreshold: 2, PassThreshold: 2})
+
+	owns := func() bool {
+		for i := 0; i < 100; i++ {
+			if _, ok := pool.PickPeer(fmt.Sprint("key", i)); ok {
+				return true
+			}
+		}
+		return false
+	}
+	waitFor := func(want bool) {
+		deadline := time.Now().Add(2 * time.Second)
+		for owns() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("peer should own keys: %v", want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitFor(true)
+	s.Stop()
+	waitFor(false)
+	if len(pool.ListPeers()) != 0 {
+		t.Fatalf("ejected peer should not be listed")
+	}
+
+	lis, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = grpc.NewServer()
+	NewServer().Register(s)
+	go s.Serve(lis)
+	defer s.Stop()
+	waitFor(true)
+}
+
+func TestPing(t *testing.T) {
+	addr := startServer(t, WithServerToken("secret"))
+	pool := NewPool("self")
+	defer pool.Close()
+	if err := pool.Set("self", addr); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.ping(context.Background(), addr); err == nil {
+		t.Fatalf("ping without the token should fail")
+	}
+
+	pool = NewPool("self", WithToken("secret"))
+	defer pool.Close()
+	if err := pool.Set("self", addr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.getters[addr].client.Ping(context.Background(), &peerpb.PingRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
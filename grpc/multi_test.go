@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	gocache "go-cache"
+	"go-cache/gocachepb"
+	"testing"
+)
+
+func TestGetMulti(t *testing.T) {
+	gocache.NewGroup("grpc-multi", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			if key == "missing" {
+				return nil, gocache.ErrNotFound
+			}
+			return []byte(key), nil
+		}))
+	addr := startServer(t)
+	pool := NewPool("self")
+	defer pool.Close()
+	if err := pool.Set("self", addr); err != nil {
+		t.Fatal(err)
+	}
+
+	res := &gocachepb.GetMultiResponse{}
+	err := pool.getters[addr].GetMulti(context.Background(), &gocachepb.GetMultiRequest{Group: "grpc-multi", Keys: []string{"a", "missing", "b"}}, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetValues()) != 2 || string(res.GetValues()["b"].GetValue()) != "b" {
+		t.Fatalf("unexpected response %v", res)
+	}
+	err = pool.getters[addr].GetMulti(context.Background(), &gocachepb.GetMultiRequest{Group: "nosuchgroup", Keys: []string{"a"}}, res)
+	if err == nil {
+		t.Fatalf("request to an unknown group should fail")
+	}
+}
@@ -0,0 +1,4 @@
+// Package peerpb gRPC 节点通信服务的定义，消息类型来自 go-cache/gocachepb
+package peerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative -I .. -I ../.. ../peerpb/peer.proto
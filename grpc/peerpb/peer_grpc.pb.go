@@ -0,0 +1,337 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: peerpb/peer.proto
+
+// 基于 gRPC 的节点通信服务
+
+package peerpb
+
+import (
+	context "context"
+	gocachepb "go-cache/gocachepb"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	GroupCache_Get_FullMethodName        = "/peerpb.GroupCache/Get"
+	GroupCache_GetStream_FullMethodName  = "/peerpb.GroupCache/GetStream"
+	GroupCache_Set_FullMethodName        = "/peerpb.GroupCache/Set"
+	GroupCache_Invalidate_FullMethodName = "/peerpb.GroupCache/Invalidate"
+	GroupCache_GetMulti_FullMethodName   = "/peerpb.GroupCache/GetMulti"
+	GroupCache_Ping_FullMethodName       = "/peerpb.GroupCache/Ping"
+)
+
+// GroupCacheClient is the client API for GroupCache service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GroupCacheClient interface {
+	// Get 一次返回完整的值
+	Get(ctx context.Context, in *gocachepb.GetRequest, opts ...grpc.CallOption) (*gocachepb.GetResponse, error)
+	// GetStream 把值分成多段返回，适合超过单条消息大小限制的值
+	GetStream(ctx context.Context, in *gocachepb.GetRequest, opts ...grpc.CallOption) (GroupCache_GetStreamClient, error)
+	// Set 把值写入本节点的缓存，用于同步副本
+	Set(ctx context.Context, in *gocachepb.SetRequest, opts ...grpc.CallOption) (*gocachepb.SetResponse, error)
+	// Invalidate 从本节点的缓存中删除 key，用于 Group.Remove 通知所有节点
+	Invalidate(ctx context.Context, in *gocachepb.InvalidateRequest, opts ...grpc.CallOption) (*gocachepb.InvalidateResponse, error)
+	// GetMulti 一次获取多个 key，允许部分成功
+	GetMulti(ctx context.Context, in *gocachepb.GetMultiRequest, opts ...grpc.CallOption) (*gocachepb.GetMultiResponse, error)
+	// Ping 健康检查，节点能够处理请求时返回成功
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+}
+
+type groupCacheClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGroupCacheClient(cc grpc.ClientConnInterface) GroupCacheClient {
+	return &groupCacheClient{cc}
+}
+
+func (c *groupCacheClient) Get(ctx context.Context, in *gocachepb.GetRequest, opts ...grpc.CallOption) (*gocachepb.GetResponse, error) {
+	out := new(gocachepb.GetResponse)
+	err := c.cc.Invoke(ctx, GroupCache_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *groupCacheClient) GetStream(ctx context.Context, in *gocachepb.GetRequest, opts ...grpc.CallOption) (GroupCache_GetStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GroupCache_ServiceDesc.Streams[0], GroupCache_GetStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &groupCacheGetStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GroupCache_GetStreamClient interface {
+	Recv() (*gocachepb.GetResponse, error)
+	grpc.ClientStream
+}
+
+type groupCacheGetStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *groupCacheGetStreamClient) Recv() (*gocachepb.GetResponse, error) {
+	m := new(gocachepb.GetResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *groupCacheClient) Set(ctx context.Context, in *gocachepb.SetRequest, opts ...grpc.CallOption) (*gocachepb.SetResponse, error) {
+	out := new(gocachepb.SetResponse)
+	err := c.cc.Invoke(ctx, GroupCache_Set_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *groupCacheClient) Invalidate(ctx context.Context, in *gocachepb.InvalidateRequest, opts ...grpc.CallOption) (*gocachepb.InvalidateResponse, error) {
+	out := new(gocachepb.InvalidateResponse)
+	err := c.cc.Invoke(ctx, GroupCache_Invalidate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *groupCacheClient) GetMulti(ctx context.Context, in *gocachepb.GetMultiRequest, opts ...grpc.CallOption) (*gocachepb.GetMultiResponse, error) {
+	out := new(gocachepb.GetMultiResponse)
+	err := c.cc.Invoke(ctx, GroupCache_GetMulti_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *groupCacheClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, GroupCache_Ping_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GroupCacheServer is the server API for GroupCache service.
+// All implementations must embed UnimplementedGroupCacheServer
+// for forward compatibility
+type GroupCacheServer interface {
+	// Get 一次返回完整的值
+	Get(context.Context, *gocachepb.GetRequest) (*gocachepb.GetResponse, error)
+	// GetStream 把值分成多段返回，适合超过单条消息大小限制的值
+	GetStream(*gocachepb.GetRequest, GroupCache_GetStreamServer) error
+	// Set 把值写入本节点的缓存，用于同步副本
+	Set(context.Context, *gocachepb.SetRequest) (*gocachepb.SetResponse, error)
+	// Invalidate 从本节点的缓存中删除 key，用于 Group.Remove 通知所有节点
+	Invalidate(context.Context, *gocachepb.InvalidateRequest) (*gocachepb.InvalidateResponse, error)
+	// GetMulti 一次获取多个 key，允许部分成功
+	GetMulti(context.Context, *gocachepb.GetMultiRequest) (*gocachepb.GetMultiResponse, error)
+	// Ping 健康检查，节点能够处理请求时返回成功
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	mustEmbedUnimplementedGroupCacheServer()
+}
+
+// UnimplementedGroupCacheServer must be embedded to have forward compatible implementations.
+type UnimplementedGroupCacheServer struct {
+}
+
+func (UnimplementedGroupCacheServer) Get(context.Context, *gocachepb.GetRequest) (*gocachepb.GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedGroupCacheServer) GetStream(*gocachepb.GetRequest, GroupCache_GetStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetStream not implemented")
+}
+func (UnimplementedGroupCacheServer) Set(context.Context, *gocachepb.SetRequest) (*gocachepb.SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Set not implemented")
+}
+func (UnimplementedGroupCacheServer) Invalidate(context.Context, *gocachepb.InvalidateRequest) (*gocachepb.InvalidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Invalidate not implemented")
+}
+func (UnimplementedGroupCacheServer) GetMulti(context.Context, *gocachepb.GetMultiRequest) (*gocachepb.GetMultiResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMulti not implemented")
+}
+func (UnimplementedGroupCacheServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedGroupCacheServer) mustEmbedUnimplementedGroupCacheServer() {}
+
+// UnsafeGroupCacheServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GroupCacheServer will
+// result in compilation errors.
+type UnsafeGroupCacheServer interface {
+	mustEmbedUnimplementedGroupCacheServer()
+}
+
+func RegisterGroupCacheServer(s grpc.ServiceRegistrar, srv GroupCacheServer) {
+	s.RegisterService(&GroupCache_ServiceDesc, srv)
+}
+
+func _GroupCache_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(gocachepb.GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupCacheServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupCache_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupCacheServer).Get(ctx, req.(*gocachepb.GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GroupCache_GetStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(gocachepb.GetRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GroupCacheServer).GetStream(m, &groupCacheGetStreamServer{stream})
+}
+
+type GroupCache_GetStreamServer interface {
+	Send(*gocachepb.GetResponse) error
+	grpc.ServerStream
+}
+
+type groupCacheGetStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *groupCacheGetStreamServer) Send(m *gocachepb.GetResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GroupCache_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(gocachepb.SetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupCacheServer).Set(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupCache_Set_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupCacheServer).Set(ctx, req.(*gocachepb.SetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GroupCache_Invalidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(gocachepb.InvalidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupCacheServer).Invalidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupCache_Invalidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupCacheServer).Invalidate(ctx, req.(*gocachepb.InvalidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GroupCache_GetMulti_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(gocachepb.GetMultiRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupCacheServer).GetMulti(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupCache_GetMulti_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupCacheServer).GetMulti(ctx, req.(*gocachepb.GetMultiRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GroupCache_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GroupCacheServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GroupCache_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GroupCacheServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GroupCache_ServiceDesc is the grpc.ServiceDesc for GroupCache service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GroupCache_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "peerpb.GroupCache",
+	HandlerType: (*GroupCacheServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _GroupCache_Get_Handler,
+		},
+		{
+			MethodName: "Set",
+			Handler:    _GroupCache_Set_Handler,
+		},
+		{
+			MethodName: "Invalidate",
+			Handler:    _GroupCache_Invalidate_Handler,
+		},
+		{
+			MethodName: "GetMulti",
+			Handler:    _GroupCache_GetMulti_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _GroupCache_Ping_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetStream",
+			Handler:       _GroupCache_GetStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "peerpb/peer.proto",
+}
@@ -0,0 +1,214 @@
+package go_cache
+
+import (
+	"context"
+	"go-cache/gocachepb"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// HintedHandoffConfig hinted handoff 的配置，零值字段使用默认值
+type HintedHandoffConfig struct {
+	// 暂存的写入在多长时间之后丢弃，默认 10 分钟，超过之后副本只能等值过期后更新
+	TTL time.Duration
+	// 最多暂存的写入条数，超过时丢弃新的写入，默认 10000
+	MaxHints int
+	// 重新发送暂存的写入的间隔，默认 1s
+	RetryInterval time.Duration
+}
+
+// WithHintedHandoff 向其他副本同步 Group.Set 写入的新值或者通知其他节点 Group.Remove 删除的 key 失败时
+// （例如对方节点重启），把这次写入暂存在本节点，定期重新发送直到成功或超过 cfg.TTL，
+// 使副本在短暂的故障和滚动重启之后仍然保持一致。同一个节点上相同 key 的写入和删除只保留最后一次，
+// 节点按 PeerGetter 区分，因此 PeerGetter 需要能够作为 map 的 key（例如指针）。
+// 使用完毕后需要调用 Group.Close 停止后台协程。
+func WithHintedHandoff(cfg HintedHandoffConfig) GroupOption {
+	return func(g *Group) {
+		g.handoff = newHandoff(cfg)
+	}
+}
+
+// handoffTimeout 重新发送一条写入的最长时间
+const handoffTimeout = 5 * time.Second
+
+// hint 一条等待重新发送的写入或删除，set 和 invalidate 只有一个不为 nil
+type hint struct {
+	set        *gocachepb.SetRequest
+	invalidate *gocachepb.InvalidateRequest
+	added      time.Time
+}
+
+// handoff 暂存发送失败的写入，并在后台定期重新发送
+type handoff struct {
+	cfg HintedHandoffConfig
+
+	mu sync.Mutex
+	// 每个节点上等待重新发送的写入，key 为写入或删除的 key
+	hints map[PeerGetter]map[string]hint
+	n     int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newHandoff(cfg HintedHandoffConfig) *handoff {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Minute
+	}
+	if cfg.MaxHints <= 0 {
+		cfg.MaxHints = 10000
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = time.Second
+	}
+	h := &handoff{
+		cfg:   cfg,
+		hints: make(map[PeerGetter]map[string]hint),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// add 暂存一条发往 peer 的写入，替换同一个 key 之前暂存的写入或删除，peer 需要实现 PeerSetter
+func (h *handoff) add(peer PeerGetter, req *gocachepb.SetRequest) {
+	h.addHint(peer, req.GetKey(), hint{set: req, added: time.Now()})
+}
+
+// addInvalidation 暂存一条发往 peer 的删除，替换同一个 key 之前暂存的写入或删除，
+// peer 需要实现 PeerInvalidator
+func (h *handoff) addInvalidation(peer PeerGetter, req *gocachepb.InvalidateRequest) {
+	h.addHint(peer, req.GetKey(), hint{invalidate: req, added: time.Now()})
+}
+
+func (h *handoff) addHint(peer PeerGetter, key string, hi hint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hints := h.hints[peer]
+	if hints == nil {
+		hints = make(map[string]hint)
+		h.hints[peer] = hints
+	}
+	if _, ok := hints[key]; !ok {
+		if h.n >= h.cfg.MaxHints {
+			log.Println("[GeeCache] Too many hints, dropping write of", key)
+			return
+		}
+		h.n++
+	}
+	hints[key] = hi
+}
+
+// pending 返回暂存的写入条数
+func (h *handoff) pending() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.n
+}
+
+func (h *handoff) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.cfg.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.replay()
+		}
+	}
+}
+
+// replay 向每个节点重新发送暂存的写入，某个节点发送失败时说明它仍然不可用，跳过它剩下的写入
+func (h *handoff) replay() {
+	h.mu.Lock()
+	peers := make([]PeerGetter, 0, len(h.hints))
+	for peer := range h.hints {
+		peers = append(peers, peer)
+	}
+	h.mu.Unlock()
+
+	for _, peer := range peers {
+		for _, key := range h.keys(peer) {
+			h.mu.Lock()
+			hi, ok := h.hints[peer][key]
+			h.mu.Unlock()
+			if !ok {
+				continue
+			}
+			if time.Since(hi.added) > h.cfg.TTL {
+				h.remove(peer, key, hi)
+				continue
+			}
+			var err error
+			ctx, cancel := context.WithTimeout(context.Background(), handoffTimeout)
+			if hi.invalidate != nil {
+				err = peer.(PeerInvalidator).Invalidate(ctx, hi.invalidate)
+			} else if req, ok := hi.replayRequest(); ok {
+				err = peer.(PeerSetter).Set(ctx, req)
+			}
+			cancel()
+			if err != nil {
+				break
+			}
+			h.remove(peer, key, hi)
+		}
+	}
+}
+
+// replayRequest 返回重新发送的写入，值的有效期扣除暂存的时间，值已经过期时 ok 为 false
+func (hi hint) replayRequest() (req *gocachepb.SetRequest, ok bool) {
+	if hi.set.GetTtl() == nil {
+		return hi.set, true
+	}
+	remaining := hi.set.GetTtl().AsDuration() - time.Since(hi.added)
+	if remaining <= 0 {
+		return nil, false
+	}
+	return &gocachepb.SetRequest{
+		Group:   hi.set.GetGroup(),
+		Key:     hi.set.GetKey(),
+		Value:   hi.set.GetValue(),
+		Ttl:     durationpb.New(remaining),
+		Hot:     hi.set.GetHot(),
+		Version: hi.set.GetVersion(),
+	}, true
+}
+
+// keys 返回 peer 上暂存的写入的 key
+func (h *handoff) keys(peer PeerGetter) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, 0, len(h.hints[peer]))
+	for key := range h.hints[peer] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// remove 删除已经发送或者过期的写入，发送期间同一个 key 又有新的写入时保留新的写入
+func (h *handoff) remove(peer PeerGetter, key string, sent hint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hints := h.hints[peer]
+	if hi, ok := hints[key]; !ok || hi != sent {
+		return
+	}
+	delete(hints, key)
+	h.n--
+	if len(hints) == 0 {
+		delete(h.hints, peer)
+	}
+}
+
+// close 停止后台协程，暂存的写入被丢弃
+func (h *handoff) close() {
+	h.closeOnce.Do(func() { close(h.stop) })
+	<-h.done
+}
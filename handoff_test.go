@@ -0,0 +1,94 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyPeer 在 down 为 true 时拒绝写入
+type flakyPeer struct {
+	fakePeer
+	mu    sync.Mutex
+	down  bool
+	value string
+	ttl   time.Duration
+}
+
+func (p *flakyPeer) Set(ctx context.Context, in *gocachepb.SetRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errors.New("connection refused")
+	}
+	p.value = string(in.GetValue())
+	p.ttl = in.GetTtl().AsDuration()
+	return nil
+}
+
+func (p *flakyPeer) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHintedHandoff(t *testing.T) {
+	peer := &flakyPeer{down: true}
+	gee := NewGroup("handoff", 2<<10, &memStore{m: make(map[string]string)},
+		WithPeers(replicaList{peer}),
+		WithHintedHandoff(HintedHandoffConfig{RetryInterval: 5 * time.Millisecond}))
+	defer gee.Close(context.Background())
+
+	gee.Set(context.Background(), "Tom", []byte("v1"), time.Minute)
+	gee.Set(context.Background(), "Tom", []byte("v2"), time.Minute)
+	if n := gee.handoff.pending(); n != 1 {
+		t.Fatalf("writes of the same key should be merged, got %d hints", n)
+	}
+
+	peer.setDown(false)
+	waitFor(t, func() bool { return gee.handoff.pending() == 0 })
+	peer.mu.Lock()
+	defer peer.mu.Unlock()
+	if peer.value != "v2" {
+		t.Fatalf("the last write should be replayed, got %s", peer.value)
+	}
+	if peer.ttl <= 0 || peer.ttl >= time.Minute {
+		t.Fatalf("replayed TTL should exclude the time spent queued, got %v", peer.ttl)
+	}
+}
+
+func TestHintedHandoffExpire(t *testing.T) {
+	peer := &flakyPeer{down: true}
+	gee := NewGroup("handoff-expire", 2<<10, &memStore{m: make(map[string]string)},
+		WithPeers(replicaList{peer}),
+		WithHintedHandoff(HintedHandoffConfig{TTL: 20 * time.Millisecond, MaxHints: 1, RetryInterval: 5 * time.Millisecond}))
+	defer gee.Close(context.Background())
+
+	gee.Set(context.Background(), "Tom", []byte("v1"), 0)
+	gee.Set(context.Background(), "Jack", []byte("v1"), 0)
+	if n := gee.handoff.pending(); n != 1 {
+		t.Fatalf("hints beyond MaxHints should be dropped, got %d", n)
+	}
+	waitFor(t, func() bool { return gee.handoff.pending() == 0 })
+	peer.setDown(false)
+	time.Sleep(20 * time.Millisecond)
+	peer.mu.Lock()
+	defer peer.mu.Unlock()
+	if peer.value != "" {
+		t.Fatalf("expired hints should not be replayed, got %s", peer.value)
+	}
+}
@@ -0,0 +1,104 @@
+// Package healthcheck 定期检查节点是否健康，供 HTTPPool 和 gRPC 的 Pool 把不健康的节点
+// 暂时移出一致性哈希环，恢复之后再加回来：
+//
+//	go pool.CheckHealth(ctx, healthcheck.Config{Interval: 5 * time.Second})
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Config 健康检查的配置，零值字段使用默认值
+type Config struct {
+	// 检查的间隔，默认 5s
+	Interval time.Duration
+	// 每次检查的超时时间，默认 1s
+	Timeout time.Duration
+	// 连续失败 FailThreshold 次之后判定为不健康，默认 3
+	FailThreshold int
+	// 不健康的节点连续成功 PassThreshold 次之后恢复，默认 2。
+	// 两个阈值使节点在健康边缘时不会频繁地进出一致性哈希环
+	PassThreshold int
+}
+
+func (c *Config) setDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = 5 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = time.Second
+	}
+	if c.FailThreshold <= 0 {
+		c.FailThreshold = 3
+	}
+	if c.PassThreshold <= 0 {
+		c.PassThreshold = 2
+	}
+}
+
+// status 一个节点的健康状态
+type status struct {
+	healthy bool
+	// 与当前状态相反的连续检查结果的次数
+	streak int
+}
+
+// Run 每隔 cfg.Interval 并发地对 peers 返回的每个节点调用 ping，
+// 节点在健康与不健康之间变化时调用 onChange。新出现的节点视为健康，
+// 不再出现在 peers 中的节点不再检查。Run 直到 ctx 结束时返回 ctx.Err()
+func Run(ctx context.Context, cfg Config, peers func() []string,
+	ping func(ctx context.Context, peer string) error, onChange func(peer string, healthy bool)) error {
+	cfg.setDefaults()
+	statuses := make(map[string]*status)
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		list := peers()
+		results := make([]error, len(list))
+		var wg sync.WaitGroup
+		for i, peer := range list {
+			wg.Add(1)
+			go func(i int, peer string) {
+				defer wg.Done()
+				pctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+				defer cancel()
+				results[i] = ping(pctx, peer)
+			}(i, peer)
+		}
+		wg.Wait()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		current := make(map[string]*status, len(list))
+		for i, peer := range list {
+			s, ok := statuses[peer]
+			if !ok {
+				s = &status{healthy: true}
+			}
+			current[peer] = s
+			if (results[i] == nil) == s.healthy {
+				s.streak = 0
+				continue
+			}
+			s.streak++
+			threshold := cfg.FailThreshold
+			if !s.healthy {
+				threshold = cfg.PassThreshold
+			}
+			if s.streak >= threshold {
+				s.healthy, s.streak = !s.healthy, 0
+				onChange(peer, s.healthy)
+			}
+		}
+		statuses = current
+	}
+}
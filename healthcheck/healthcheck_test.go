@@ -0,0 +1,89 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	// 每个节点依次返回的结果，用完之后保持最后一个
+	results := map[string][]bool{
+		"a": {false, true, false, false, false, true, true},
+		"b": {true},
+	}
+	var changes []string
+	ticks := 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	peers := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		if ticks++; ticks > 8 {
+			cancel()
+		}
+		return []string{"a", "b"}
+	}
+	ping := func(ctx context.Context, peer string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		r := results[peer]
+		ok := r[0]
+		if len(r) > 1 {
+			results[peer] = r[1:]
+		}
+		if !ok {
+			return errors.New("down")
+		}
+		return nil
+	}
+	onChange := func(peer string, healthy bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if healthy {
+			changes = append(changes, peer+" up")
+		} else {
+			changes = append(changes, peer+" down")
+		}
+	}
+	err := Run(ctx, Config{Interval: time.Millisecond, FailThreshold: 3, PassThreshold: 2}, peers, ping, onChange)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run should return ctx.Err(), got %v", err)
+	}
+	want := []string{"a down", "a up"}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestRunForgetsRemovedPeers(t *testing.T) {
+	var mu sync.Mutex
+	list := []string{"a"}
+	var changes int
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ticks := 0
+	peers := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		// 节点失败一次之后被移除，再加入时重新计数
+		if ticks++; ticks%2 == 0 {
+			return nil
+		}
+		return list
+	}
+	ping := func(ctx context.Context, peer string) error { return errors.New("down") }
+	onChange := func(peer string, healthy bool) {
+		mu.Lock()
+		changes++
+		mu.Unlock()
+	}
+	Run(ctx, Config{Interval: time.Millisecond, FailThreshold: 2}, peers, ping, onChange)
+	if changes != 0 {
+		t.Fatalf("removed peers should start over, got %d changes", changes)
+	}
+}
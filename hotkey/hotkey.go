@@ -0,0 +1,192 @@
+package hotkey
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+const depth = 4
+
+// KeyStat 一个热点 key 及其每秒访问次数
+type KeyStat struct {
+	Key string
+	QPS float64
+}
+
+// Detector 热点 key 探测器，使用 count-min sketch 近似统计每个 key 在一个时间窗口内的访问次数，
+// 并用一个小顶堆维护访问次数最多的 k 个 key。每个窗口结束时计数清零，
+// 上一个窗口的结果由 Top 返回。Detector 不是并发安全的。
+type Detector struct {
+	rows  [depth][]uint32
+	width uint64
+	k     int
+	top   topHeap
+	// 当前窗口的开始时间和长度
+	start  time.Time
+	window time.Duration
+	// 上一个完整窗口的热点 key
+	last []KeyStat
+	// 每秒访问次数超过 threshold 时调用 onHot，每个 key 每个窗口最多调用一次
+	threshold float64
+	onHot     func(key string, qps float64)
+	reported  map[string]bool
+}
+
+// New 实例化 Detector，统计最热的 k 个 key，counters 是预计每个窗口内访问的不同 key 的数量，
+// window 为统计的时间窗口。threshold > 0 时，key 的每秒访问次数超过 threshold 会调用 onHot
+func New(k, counters int, window time.Duration, threshold float64, onHot func(key string, qps float64)) *Detector {
+	width := uint64(1)
+	for width < uint64(counters) {
+		width <<= 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	d := &Detector{
+		width:     width,
+		k:         k,
+		top:       topHeap{index: make(map[string]int)},
+		start:     time.Now(),
+		window:    window,
+		threshold: threshold,
+		onHot:     onHot,
+		reported:  make(map[string]bool),
+	}
+	for i := range d.rows {
+		d.rows[i] = make([]uint32, width)
+	}
+	return d
+}
+
+// Record 记录一次 key 的访问
+func (d *Detector) Record(key string) {
+	now := time.Now()
+	if now.Sub(d.start) >= d.window {
+		d.roll(now)
+	}
+
+	h1, h2 := hash(key)
+	count := ^uint32(0)
+	for i := range d.rows {
+		idx := (h1 + uint64(i)*h2) & (d.width - 1)
+		d.rows[i][idx]++
+		if d.rows[i][idx] < count {
+			count = d.rows[i][idx]
+		}
+	}
+	d.top.offer(key, count, d.k)
+
+	if d.threshold > 0 && d.onHot != nil && !d.reported[key] {
+		if qps := float64(count) / d.window.Seconds(); qps >= d.threshold {
+			d.reported[key] = true
+			d.onHot(key, qps)
+		}
+	}
+}
+
+// roll 结束当前窗口：保存热点 key 并清空计数
+func (d *Detector) roll(now time.Time) {
+	d.last = d.snapshot(d.window)
+	for i := range d.rows {
+		for j := range d.rows[i] {
+			d.rows[i][j] = 0
+		}
+	}
+	d.top = topHeap{index: make(map[string]int)}
+	d.reported = make(map[string]bool)
+	d.start = now
+}
+
+// snapshot 按访问次数从高到低返回当前窗口的热点 key
+func (d *Detector) snapshot(elapsed time.Duration) []KeyStat {
+	stats := make([]KeyStat, len(d.top.items))
+	for i, it := range d.top.items {
+		stats[i] = KeyStat{Key: it.key, QPS: float64(it.count) / elapsed.Seconds()}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].QPS != stats[j].QPS {
+			return stats[i].QPS > stats[j].QPS
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
+
+// Top 返回最热的 n 个 key。上一个窗口已经结束时返回上一个窗口的结果，
+// 否则（启动后的第一个窗口）返回当前窗口到目前为止的结果
+func (d *Detector) Top(n int) []KeyStat {
+	now := time.Now()
+	if now.Sub(d.start) >= d.window {
+		d.roll(now)
+	}
+	stats := d.last
+	if stats == nil {
+		elapsed := now.Sub(d.start)
+		if elapsed <= 0 {
+			elapsed = time.Nanosecond
+		}
+		stats = d.snapshot(elapsed)
+	}
+	if n < len(stats) {
+		stats = stats[:n]
+	}
+	return append([]KeyStat(nil), stats...)
+}
+
+func hash(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	return sum, (sum >> 32) | 1
+}
+
+type topItem struct {
+	key   string
+	count uint32
+}
+
+// topHeap 按访问次数排序的小顶堆，index 记录 key 在堆中的下标
+type topHeap struct {
+	items []topItem
+	index map[string]int
+}
+
+func (h topHeap) Len() int           { return len(h.items) }
+func (h topHeap) Less(i, j int) bool { return h.items[i].count < h.items[j].count }
+func (h topHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].key] = i
+	h.index[h.items[j].key] = j
+}
+
+func (h *topHeap) Push(x any) {
+	it := x.(topItem)
+	h.index[it.key] = len(h.items)
+	h.items = append(h.items, it)
+}
+
+func (h *topHeap) Pop() any {
+	it := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, it.key)
+	return it
+}
+
+// offer 更新 key 的访问次数，堆中不足 k 个或 key 比堆顶更热时放入堆中
+func (h *topHeap) offer(key string, count uint32, k int) {
+	if i, ok := h.index[key]; ok {
+		h.items[i].count = count
+		heap.Fix(h, i)
+		return
+	}
+	if len(h.items) < k {
+		heap.Push(h, topItem{key: key, count: count})
+		return
+	}
+	if k > 0 && count > h.items[0].count {
+		heap.Pop(h)
+		heap.Push(h, topItem{key: key, count: count})
+	}
+}
@@ -0,0 +1,52 @@
+package hotkey
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTop(t *testing.T) {
+	d := New(3, 1024, time.Hour, 0, nil)
+	for i := 0; i < 100; i++ {
+		d.Record(fmt.Sprintf("cold%d", i))
+	}
+	for i := 0; i < 50; i++ {
+		d.Record("hot1")
+		if i%2 == 0 {
+			d.Record("hot2")
+		}
+		if i%5 == 0 {
+			d.Record("hot3")
+		}
+	}
+
+	top := d.Top(2)
+	if len(top) != 2 || top[0].Key != "hot1" || top[1].Key != "hot2" {
+		t.Fatalf("unexpected top keys %v", top)
+	}
+	if top := d.Top(10); len(top) != 3 || top[2].Key != "hot3" {
+		t.Fatalf("at most k keys should be tracked, got %v", top)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	var hot []string
+	d := New(2, 1024, 20*time.Millisecond, 100, func(key string, qps float64) {
+		hot = append(hot, key)
+	})
+	for i := 0; i < 10; i++ {
+		d.Record("hot")
+	}
+	d.Record("cold")
+	if len(hot) != 1 || hot[0] != "hot" {
+		t.Fatalf("key above the QPS threshold should be reported once, got %v", hot)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	d.Record("next")
+	top := d.Top(10)
+	if len(top) != 2 || top[0].Key != "hot" || top[0].QPS != 500 {
+		t.Fatalf("Top should report the last complete window, got %v", top)
+	}
+}
@@ -0,0 +1,91 @@
+package go_cache
+
+import (
+	"context"
+	"go-cache/gocachepb"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// PeerLister 可选接口，PeerPicker 同时实现 PeerLister 时才能使用 WithHotKeyReplication
+type PeerLister interface {
+	// ListPeers 返回除本节点之外的全部节点，每次调用的顺序应当相同
+	ListPeers() []PeerGetter
+}
+
+// WithHotKeyReplication 本节点负责的 key 被 WithHotKeyDetection 判定为热点时，
+// 把它的值主动写入 k 个其他节点的 hotCache（k <= 0 时写入全部节点），
+// 这些节点之后直接返回热点 key，请求不再全部汇集到负责它的节点上。
+// 需要同时配置 threshold > 0 的 WithHotKeyDetection，并且 WithPeers 的 PeerPicker 实现了 PeerLister、
+// 各节点的 PeerGetter 实现了 PeerSetter。和 hotCache 一样，其他节点上的副本在过期之前可能是旧值。
+func WithHotKeyReplication(k int) GroupOption {
+	return func(g *Group) {
+		if k <= 0 {
+			k = -1
+		}
+		g.hotReplicas = k
+	}
+}
+
+// onHotKey Detector 发现热点 key 时调用，在 Get 的协程中持有 detectorMu 执行
+func (g *Group) onHotKey(key string, qps float64) {
+	if g.onHot != nil {
+		g.onHot(key, qps)
+	}
+	if g.hotReplicas != 0 {
+		go g.replicateHot(key)
+	}
+}
+
+// replicateHot 把本节点负责的热点 key 写入其他节点的 hotCache
+func (g *Group) replicateHot(key string) {
+	lister, ok := g.peers.(PeerLister)
+	if !ok {
+		return
+	}
+	value, entry, ok := g.mainCache.getEntry(key)
+	if !ok {
+		return
+	}
+	req := &gocachepb.SetRequest{Group: g.name, Key: key, Value: value.ByteSlice(), Hot: true, Version: entry.version}
+	if !entry.Expire.IsZero() {
+		ttl := time.Until(entry.Expire.Add(-g.staleTTL))
+		if ttl <= 0 {
+			return
+		}
+		req.Ttl = durationpb.New(ttl)
+	}
+
+	var wg sync.WaitGroup
+	for _, peer := range hotPeers(lister.ListPeers(), key, g.hotReplicas) {
+		ps, ok := peer.(PeerSetter)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ps.Set(context.Background(), req); err != nil {
+				g.stats.incr(&g.stats.peerErrors)
+				log.Println("[GeeCache] Failed to replicate hot key to peer", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// hotPeers 从 peers 中选出 k 个节点，起点由 key 的哈希决定，使不同的热点 key 分散到不同的节点上
+func hotPeers(peers []PeerGetter, key string, k int) []PeerGetter {
+	if k < 0 || k >= len(peers) {
+		return peers
+	}
+	start := int(hashKey(key) % uint64(len(peers)))
+	selected := make([]PeerGetter, 0, k)
+	for i := 0; i < k; i++ {
+		selected = append(selected, peers[(start+i)%len(peers)])
+	}
+	return selected
+}
@@ -0,0 +1,116 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"go-cache/gocachepb"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingPeer 记录收到的 SetRequest
+type recordingPeer struct {
+	fakePeer
+	mu   sync.Mutex
+	sets []*gocachepb.SetRequest
+}
+
+func (p *recordingPeer) Set(ctx context.Context, in *gocachepb.SetRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sets = append(p.sets, in)
+	return nil
+}
+
+func (p *recordingPeer) received() []*gocachepb.SetRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*gocachepb.SetRequest(nil), p.sets...)
+}
+
+// ownerPicker 本节点负责所有 key，ListPeers 返回 peers
+type ownerPicker []PeerGetter
+
+func (p ownerPicker) PickPeer(key string) (PeerGetter, bool) { return nil, false }
+func (p ownerPicker) ListPeers() []PeerGetter                { return p }
+
+func TestHotKeyReplication(t *testing.T) {
+	peers := []*recordingPeer{{}, {}, {}}
+	gee := NewGroup("hot-replication", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("value of " + key), nil
+		}),
+		WithTTL(time.Minute),
+		WithPeers(ownerPicker{peers[0], peers[1], peers[2]}),
+		WithHotKeyDetection(10, 5, nil),
+		WithHotKeyReplication(2))
+
+	for i := 0; i < 10; i++ {
+		gee.Get(context.Background(), "Tom")
+	}
+	var got []*gocachepb.SetRequest
+	deadline := time.Now().Add(time.Second)
+	for len(got) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		got = got[:0]
+		for _, p := range peers {
+			got = append(got, p.received()...)
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("hot key should be pushed to 2 peers, got %d", len(got))
+	}
+	for _, req := range got {
+		if !req.GetHot() || string(req.GetValue()) != "value of Tom" {
+			t.Fatalf("unexpected request %v", req)
+		}
+		if ttl := req.GetTtl().AsDuration(); ttl <= 0 || ttl > time.Minute {
+			t.Fatalf("remaining TTL should be sent, got %v", ttl)
+		}
+	}
+}
+
+func TestServePeerSetHot(t *testing.T) {
+	peer := &fakePeer{}
+	gee := NewGroup("serve-hot", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("local"), nil
+		}), WithPeers(peer))
+
+	gee.ServePeerSet(context.Background(), &gocachepb.SetRequest{Key: "remote-Tom", Value: []byte("hot"), Hot: true})
+	view, info, err := gee.GetWithInfo(context.Background(), "remote-Tom")
+	if err != nil || view.String() != "hot" || info.Source != SourceHotCache {
+		t.Fatalf("pushed hot key should be served from hotCache, got %s %v %v", view, info.Source, err)
+	}
+	if peer.gets != 0 {
+		t.Fatalf("owner should not be asked, got %d requests", peer.gets)
+	}
+
+	// 本节点负责的 key 不写入 hotCache
+	gee.ServePeerSet(context.Background(), &gocachepb.SetRequest{Key: "Tom", Value: []byte("hot"), Hot: true})
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "local" {
+		t.Fatalf("owned key should be loaded locally, got %s", view)
+	}
+}
+
+func TestHotPeers(t *testing.T) {
+	peers := make([]PeerGetter, 5)
+	for i := range peers {
+		peers[i] = &fakePeer{}
+	}
+	if got := hotPeers(peers, "Tom", -1); len(got) != 5 {
+		t.Fatalf("all peers should be selected, got %d", len(got))
+	}
+	starts := make(map[PeerGetter]bool)
+	for i := 0; i < 50; i++ {
+		got := hotPeers(peers, fmt.Sprint("key", i), 2)
+		if len(got) != 2 || got[0] == got[1] {
+			t.Fatalf("expected 2 distinct peers, got %v", got)
+		}
+		starts[got[0]] = true
+	}
+	if len(starts) < 2 {
+		t.Fatalf("different keys should start at different peers")
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+)
+
+// 响应体支持的压缩算法，即 Content-Encoding 的取值
+const (
+	EncodingGzip   = "gzip"
+	EncodingSnappy = "snappy"
+)
+
+// acceptEncoding 请求其他节点时发送的 Accept-Encoding，客户端总是能够解压这两种算法
+const acceptEncoding = EncodingSnappy + ", " + EncodingGzip
+
+// WithCompression 响应体超过 minSize 字节时压缩后再返回，算法按 encodings 的顺序
+// 从请求的 Accept-Encoding 中选择，默认优先使用更快的 snappy，其次是压缩率更高的 gzip。
+// 只影响本节点作为服务端返回的响应，各节点可以分别配置
+func WithCompression(minSize int, encodings ...string) Option {
+	return func(p *HTTPPool) {
+		if len(encodings) == 0 {
+			encodings = []string{EncodingSnappy, EncodingGzip}
+		}
+		p.compressMinSize = minSize
+		p.encodings = encodings
+	}
+}
+
+// negotiateEncoding 返回 encodings 中第一个被 Accept-Encoding 接受的算法，都不接受时返回空字符串
+func negotiateEncoding(header string, encodings []string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		ok := true
+		// q=0 表示不接受
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				ok = false
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = ok
+	}
+	for _, enc := range encodings {
+		if accepted[enc] {
+			return enc
+		}
+	}
+	return ""
+}
+
+// encode 使用 encoding 压缩 b
+func encode(encoding string, b []byte) ([]byte, error) {
+	switch encoding {
+	case EncodingSnappy:
+		return snappy.Encode(nil, b), nil
+	case EncodingGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// decode 读取响应体，按 Content-Encoding 解压
+func decode(res *http.Response) ([]byte, error) {
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch encoding := res.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+		return b, nil
+	case EncodingSnappy:
+		return snappy.Decode(nil, b)
+	case EncodingGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+}
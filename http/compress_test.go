@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	gocache "go-cache"
+	"go-cache/gocachepb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header    string
+		encodings []string
+		want      string
+	}{
+		{"snappy, gzip", []string{EncodingSnappy, EncodingGzip}, EncodingSnappy},
+		{"gzip, snappy", []string{EncodingGzip, EncodingSnappy}, EncodingGzip},
+		{"GZIP;q=0.5", []string{EncodingSnappy, EncodingGzip}, EncodingGzip},
+		{"snappy;q=0, gzip", []string{EncodingSnappy, EncodingGzip}, EncodingGzip},
+		{"br", []string{EncodingSnappy, EncodingGzip}, ""},
+		{"", []string{EncodingSnappy}, ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header, tt.encodings); got != tt.want {
+			t.Fatalf("%q %v: expected %q but got %q", tt.header, tt.encodings, tt.want, got)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	gocache.NewGroup("http-compress", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(strings.Repeat(key, 100)), nil
+		}))
+
+	for _, enc := range []string{EncodingSnappy, EncodingGzip} {
+		srv := httptest.NewServer(NewHTTPPool("http://example.com", WithCompression(200, enc)))
+		rt := &encodingRecorder{}
+		h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: &http.Client{Transport: rt}}
+
+		// 100 字节的值不超过 minSize，不压缩
+		for _, tt := range []struct {
+			key  string
+			want string
+		}{{"a", ""}, {"Tom", enc}} {
+			var res gocachepb.GetResponse
+			if err := h.Get(context.Background(), &gocachepb.GetRequest{Group: "http-compress", Key: tt.key}, &res); err != nil {
+				t.Fatal(err)
+			}
+			if string(res.GetValue()) != strings.Repeat(tt.key, 100) {
+				t.Fatalf("%s: value should be decompressed, got %q", enc, res.GetValue())
+			}
+			if rt.encoding != tt.want {
+				t.Fatalf("%s: expected Content-Encoding %q for %s but got %q", enc, tt.want, tt.key, rt.encoding)
+			}
+		}
+		srv.Close()
+	}
+}
+
+// encodingRecorder 记录最近一个响应的 Content-Encoding
+type encodingRecorder struct {
+	encoding string
+}
+
+func (r *encodingRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		r.encoding = res.Header.Get("Content-Encoding")
+	}
+	return res, err
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"go-cache/healthcheck"
+	"net/http"
+)
+
+// healthPath 健康检查的路径，完整的地址为 <basePath>_health，
+// 不会与 <basePath><group>/<key> 冲突
+const healthPath = "_health"
+
+// CheckHealth 定期请求其他节点的 <basePath>_health，把不健康的节点暂时移出一致性哈希环，
+// 它负责的 key 由环上的下一个节点接管，节点恢复之后再加回来。阻塞直到 ctx 结束时返回 ctx.Err()：
+//
+//	go pool.CheckHealth(ctx, healthcheck.Config{})
+//
+// 每个节点根据自己的检查结果移出节点，节点之间对环的看法可能短暂地不一致，
+// 这时其他节点的请求只会从本地加载，不会来回转发。
+func (p *HTTPPool) CheckHealth(ctx context.Context, cfg healthcheck.Config) error {
+	return healthcheck.Run(ctx, cfg, p.otherPeers, p.ping, p.setHealthy)
+}
+
+// otherPeers 返回除本节点之外的全部节点
+func (p *HTTPPool) otherPeers() []string {
+	var peers []string
+	for _, peer := range p.Peers() {
+		if peer != p.self {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// ping 请求 peer 的健康检查地址
+func (p *HTTPPool) ping(ctx context.Context, peer string) error {
+	p.mu.Lock()
+	h, ok := p.httpGetters[peer]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if h.begin() != nil {
+		return nil
+	}
+	defer h.end()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.baseURL+healthPath, nil)
+	if err != nil {
+		return err
+	}
+	h.authorize(req)
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %v", res.Status)
+	}
+	return nil
+}
+
+// setHealthy 把 peer 移出或者加回一致性哈希环
+func (p *HTTPPool) setHealthy(peer string, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.httpGetters[peer]; !ok || p.ejected[peer] == !healthy {
+		return
+	}
+	if healthy {
+		p.Log("Restore peer %s", peer)
+		delete(p.ejected, peer)
+	} else {
+		p.Log("Eject unhealthy peer %s", peer)
+		if p.ejected == nil {
+			p.ejected = make(map[string]bool)
+		}
+		p.ejected[peer] = true
+	}
+	p.rebuild()
+}
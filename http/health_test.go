@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"go-cache/healthcheck"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckHealth(t *testing.T) {
+	var down int32
+	pool := NewHTTPPool("http://example.com")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		pool.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPPool("http://self")
+	p.Set("http://self", srv.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.CheckHealth(ctx, healthcheck.Config{Interval: 2 * time.Millisecond, FailThreshold: 2, PassThreshold: 2})
+
+	owns := func() bool {
+		for i := 0; i < 100; i++ {
+			if p.Owner(fmt.Sprint("key", i)) == srv.URL {
+				return true
+			}
+		}
+		return false
+	}
+	waitFor := func(want bool) {
+		deadline := time.Now().Add(time.Second)
+		for owns() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("peer should own keys: %v", want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitFor(true)
+	atomic.StoreInt32(&down, 1)
+	waitFor(false)
+	if len(p.Peers()) != 2 {
+		t.Fatalf("ejected peer should still be a member, got %v", p.Peers())
+	}
+	atomic.StoreInt32(&down, 0)
+	waitFor(true)
+}
+
+func TestHealthEndpoint(t *testing.T) {
+	srv := httptest.NewServer(NewHTTPPool("http://example.com", WithToken("secret")))
+	defer srv.Close()
+	p := NewHTTPPool("http://self", WithToken("secret"))
+	p.Set(srv.URL)
+	if err := p.ping(context.Background(), srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(srv.URL + DefaultBasePath + healthPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("health endpoint should require the token, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,688 @@
+// Package http 通过 HTTP 在节点之间提供缓存的值，使多个节点组成分布式缓存。
+//
+// 在不可信的网络中运行时，节点地址使用 https://，服务端在 http.Server.TLSConfig 中
+// 设置 ClientAuth: tls.RequireAndVerifyClientCert 要求客户端证书，客户端通过 WithTLSConfig 提供证书；
+// 或者通过 WithToken 使用共享的令牌认证每个请求。
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	gocache "go-cache"
+	"go-cache/consistenthash"
+	"go-cache/gocachepb"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultBasePath 节点之间通信的默认路径前缀，
+// 完整的地址为 http://<host>/_gocache/<group>/<key>
+const DefaultBasePath = "/_gocache/"
+
+// DefaultReplicas 一致性哈希中每个节点默认的虚拟节点数量
+const DefaultReplicas = 50
+
+// DefaultMaxBodySize 默认允许的最大请求体（PUT 同步的新值、批量请求），超过时返回 413
+const DefaultMaxBodySize = 64 << 20
+
+// HTTPPool 实现了 http.Handler，为其他节点提供本节点的缓存
+type HTTPPool struct {
+	// 本节点的地址，例如 "http://10.0.0.2:8008"
+	self     string
+	basePath string
+	replicas int
+	hash     consistenthash.Hash
+	// 配置了 WithClientOnly 时本节点不加入一致性哈希环
+	clientOnly bool
+	// 构造选择节点的 Picker，默认为一致性哈希环
+	newPicker func() consistenthash.Picker
+	// 每个 key 保存的副本数
+	replication int
+	// 请求其他节点使用的 http.Client
+	client *http.Client
+	// 请求其他节点时使用的 TLS 配置
+	tlsConfig *tls.Config
+	// 节点之间共享的令牌，为空表示不认证
+	token string
+	// 响应体超过 compressMinSize 字节时按 encodings 的顺序协商压缩算法，encodings 为空表示不压缩
+	compressMinSize int
+	encodings       []string
+	// 允许的最大请求体字节数
+	maxBodySize int64
+
+	mu sync.Mutex
+	// 全部节点（包括本节点），按 Set 传入的顺序
+	members []string
+	// 根据 key 选择节点的一致性哈希环，不包括被健康检查移出的节点
+	peers consistenthash.Picker
+	// 健康检查判定为不健康、暂时移出一致性哈希环的节点
+	ejected map[string]bool
+	// SetWeights 设置的节点权重，不在其中的节点权重为 1
+	weights map[string]int
+	// 每个节点对应的客户端，key 为节点地址
+	httpGetters map[string]*httpGetter
+}
+
+var (
+	_ gocache.PeerPicker    = (*HTTPPool)(nil)
+	_ gocache.ReplicaPicker = (*HTTPPool)(nil)
+	_ gocache.PeerLister    = (*HTTPPool)(nil)
+)
+
+// Option 构造 HTTPPool 时的可选配置
+type Option func(*HTTPPool)
+
+// WithBasePath 使用 basePath 代替 DefaultBasePath，所有节点需要使用相同的配置
+func WithBasePath(basePath string) Option {
+	return func(p *HTTPPool) {
+		if !strings.HasSuffix(basePath, "/") {
+			basePath += "/"
+		}
+		p.basePath = basePath
+	}
+}
+
+// WithReplicas 设置一致性哈希中每个节点的虚拟节点数量，默认为 DefaultReplicas
+func WithReplicas(n int) Option {
+	return func(p *HTTPPool) {
+		p.replicas = n
+	}
+}
+
+// WithHash 设置选择节点使用的哈希函数，默认为 consistenthash.XXHash，所有节点需要使用相同的哈希函数。
+// 从使用 crc32 的旧版本滚动升级时可以先传入 consistenthash.CRC32
+func WithHash(fn consistenthash.Hash) Option {
+	return func(p *HTTPPool) {
+		p.hash = fn
+	}
+}
+
+// WithRendezvousHashing 使用 Rendezvous（HRW）哈希代替一致性哈希环选择节点，节点较少时分布更均匀，
+// 此时 WithReplicas 不生效。所有节点需要使用相同的配置
+func WithRendezvousHashing() Option {
+	return func(p *HTTPPool) {
+		p.newPicker = func() consistenthash.Picker { return consistenthash.NewRendezvous(p.hash) }
+	}
+}
+
+// WithJumpHashing 使用跳跃一致性哈希选择节点，查找时不分配内存，比一致性哈希环快得多，此时 WithReplicas 不生效。
+// 节点按 Set 传入的顺序编号，所有节点需要以相同的顺序调用 Set，新的节点应当添加在末尾，
+// 删除或者健康检查移出中间的节点会使它之后的节点负责的 key 大量迁移，因此只适合成员稳定的集群。
+// 节点列表来自注册中心时应当使用 registry.OnChangeAppend 而不是会排序的 registry.OnChange
+func WithJumpHashing() Option {
+	return func(p *HTTPPool) {
+		p.newPicker = func() consistenthash.Picker { return consistenthash.NewJump(p.hash) }
+	}
+}
+
+// WithClientOnly 本节点只作为集群的客户端，不加入选择节点的一致性哈希环，所有 key 都由其他节点负责，
+// 通常与 gocache.WithClientOnly 一起使用。Set 传入的节点列表可以包括也可以不包括本节点，
+// 其他节点的节点列表中不应当包括本节点
+func WithClientOnly() Option {
+	return func(p *HTTPPool) {
+		p.clientOnly = true
+	}
+}
+
+// WithReplication 每个 key 保存在一致性哈希环上顺时针方向的 n 个节点上，默认为 1。
+// 缓存未命中时依次请求各个副本，Group.Set 之后把新值写入其他副本的缓存
+func WithReplication(n int) Option {
+	return func(p *HTTPPool) {
+		p.replication = n
+	}
+}
+
+// WithMaxBodySize 设置允许的最大请求体字节数，默认为 DefaultMaxBodySize，
+// 应当不小于 gocache.WithMaxValueSize 的限制，否则较大的值无法同步到副本
+func WithMaxBodySize(n int64) Option {
+	return func(p *HTTPPool) {
+		p.maxBodySize = n
+	}
+}
+
+// WithTransport 设置请求其他节点时使用的 http.RoundTripper，默认为 http.DefaultTransport，
+// 例如传入 tracing.Transport 以传播追踪上下文
+func WithTransport(rt http.RoundTripper) Option {
+	return func(p *HTTPPool) {
+		p.client = &http.Client{Transport: rt}
+	}
+}
+
+// WithTLSConfig 请求其他节点时使用 cfg，例如通过 cfg.Certificates 提供客户端证书、
+// cfg.RootCAs 指定信任的 CA。WithTransport 传入的不是 *http.Transport 时不生效
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(p *HTTPPool) {
+		p.tlsConfig = cfg
+	}
+}
+
+// WithToken 节点之间使用共享的令牌认证：请求其他节点时带上 Authorization: Bearer <token>，
+// ServeHTTP 拒绝令牌不匹配的请求（401）。所有节点需要使用相同的令牌，明文传输时令牌可能被窃听，
+// 应当同时使用 https
+func WithToken(token string) Option {
+	return func(p *HTTPPool) {
+		p.token = token
+	}
+}
+
+// NewHTTPPool 实例化本节点的 HTTPPool，self 为本节点的地址
+func NewHTTPPool(self string, opts ...Option) *HTTPPool {
+	p := &HTTPPool{
+		self:        self,
+		basePath:    DefaultBasePath,
+		replicas:    DefaultReplicas,
+		replication: 1,
+		client:      http.DefaultClient,
+		maxBodySize: DefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.newPicker == nil {
+		p.newPicker = func() consistenthash.Picker { return consistenthash.New(p.replicas, p.hash) }
+	}
+	return p
+}
+
+// Self 返回本节点的地址
+func (p *HTTPPool) Self() string {
+	return p.self
+}
+
+// Set 设置集群中的全部节点（包括本节点），所有节点需要使用相同的节点列表和配置，
+// 这样同一个 key 在每个节点上都会选出相同的负责节点。
+// 可以在运行期间反复调用以增删节点：新的一致性哈希环在锁内一次性替换，
+// 已经存在的节点继续使用原来的连接，发往被移除节点的请求不会被中断，全部结束后再关闭空闲连接。
+func (p *HTTPPool) Set(peers ...string) {
+	p.mu.Lock()
+	old := p.httpGetters
+	getters := make(map[string]*httpGetter, len(peers))
+	for _, peer := range peers {
+		if h, ok := old[peer]; ok {
+			getters[peer] = h
+			continue
+		}
+		getters[peer] = &httpGetter{baseURL: peer + p.basePath, client: p.peerClient(), token: p.token}
+	}
+	for peer := range p.ejected {
+		if _, ok := getters[peer]; !ok {
+			delete(p.ejected, peer)
+		}
+	}
+	p.members = append([]string(nil), peers...)
+	p.httpGetters = getters
+	p.rebuild()
+	p.mu.Unlock()
+
+	for peer, h := range old {
+		if _, ok := getters[peer]; !ok {
+			p.Log("Remove peer %s", peer)
+			go h.drain()
+		}
+	}
+}
+
+// rebuild 用健康的节点重建一致性哈希环，调用方需要持有 p.mu
+func (p *HTTPPool) rebuild() {
+	m := p.newPicker()
+	wp, weighted := m.(consistenthash.WeightedPicker)
+	for _, peer := range p.members {
+		if p.ejected[peer] || (p.clientOnly && peer == p.self) {
+			continue
+		}
+		if w, ok := p.weights[peer]; ok && weighted {
+			wp.AddWeighted(peer, w)
+		} else {
+			m.Add(peer)
+		}
+	}
+	p.peers = m
+}
+
+// SetWeights 设置节点的权重，节点负责的 key 与权重大致成正比，例如按内存大小设置权重，
+// 使内存较大的节点负责更多的 key。不在 weights 中的节点权重为 1，WithJumpHashing 时不生效。
+// 与 Set 一样，所有节点需要使用相同的权重
+func (p *HTTPPool) SetWeights(weights map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.weights = make(map[string]int, len(weights))
+	for peer, w := range weights {
+		p.weights[peer] = w
+	}
+	p.rebuild()
+}
+
+// Peers 返回当前的全部节点，包括被健康检查移出一致性哈希环的节点
+func (p *HTTPPool) Peers() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]string, 0, len(p.httpGetters))
+	for peer := range p.httpGetters {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// peerClient 为一个节点创建 http.Client，能够复制 Transport 时每个节点使用独立的连接池，
+// 节点被移除后可以单独关闭它的空闲连接
+func (p *HTTPPool) peerClient() *http.Client {
+	rt := p.client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if t, ok := rt.(*http.Transport); ok {
+		t = t.Clone()
+		if p.tlsConfig != nil {
+			t.TLSClientConfig = p.tlsConfig.Clone()
+		}
+		c := *p.client
+		c.Transport = t
+		return &c
+	}
+	return p.client
+}
+
+// PickPeer 实现 gocache.PeerPicker，根据一致性哈希选择负责 key 的节点
+func (p *HTTPPool) PickPeer(key string) (gocache.PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		return p.httpGetters[peer], true
+	}
+	return nil, false
+}
+
+// PickReplicas 实现 gocache.ReplicaPicker，按一致性哈希的顺序返回负责 key 的其他副本
+func (p *HTTPPool) PickReplicas(key string) ([]gocache.PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	var peers []gocache.PeerGetter
+	self := false
+	for _, peer := range p.peers.GetN(key, p.replication) {
+		if peer == p.self {
+			self = true
+			continue
+		}
+		peers = append(peers, p.httpGetters[peer])
+	}
+	return peers, self
+}
+
+// ListPeers 实现 gocache.PeerLister，按地址顺序返回除本节点和不健康的节点之外的全部节点
+func (p *HTTPPool) ListPeers() []gocache.PeerGetter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addrs := make([]string, 0, len(p.httpGetters))
+	for addr := range p.httpGetters {
+		if addr != p.self && !p.ejected[addr] {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+	peers := make([]gocache.PeerGetter, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = p.httpGetters[addr]
+	}
+	return peers
+}
+
+// Owner 返回负责 key 的节点地址，没有调用过 Set 时返回空字符串
+func (p *HTTPPool) Owner(key string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return ""
+	}
+	return p.peers.Get(key)
+}
+
+// Ejected 按地址顺序返回被健康检查移出一致性哈希环的节点
+func (p *HTTPPool) Ejected() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]string, 0, len(p.ejected))
+	for peer := range p.ejected {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// Log 带有节点地址的日志
+func (p *HTTPPool) Log(format string, v ...interface{}) {
+	log.Printf("[Server %s] %s", p.self, fmt.Sprintf(format, v...))
+}
+
+// ServeHTTP 处理 GET <basePath><group>/<key>，group 和 key 需要经过 url.PathEscape 转义；
+// PUT 同一路径时请求体为 protobuf 编码的 SetRequest，用于副本之间同步新值；
+// DELETE 同一路径时从本节点的缓存中删除 key，用于 Group.Remove 通知所有节点。
+// 404 只表示数据源中没有 key，basePath 不匹配时返回 400，本节点没有该 Group 时返回 501，
+// 使请求方继续尝试其他副本或从本地加载
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, p.basePath) {
+		http.Error(w, "unexpected path: "+path, http.StatusBadRequest)
+		return
+	}
+	if !p.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if path == p.basePath+healthPath {
+		w.Write([]byte("ok"))
+		return
+	}
+	if path == p.basePath+multiPath {
+		p.serveMulti(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.SplitN(path[len(p.basePath):], "/", 2)
+	if len(parts) != 2 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	groupName, err := url.PathUnescape(parts[0])
+	if err != nil {
+		http.Error(w, "bad group name", http.StatusBadRequest)
+		return
+	}
+	key, err := url.PathUnescape(parts[1])
+	if err != nil {
+		http.Error(w, "bad key", http.StatusBadRequest)
+		return
+	}
+
+	group := gocache.GetGroup(groupName)
+	if group == nil {
+		http.Error(w, "no such group: "+groupName, http.StatusNotImplemented)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		p.serveSet(w, r, group, key)
+		return
+	case http.MethodDelete:
+		p.serveInvalidate(w, r, group, key)
+		return
+	}
+	var res gocachepb.GetResponse
+	if err := group.ServePeer(r.Context(), key, &res); err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	p.writeProto(w, r, &res)
+}
+
+// writeProto 把 protobuf 编码的 m 作为响应体，按 Accept-Encoding 压缩
+func (p *HTTPPool) writeProto(w http.ResponseWriter, r *http.Request, m proto.Message) {
+	body, err := proto.Marshal(m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Add("Vary", "Accept-Encoding")
+	if len(p.encodings) > 0 && len(body) > p.compressMinSize {
+		if enc := negotiateEncoding(r.Header.Get("Accept-Encoding"), p.encodings); enc != "" {
+			if b, err := encode(enc, body); err == nil && len(b) < len(body) {
+				w.Header().Set("Content-Encoding", enc)
+				body = b
+			}
+		}
+	}
+	w.Write(body)
+}
+
+// authorized 检查请求是否带有 WithToken 设置的令牌
+func (p *HTTPPool) authorized(r *http.Request) bool {
+	if p.token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(p.token)) == 1
+}
+
+// serveSet 处理其他副本同步过来的新值
+func (p *HTTPPool) serveSet(w http.ResponseWriter, r *http.Request, group *gocache.Group, key string) {
+	body, ok := p.readBody(w, r)
+	if !ok {
+		return
+	}
+	var req gocachepb.SetRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, "bad request body", http.StatusBadRequest)
+		return
+	}
+	req.Group, req.Key = group.Name(), key
+	if err := group.ServePeerSet(r.Context(), &req); err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readBody 读取不超过 maxBodySize 的请求体，失败时写入错误响应并返回 false，超过限制时返回 413
+func (p *HTTPPool) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, p.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return nil, false
+	}
+	return body, true
+}
+
+// serveInvalidate 处理其他节点 Group.Remove 发来的通知
+func (p *HTTPPool) serveInvalidate(w http.ResponseWriter, r *http.Request, group *gocache.Group, key string) {
+	req := &gocachepb.InvalidateRequest{Group: group.Name(), Key: key}
+	if err := group.ServePeerInvalidate(r.Context(), req); err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// statusOf 把 Group.Get 的错误转换为 HTTP 状态码
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, gocache.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, gocache.ErrEmptyKey), errors.Is(err, gocache.ErrKeyTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, gocache.ErrLoadThrottled), errors.Is(err, gocache.ErrGroupClosed):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// httpGetter 通过 HTTP 从其他节点获取缓存值，实现了 gocache.PeerGetter
+type httpGetter struct {
+	baseURL string
+	client  *http.Client
+	// 非空时通过 Authorization 头发送
+	token string
+
+	mu sync.Mutex
+	// 正在进行的请求数
+	inflight int
+	// 调用过 drain 之后为 true，不再接受新的请求
+	draining bool
+	// drain 等待请求结束时不为 nil，最后一个请求结束时关闭
+	idle chan struct{}
+}
+
+// errPeerRemoved 节点已经被 Set 移除，PickPeer 之后才开始的请求不再发送
+var errPeerRemoved = errors.New("peer has been removed")
+
+// begin 记录一个开始的请求，与 drain 在同一把锁内检查 draining，节点已被移除时返回 errPeerRemoved
+func (h *httpGetter) begin() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.draining {
+		return errPeerRemoved
+	}
+	h.inflight++
+	return nil
+}
+
+// end 记录一个结束的请求
+func (h *httpGetter) end() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.inflight--; h.inflight == 0 && h.idle != nil {
+		close(h.idle)
+		h.idle = nil
+	}
+}
+
+// drain 拒绝新的请求，等待正在进行的请求全部结束，然后关闭空闲连接
+func (h *httpGetter) drain() {
+	h.mu.Lock()
+	h.draining = true
+	if h.inflight > 0 {
+		if h.idle == nil {
+			h.idle = make(chan struct{})
+		}
+		idle := h.idle
+		h.mu.Unlock()
+		<-idle
+	} else {
+		h.mu.Unlock()
+	}
+	h.client.CloseIdleConnections()
+}
+
+// authorize 为请求加上令牌
+func (h *httpGetter) authorize(req *http.Request) {
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+}
+
+var (
+	_ gocache.PeerGetter      = (*httpGetter)(nil)
+	_ gocache.PeerSetter      = (*httpGetter)(nil)
+	_ gocache.PeerInvalidator = (*httpGetter)(nil)
+)
+
+// Get 请求 <baseURL><group>/<key>，响应体为 protobuf 编码的 GetResponse，
+// 对方节点可以按 Accept-Encoding 压缩响应体，节点返回 404 时返回 gocache.ErrNotFound，
+// 其他状态码（包括没有该 Group 时的 501）返回普通的错误
+func (h *httpGetter) Get(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+	if err := h.begin(); err != nil {
+		return err
+	}
+	defer h.end()
+	u := h.baseURL + url.PathEscape(in.GetGroup()) + "/" + url.PathEscape(in.GetKey())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	h.authorize(req)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return gocache.ErrNotFound
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("server returned %v: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	body, err := decode(res)
+	if err != nil {
+		return fmt.Errorf("reading response body: %v", err)
+	}
+	if err := proto.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding response body: %v", err)
+	}
+	return nil
+}
+
+// Set 以 PUT 请求 <baseURL><group>/<key>，把新值写入该节点的缓存
+func (h *httpGetter) Set(ctx context.Context, in *gocachepb.SetRequest) error {
+	if err := h.begin(); err != nil {
+		return err
+	}
+	defer h.end()
+	body, err := proto.Marshal(in)
+	if err != nil {
+		return err
+	}
+	u := h.baseURL + url.PathEscape(in.GetGroup()) + "/" + url.PathEscape(in.GetKey())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	h.authorize(req)
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("server returned %v: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Invalidate 以 DELETE 请求 <baseURL><group>/<key>，从该节点的缓存中删除 key
+func (h *httpGetter) Invalidate(ctx context.Context, in *gocachepb.InvalidateRequest) error {
+	if err := h.begin(); err != nil {
+		return err
+	}
+	defer h.end()
+	u := h.baseURL + url.PathEscape(in.GetGroup()) + "/" + url.PathEscape(in.GetKey())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	h.authorize(req)
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("server returned %v: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
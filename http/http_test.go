@@ -0,0 +1,554 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	gocache "go-cache"
+	"go-cache/consistenthash"
+	"go-cache/gocachepb"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHTTPPool(t *testing.T) {
+	gocache.NewGroup("http", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			if key == "unknown" {
+				return nil, gocache.ErrNotFound
+			}
+			return []byte("value of " + key), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com"))
+	defer srv.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/_gocache/http/Tom", http.StatusOK, "value of Tom"},
+		{"/_gocache/http/" + url.PathEscape("a/b c"), http.StatusOK, "value of a/b c"},
+		{"/_gocache/http/unknown", http.StatusNotFound, ""},
+		{"/_gocache/nosuchgroup/Tom", http.StatusNotImplemented, ""},
+		{"/_gocache/http", http.StatusBadRequest, ""},
+		{"/other", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Fatalf("%s: expected status %d but got %d", tt.path, tt.status, resp.StatusCode)
+		}
+		if tt.body == "" {
+			continue
+		}
+		var res gocachepb.GetResponse
+		if err := proto.Unmarshal(body, &res); err != nil || string(res.GetValue()) != tt.body {
+			t.Fatalf("%s: expected %q but got %v %v", tt.path, tt.body, &res, err)
+		}
+	}
+
+	resp, err := http.Post(srv.URL+"/_gocache/http/Tom", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("POST should not be allowed, got %d", resp.StatusCode)
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	err := &gocache.LoadError{Group: "g", Key: "k", Err: gocache.ErrNotFound}
+	if statusOf(err) != http.StatusNotFound {
+		t.Fatalf("wrapped ErrNotFound should map to 404")
+	}
+	if statusOf(errors.New("boom")) != http.StatusInternalServerError {
+		t.Fatalf("unknown error should map to 500")
+	}
+}
+
+func TestOwner(t *testing.T) {
+	peers := []string{"http://a:8001", "http://b:8002", "http://c:8003"}
+	a := NewHTTPPool(peers[0])
+	b := NewHTTPPool(peers[1])
+	if a.Owner("Tom") != "" {
+		t.Fatalf("no owner should be picked before Set")
+	}
+	a.Set(peers...)
+	b.Set(peers[2], peers[1], peers[0])
+
+	owners := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		owner := a.Owner(key)
+		if owner != b.Owner(key) {
+			t.Fatalf("%s: every node should pick the same owner", key)
+		}
+		owners[owner] = true
+	}
+	if len(owners) != len(peers) {
+		t.Fatalf("keys should be spread over all peers, got %v", owners)
+	}
+}
+
+func TestRendezvousHashing(t *testing.T) {
+	peers := []string{"http://a:8001", "http://b:8002", "http://c:8003"}
+	p := NewHTTPPool(peers[0], WithRendezvousHashing(), WithReplication(2))
+	p.Set(peers...)
+	r := consistenthash.NewRendezvous(nil)
+	r.Add(peers...)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		if owner := p.Owner(key); owner != r.Get(key) {
+			t.Fatalf("%s: expected owner %s, got %s", key, r.Get(key), owner)
+		}
+		_, self := p.PickReplicas(key)
+		if want := contains(r.GetN(key, 2), peers[0]); self != want {
+			t.Fatalf("%s: replicas should follow rendezvous order", key)
+		}
+	}
+}
+
+func TestJumpHashing(t *testing.T) {
+	peers := []string{"http://a:8001", "http://b:8002", "http://c:8003"}
+	p := NewHTTPPool(peers[0], WithJumpHashing())
+	p.Set(peers...)
+	j := consistenthash.NewJump(nil)
+	j.Add(peers...)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		if owner := p.Owner(key); owner != j.Get(key) {
+			t.Fatalf("%s: nodes should be numbered in the order of Set, expected %s, got %s", key, j.Get(key), owner)
+		}
+	}
+}
+
+func TestSetWeights(t *testing.T) {
+	peers := []string{"http://a:8001", "http://b:8002"}
+	for _, opt := range []Option{WithReplicas(DefaultReplicas), WithRendezvousHashing()} {
+		p := NewHTTPPool(peers[0], opt)
+		p.Set(peers...)
+		p.SetWeights(map[string]int{peers[1]: 3})
+		n := 0
+		for i := 0; i < 4000; i++ {
+			if p.Owner(fmt.Sprint("key", i)) == peers[1] {
+				n++
+			}
+		}
+		if share := float64(n) / 4000; share < 0.65 || share > 0.85 {
+			t.Fatalf("peer with weight 3 owns %.2f of the keys, expected about 0.75", share)
+		}
+	}
+}
+
+func TestClientOnly(t *testing.T) {
+	peers := []string{"http://a:8001", "http://b:8002", "http://c:8003"}
+	p := NewHTTPPool(peers[0], WithClientOnly())
+	p.Set(peers...)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		if owner := p.Owner(key); owner == peers[0] {
+			t.Fatalf("%s: client-only node should not own keys", key)
+		}
+		if _, ok := p.PickPeer(key); !ok {
+			t.Fatalf("%s: every key should be picked from another peer", key)
+		}
+	}
+}
+
+func contains(nodes []string, node string) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCluster(t *testing.T) {
+	servers := make([]*httptest.Server, 2)
+	pools := make([]*HTTPPool, 2)
+	for i := range servers {
+		mux := http.NewServeMux()
+		servers[i] = httptest.NewServer(mux)
+		defer servers[i].Close()
+		pools[i] = NewHTTPPool(servers[i].URL)
+		mux.Handle(DefaultBasePath, pools[i])
+	}
+	peers := []string{servers[0].URL, servers[1].URL}
+	for _, p := range pools {
+		p.Set(peers...)
+	}
+
+	// 同一个进程中 Group 的名称是唯一的，这里只在节点 0 上创建 Group，
+	// 由节点 1 的 HTTPPool 选出节点 0 并通过 HTTP 获取
+	var loads int32
+	gocache.NewGroup("cluster", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return []byte("value of " + key), nil
+		}), gocache.WithTTL(time.Minute))
+
+	var key string
+	for i := 0; ; i++ {
+		if key = fmt.Sprint("key/", i); pools[1].Owner(key) == peers[0] {
+			break
+		}
+	}
+	peer, ok := pools[1].PickPeer(key)
+	if !ok {
+		t.Fatalf("node 1 should pick node 0 for %s", key)
+	}
+	var res gocachepb.GetResponse
+	err := peer.Get(context.Background(), &gocachepb.GetRequest{Group: "cluster", Key: key}, &res)
+	if err != nil || string(res.GetValue()) != "value of "+key {
+		t.Fatalf("expected value from peer, got %v %v", &res, err)
+	}
+	if ttl := res.GetTtl().AsDuration(); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL should travel with the value, got %v", ttl)
+	}
+	if loads != 1 {
+		t.Fatalf("key should be loaded once by its owner, got %d", loads)
+	}
+	if _, ok := pools[0].PickPeer(key); ok {
+		t.Fatalf("owner should not pick another peer for its own key")
+	}
+}
+
+func TestHTTPGetterNotFound(t *testing.T) {
+	gocache.NewGroup("http-notfound", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return nil, gocache.ErrNotFound
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com"))
+	defer srv.Close()
+	h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: http.DefaultClient}
+	err := h.Get(context.Background(), &gocachepb.GetRequest{Group: "http-notfound", Key: "Tom"}, &gocachepb.GetResponse{})
+	if !errors.Is(err, gocache.ErrNotFound) {
+		t.Fatalf("404 should map to ErrNotFound, got %v", err)
+	}
+	err = h.Get(context.Background(), &gocachepb.GetRequest{Group: "nosuchgroup", Key: "Tom"}, &gocachepb.GetResponse{})
+	if err == nil || errors.Is(err, gocache.ErrNotFound) {
+		t.Fatalf("missing group should not map to ErrNotFound, got %v", err)
+	}
+	h.baseURL = srv.URL + "/other/"
+	err = h.Get(context.Background(), &gocachepb.GetRequest{Group: "http-notfound", Key: "Tom"}, &gocachepb.GetResponse{})
+	if err == nil || errors.Is(err, gocache.ErrNotFound) {
+		t.Fatalf("basePath mismatch should not map to ErrNotFound, got %v", err)
+	}
+}
+
+func TestPeerWithoutGroup(t *testing.T) {
+	// 模拟滚动发布中还没有注册该 Group 的节点
+	pool := NewHTTPPool("peer")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.Replace(r.URL.Path, "/http-missing/", "/http-missing-absent/", 1)
+		r.URL.RawPath = ""
+		pool.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPPool("self")
+	p.Set("self", srv.URL)
+	gee := gocache.NewGroup("http-missing", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("local " + key), nil
+		}), gocache.WithPeers(p))
+	var key string
+	for i := 0; ; i++ {
+		if key = fmt.Sprint("key", i); p.Owner(key) == srv.URL {
+			break
+		}
+	}
+	v, err := gee.Get(context.Background(), key)
+	if err != nil || v.String() != "local "+key {
+		t.Fatalf("peer without the group should fall back to the local getter, got %q %v", v.String(), err)
+	}
+}
+
+func TestSetAtRuntime(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	gocache.NewGroup("http-dynamic", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			close(started)
+			<-release
+			return []byte(key), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com"))
+	defer srv.Close()
+
+	p := NewHTTPPool("self")
+	p.Set("self", srv.URL)
+	var key string
+	for i := 0; ; i++ {
+		if key = fmt.Sprint("key", i); p.Owner(key) == srv.URL {
+			break
+		}
+	}
+	peer, _ := p.PickPeer(key)
+
+	done := make(chan error)
+	go func() {
+		done <- peer.Get(context.Background(), &gocachepb.GetRequest{Group: "http-dynamic", Key: key}, &gocachepb.GetResponse{})
+	}()
+	<-started
+
+	// 请求进行中移除节点，已经发出的请求应当正常完成
+	p.Set("self")
+	if _, ok := p.PickPeer(key); ok {
+		t.Fatalf("removed peer should not be picked")
+	}
+	if peers := p.Peers(); len(peers) != 1 || peers[0] != "self" {
+		t.Fatalf("unexpected peers %v", peers)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("in-flight request should not be interrupted, got %v", err)
+	}
+
+	// 移除之后才开始的请求不再发送给该节点
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := peer.Get(context.Background(), &gocachepb.GetRequest{Group: "http-dynamic", Key: key}, &gocachepb.GetResponse{})
+		if errors.Is(err, errPeerRemoved) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("request to a drained peer should be rejected, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p.Set("self", srv.URL)
+	h := p.httpGetters[srv.URL]
+	p.Set("self", srv.URL, "http://other")
+	if p.httpGetters[srv.URL] != h {
+		t.Fatalf("existing peer should keep its client")
+	}
+}
+
+func TestSetConcurrently(t *testing.T) {
+	p := NewHTTPPool("self")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				p.Set("self", fmt.Sprint("http://peer", (i+j)%3))
+				p.PickPeer(fmt.Sprint("key", j))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPickReplicas(t *testing.T) {
+	peers := []string{"http://a:8001", "http://b:8002", "http://c:8003"}
+	p := NewHTTPPool(peers[0], WithReplication(2))
+	if got, self := p.PickReplicas("Tom"); got != nil || self {
+		t.Fatalf("no replicas should be picked before Set")
+	}
+	p.Set(peers...)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		got, self := p.PickReplicas(key)
+		if n := len(got); self && n != 1 || !self && n != 2 {
+			t.Fatalf("%s: expected 2 replicas in total, got %d others (self=%v)", key, n, self)
+		}
+		owner := p.Owner(key)
+		if owner != peers[0] && got[0] != p.httpGetters[owner] {
+			t.Fatalf("%s: the owner should be the first replica", key)
+		}
+	}
+}
+
+func TestHTTPGetterSet(t *testing.T) {
+	gee := gocache.NewGroup("http-set", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("old"), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com"))
+	defer srv.Close()
+	h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: http.DefaultClient}
+
+	err := h.Set(context.Background(), &gocachepb.SetRequest{Group: "http-set", Key: "a/b", Value: []byte("new")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view, _ := gee.Get(context.Background(), "a/b"); view.String() != "new" {
+		t.Fatalf("PUT should update the cache, got %s", view)
+	}
+	err = h.Set(context.Background(), &gocachepb.SetRequest{Group: "nosuchgroup", Key: "a"})
+	if err == nil {
+		t.Fatalf("PUT to an unknown group should fail")
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	gocache.NewGroup("http-body", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com", WithMaxBodySize(64)))
+	defer srv.Close()
+
+	body, _ := proto.Marshal(&gocachepb.SetRequest{Value: []byte(strings.Repeat("x", 128))})
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+DefaultBasePath+"http-body/a", strings.NewReader(string(body)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized PUT should return 413, got %d", resp.StatusCode)
+	}
+	resp, err = http.Post(srv.URL+DefaultBasePath+multiPath, "application/x-protobuf", strings.NewReader(strings.Repeat("x", 128)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized batch request should return 413, got %d", resp.StatusCode)
+	}
+
+	h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: http.DefaultClient}
+	if err := h.Set(context.Background(), &gocachepb.SetRequest{Group: "http-body", Key: "a", Value: []byte("small")}); err != nil {
+		t.Fatalf("small PUT should succeed, got %v", err)
+	}
+}
+
+func TestHTTPGetterInvalidate(t *testing.T) {
+	loads := 0
+	gee := gocache.NewGroup("http-invalidate", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			loads++
+			return []byte("v"), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com"))
+	defer srv.Close()
+	h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: http.DefaultClient}
+
+	gee.Get(context.Background(), "a/b")
+	if err := h.Invalidate(context.Background(), &gocachepb.InvalidateRequest{Group: "http-invalidate", Key: "a/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gee.Peek("a/b"); err == nil {
+		t.Fatalf("DELETE should remove the key")
+	}
+	err := h.Invalidate(context.Background(), &gocachepb.InvalidateRequest{Group: "nosuchgroup", Key: "a"})
+	if err == nil {
+		t.Fatalf("DELETE to an unknown group should fail")
+	}
+}
+
+func TestToken(t *testing.T) {
+	gocache.NewGroup("http-token", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com", WithToken("secret")))
+	defer srv.Close()
+
+	for _, token := range []string{"", "wrong"} {
+		h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: http.DefaultClient, token: token}
+		err := h.Get(context.Background(), &gocachepb.GetRequest{Group: "http-token", Key: "Tom"}, &gocachepb.GetResponse{})
+		if err == nil || !strings.Contains(err.Error(), "401") {
+			t.Fatalf("token %q should be rejected, got %v", token, err)
+		}
+	}
+
+	p := NewHTTPPool("http://self", WithToken("secret"))
+	p.Set(srv.URL)
+	peer, _ := p.PickPeer("Tom")
+	var res gocachepb.GetResponse
+	if err := peer.Get(context.Background(), &gocachepb.GetRequest{Group: "http-token", Key: "Tom"}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if string(res.GetValue()) != "Tom" {
+		t.Fatalf("unexpected value %q", res.GetValue())
+	}
+}
+
+// newCert 生成一个自签名的证书，同时可以用作服务端和客户端证书
+func newCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gocache"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+func TestMutualTLS(t *testing.T) {
+	gocache.NewGroup("http-mtls", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	cert, roots := newCert(t)
+	srv := httptest.NewUnstartedServer(NewHTTPPool("https://example.com"))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    roots,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(cfg *tls.Config) error {
+		p := NewHTTPPool("https://self", WithTLSConfig(cfg))
+		p.Set(srv.URL)
+		peer, _ := p.PickPeer("Tom")
+		return peer.Get(context.Background(), &gocachepb.GetRequest{Group: "http-mtls", Key: "Tom"}, &gocachepb.GetResponse{})
+	}
+	if err := get(&tls.Config{RootCAs: roots}); err == nil {
+		t.Fatalf("request without a client certificate should fail")
+	}
+	if err := get(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	gocache "go-cache"
+	"go-cache/gocachepb"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// multiPath 批量请求的路径，完整的地址为 <basePath>_multi，
+// 请求体为 protobuf 编码的 GetMultiRequest，不会与 <basePath><group>/<key> 冲突
+const multiPath = "_multi"
+
+var _ gocache.BatchPeerGetter = (*httpGetter)(nil)
+
+// serveMulti 处理其他节点的批量请求
+func (p *HTTPPool) serveMulti(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, ok := p.readBody(w, r)
+	if !ok {
+		return
+	}
+	var req gocachepb.GetMultiRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, "bad request body", http.StatusBadRequest)
+		return
+	}
+	p.Log("%s %s%s %s (%d keys)", r.Method, p.basePath, multiPath, req.GetGroup(), len(req.GetKeys()))
+	group := gocache.GetGroup(req.GetGroup())
+	if group == nil {
+		http.Error(w, "no such group: "+req.GetGroup(), http.StatusNotImplemented)
+		return
+	}
+	var res gocachepb.GetMultiResponse
+	if err := group.ServePeerMulti(r.Context(), &req, &res); err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	p.writeProto(w, r, &res)
+}
+
+// GetMulti 以 POST 请求 <baseURL>_multi，一次获取多个 key，
+// 响应体为 protobuf 编码的 GetMultiResponse，对方节点可以按 Accept-Encoding 压缩响应体
+func (h *httpGetter) GetMulti(ctx context.Context, in *gocachepb.GetMultiRequest, out *gocachepb.GetMultiResponse) error {
+	if err := h.begin(); err != nil {
+		return err
+	}
+	defer h.end()
+	body, err := proto.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL+multiPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	h.authorize(req)
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("server returned %v: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	b, err := decode(res)
+	if err != nil {
+		return fmt.Errorf("reading response body: %v", err)
+	}
+	if err := proto.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("decoding response body: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	gocache "go-cache"
+	"go-cache/gocachepb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPGetterGetMulti(t *testing.T) {
+	gocache.NewGroup("http-multi", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			if key == "missing" {
+				return nil, gocache.ErrNotFound
+			}
+			return []byte(strings.Repeat(key, 100)), nil
+		}))
+	srv := httptest.NewServer(NewHTTPPool("http://example.com", WithCompression(64)))
+	defer srv.Close()
+	h := &httpGetter{baseURL: srv.URL + DefaultBasePath, client: http.DefaultClient}
+
+	res := &gocachepb.GetMultiResponse{}
+	err := h.GetMulti(context.Background(), &gocachepb.GetMultiRequest{Group: "http-multi", Keys: []string{"a/b", "missing", "c"}}, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetValues()) != 2 || string(res.GetValues()["a/b"].GetValue()) != strings.Repeat("a/b", 100) {
+		t.Fatalf("unexpected response %v", res)
+	}
+	if len(res.GetFailed()) != 0 {
+		t.Fatalf("unexpected failed keys %v", res.GetFailed())
+	}
+
+	err = h.GetMulti(context.Background(), &gocachepb.GetMultiRequest{Group: "nosuchgroup", Keys: []string{"a"}}, res)
+	if err == nil {
+		t.Fatalf("request to an unknown group should fail")
+	}
+	resp, err := http.Get(srv.URL + DefaultBasePath + multiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %v", resp.Status)
+	}
+}
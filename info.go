@@ -0,0 +1,103 @@
+package go_cache
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// GroupInfo Group 的配置与实时的统计信息
+type GroupInfo struct {
+	Config
+	// 是否配置了 WithWriteBehind
+	WriteBehind bool
+
+	Stats     Stats
+	MainCache CacheStats
+	HotCache  CacheStats
+}
+
+// Info 返回 Group 的配置与统计信息
+func (g *Group) Info() GroupInfo {
+	return GroupInfo{
+		Config: Config{
+			Name:               g.name,
+			CacheBytes:         g.cacheBytes,
+			Getter:             g.getter,
+			TTL:                g.ttl,
+			StaleTTL:           g.staleTTL,
+			NegativeTTL:        g.negativeTTL,
+			TTLJitter:          g.ttlJitter,
+			EarlyRefreshBeta:   g.earlyRefreshBeta,
+			CompressThreshold:  g.mainCache.compressThreshold,
+			MaxValueSize:       g.maxValueSize,
+			MaxKeySize:         g.maxKeySize,
+			LoadTimeout:        g.loadTimeout,
+			MaxConcurrentLoads: cap(g.loadSem),
+			ClientOnly:         g.clientOnly,
+		},
+		WriteBehind: g.writeBehind != nil,
+		Stats:       g.Stats(),
+		MainCache:   g.CacheStats(MainCache),
+		HotCache:    g.CacheStats(HotCache),
+	}
+}
+
+// ListGroups 按名称顺序返回所有 Group 的信息
+func ListGroups() []GroupInfo {
+	mu.RLock()
+	list := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, g)
+	}
+	mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	infos := make([]GroupInfo, len(list))
+	for i, g := range list {
+		infos[i] = g.Info()
+	}
+	return infos
+}
+
+// ItemInfo Get 获取到的值的来源与时效
+type ItemInfo struct {
+	// 值的来源
+	Source Source
+	// 值写入缓存之后经过的时间，刚刚从数据源加载时接近 0
+	Age time.Duration
+	// 距离过期的剩余时间，0 表示永不过期，负数表示已经过期、
+	// 处于 WithStaleWhileRevalidate 的窗口中，返回的是旧值
+	TTL time.Duration
+	// 值的版本，每次写入都会分配更大的版本，可以作为 Cas 的 expected。
+	// 来源为 SourcePeer 或 SourceHotCache 时是负责 key 的节点上的版本
+	Version int64
+}
+
+// Stale 判断值是否已经过期
+func (i ItemInfo) Stale() bool {
+	return i.TTL < 0
+}
+
+type itemInfoKey struct{}
+
+// GetWithInfo 与 Get 相同，同时返回值的来源、存在的时间和剩余的有效期，
+// 同样会经过 WithGetMiddleware 添加的中间件
+func (g *Group) GetWithInfo(ctx context.Context, key string) (ByteView, ItemInfo, error) {
+	var info ItemInfo
+	v, err := g.getFunc(context.WithValue(ctx, itemInfoKey{}, &info), key)
+	return v, info, err
+}
+
+// itemInfo 根据缓存中的记录计算 ItemInfo
+func (g *Group) itemInfo(source Source, entry entryInfo) ItemInfo {
+	now := time.Now()
+	info := ItemInfo{Source: source, Age: now.Sub(entry.Created), Version: entry.version}
+	if !entry.Expire.IsZero() {
+		info.TTL = entry.Expire.Add(-g.staleTTL).Sub(now)
+		if info.TTL == 0 {
+			info.TTL = -1
+		}
+	}
+	return info
+}
@@ -0,0 +1,79 @@
+package go_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestListGroups(t *testing.T) {
+	gee := NewGroup("info", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}), WithTTL(time.Minute), WithMaxConcurrentLoads(4))
+	gee.Get(context.Background(), "Tom")
+
+	var info *GroupInfo
+	infos := ListGroups()
+	for i := range infos {
+		if i > 0 && infos[i-1].Name >= infos[i].Name {
+			t.Fatalf("groups should be sorted by name")
+		}
+		if infos[i].Name == "info" {
+			info = &infos[i]
+		}
+	}
+	if info == nil {
+		t.Fatalf("group should be listed")
+	}
+	if info.CacheBytes != 2<<10 || info.TTL != time.Minute || info.MaxConcurrentLoads != 4 {
+		t.Fatalf("unexpected config %+v", info)
+	}
+	if info.Stats.Gets != 1 || info.MainCache.Items != 1 {
+		t.Fatalf("unexpected stats %+v %+v", info.Stats, info.MainCache)
+	}
+}
+
+func TestGetWithInfo(t *testing.T) {
+	gee := NewGroup("item-info", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}), WithTTL(time.Minute), WithStaleWhileRevalidate(time.Minute))
+
+	_, info, err := gee.GetWithInfo(context.Background(), "Tom")
+	if err != nil || info.Source != SourceBackend {
+		t.Fatalf("first Get should load from backend, got %+v %v", info, err)
+	}
+	if info.TTL <= 0 || info.TTL > time.Minute {
+		t.Fatalf("unexpected TTL %v", info.TTL)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	_, info, _ = gee.GetWithInfo(context.Background(), "Tom")
+	if info.Source != SourceCache || info.Age < 10*time.Millisecond || info.Stale() {
+		t.Fatalf("second Get should hit main cache, got %+v", info)
+	}
+
+	for i := 0; i < hotKeyThreshold; i++ {
+		gee.populateHotCache("Jack", ByteView{b: []byte("hot")}, 0, 0)
+	}
+	view, info, _ := gee.GetWithInfo(context.Background(), "Jack")
+	if view.String() != "hot" || info.Source != SourceHotCache {
+		t.Fatalf("expected hot cache hit, got %s %+v", view, info)
+	}
+}
+
+func TestGetWithInfoStale(t *testing.T) {
+	gee := NewGroup("item-info-stale", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			time.Sleep(50 * time.Millisecond)
+			return []byte(key), nil
+		}), WithTTL(10*time.Millisecond), WithStaleWhileRevalidate(time.Second))
+
+	gee.Get(context.Background(), "Tom")
+	time.Sleep(20 * time.Millisecond)
+	_, info, _ := gee.GetWithInfo(context.Background(), "Tom")
+	if info.Source != SourceCache || !info.Stale() {
+		t.Fatalf("expected stale cache hit, got %+v", info)
+	}
+}
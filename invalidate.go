@@ -0,0 +1,53 @@
+package go_cache
+
+import (
+	"context"
+	"go-cache/gocachepb"
+	"log"
+	"sync"
+)
+
+// broadcastRemove 通知其他所有节点从缓存中删除 key，使它们的 hotCache 中不再保留旧值。
+// 需要 PeerPicker 实现 PeerLister、PeerGetter 实现 PeerInvalidator，失败时只记录日志，
+// 配置了 WithHintedHandoff 时暂存之后重新发送
+func (g *Group) broadcastRemove(ctx context.Context, key string) {
+	pl, ok := g.peers.(PeerLister)
+	if !ok || ctx.Value(peerRequestKey{}) != nil {
+		return
+	}
+	req := &gocachepb.InvalidateRequest{Group: g.name, Key: key}
+	var wg sync.WaitGroup
+	for _, peer := range pl.ListPeers() {
+		pi, ok := peer.(PeerInvalidator)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(peer PeerGetter) {
+			defer wg.Done()
+			if err := pi.Invalidate(ctx, req); err != nil {
+				g.stats.incr(&g.stats.peerErrors)
+				log.Println("[GeeCache] Failed to invalidate on peer", err)
+				if g.handoff != nil {
+					g.handoff.addInvalidation(peer, req)
+				}
+			}
+		}(peer)
+	}
+	wg.Wait()
+}
+
+// ServePeerInvalidate 处理其他节点 Group.Remove 发来的通知，从本节点的各级缓存中删除 key
+// 以及依赖它的 key，不会再通知其他节点
+func (g *Group) ServePeerInvalidate(ctx context.Context, req *gocachepb.InvalidateRequest) error {
+	key := req.GetKey()
+	if err := g.checkKey(key); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	g.removeLocally(key)
+	g.invalidateDependents(key)
+	return nil
+}
@@ -0,0 +1,92 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// invalidatingPeer 记录收到的删除通知，down 为 true 时拒绝
+type invalidatingPeer struct {
+	fakePeer
+	mu   sync.Mutex
+	down bool
+	keys []string
+}
+
+func (p *invalidatingPeer) Invalidate(ctx context.Context, in *gocachepb.InvalidateRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errors.New("connection refused")
+	}
+	p.keys = append(p.keys, in.GetKey())
+	return nil
+}
+
+func (p *invalidatingPeer) received() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.keys...)
+}
+
+func TestRemoveBroadcast(t *testing.T) {
+	up, down := &invalidatingPeer{}, &invalidatingPeer{down: true}
+	gee := NewGroup("remove-broadcast", 2<<10, &memStore{m: map[string]string{"Tom": "630"}},
+		WithPeers(ownerPicker{up, down}),
+		WithHintedHandoff(HintedHandoffConfig{RetryInterval: 5 * time.Millisecond}))
+	defer gee.Close(context.Background())
+
+	if err := gee.Remove(context.Background(), "Tom"); err != nil {
+		t.Fatal(err)
+	}
+	if keys := up.received(); !reflect.DeepEqual(keys, []string{"Tom"}) {
+		t.Fatalf("peer should be told to remove the key, got %v", keys)
+	}
+	if n := gee.handoff.pending(); n != 1 {
+		t.Fatalf("failed invalidation should be queued, got %d hints", n)
+	}
+	down.mu.Lock()
+	down.down = false
+	down.mu.Unlock()
+	waitFor(t, func() bool { return gee.handoff.pending() == 0 })
+	if keys := down.received(); !reflect.DeepEqual(keys, []string{"Tom"}) {
+		t.Fatalf("queued invalidation should be replayed, got %v", keys)
+	}
+
+	// 来自其他节点的删除不再转发
+	if err := gee.Remove(NewPeerContext(context.Background()), "Jack"); err != nil {
+		t.Fatal(err)
+	}
+	if keys := up.received(); len(keys) != 1 {
+		t.Fatalf("peer requests should not be broadcast, got %v", keys)
+	}
+}
+
+func TestServePeerInvalidate(t *testing.T) {
+	gee := NewGroup("serve-invalidate", 2<<10, &memStore{m: map[string]string{"Tom": "630", "sum": "1"}},
+		WithPeers(ownerPicker{&invalidatingPeer{}}))
+	gee.hotCache.add("Jack", ByteView{b: []byte("589")})
+	gee.Get(context.Background(), "Tom")
+	gee.Get(context.Background(), "sum")
+	gee.DependsOn("sum", "Tom")
+
+	for _, key := range []string{"Jack", "Tom"} {
+		if err := gee.ServePeerInvalidate(context.Background(), &gocachepb.InvalidateRequest{Key: key}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := gee.Peek(key); err == nil {
+			t.Fatalf("%s should be removed", key)
+		}
+	}
+	if _, err := gee.Peek("sum"); err == nil {
+		t.Fatalf("dependents should be removed")
+	}
+	if err := gee.ServePeerInvalidate(context.Background(), &gocachepb.InvalidateRequest{}); !errors.Is(err, ErrEmptyKey) {
+		t.Fatalf("empty key should be rejected, got %v", err)
+	}
+}
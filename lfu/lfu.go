@@ -0,0 +1,136 @@
+package lfu
+
+import "container/list"
+
+// Cache lfu 缓存淘汰策略，淘汰访问频次最低的记录，频次相同时淘汰最久未访问的记录。
+// 与 lru.Cache 拥有相同的 Value 接口和内存预算，可以直接替换。
+type Cache struct {
+	// 允许使用的最大内存
+	maxBytes int64
+	// 当前已使用的内存
+	nbytes int64
+	// 键是字符串，值是频次链表中节点型指针。
+	cache map[string]*list.Element
+	// 访问频次 -> 该频次下的双向链表，链表头部是最近访问的记录
+	freqs map[int]*list.List
+	// 当前最低的访问频次
+	minFreq int
+	// 某条记录被移除时的回调函数，可以为 nil。
+	OnEvicted func(key string, value Value)
+}
+
+type entry struct {
+	key   string
+	value Value
+	freq  int
+}
+
+// Value 接口，与 lru.Value 相同，用于返回值所占用的内存大小。
+type Value interface {
+	Len() int
+}
+
+// New 方便实例化 Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:  maxBytes,
+		cache:     make(map[string]*list.Element),
+		freqs:     make(map[int]*list.List),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Len 返回缓存的记录数
+func (c *Cache) Len() int {
+	return len(c.cache)
+}
+
+// Add 新增/修改，修改已有记录视为一次访问
+func (c *Cache) Add(key string, value Value) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		kv.value = value
+		c.touch(ele)
+	} else {
+		kv := &entry{key: key, value: value, freq: 1}
+		c.cache[key] = c.bucket(1).PushFront(kv)
+		c.minFreq = 1
+		c.nbytes += int64(len(key)) + int64(value.Len())
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Get 获取 value，并增加其访问频次
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		c.touch(ele)
+		return kv.value, true
+	}
+	return
+}
+
+// RemoveOldest 移除访问频次最低的值，频次相同时移除最久未访问的值。
+// 方法名与 lru.Cache 保持一致，方便替换。
+func (c *Cache) RemoveOldest() {
+	if len(c.freqs) == 0 {
+		return
+	}
+	l, ok := c.freqs[c.minFreq]
+	if !ok {
+		// 上一次淘汰清空了最低频次的链表，重新计算
+		c.minFreq = c.lowestFreq()
+		l = c.freqs[c.minFreq]
+	}
+	ele := l.Back()
+	kv := ele.Value.(*entry)
+	c.unlink(ele)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// touch 把记录移动到下一个频次的链表中
+func (c *Cache) touch(ele *list.Element) {
+	kv := ele.Value.(*entry)
+	c.unlink(ele)
+	if _, ok := c.freqs[kv.freq]; !ok && c.minFreq == kv.freq {
+		c.minFreq++
+	}
+	kv.freq++
+	c.cache[kv.key] = c.bucket(kv.freq).PushFront(kv)
+}
+
+// unlink 把节点从所在的频次链表中摘除，链表为空时删除该频次
+func (c *Cache) unlink(ele *list.Element) {
+	kv := ele.Value.(*entry)
+	l := c.freqs[kv.freq]
+	l.Remove(ele)
+	if l.Len() == 0 {
+		delete(c.freqs, kv.freq)
+	}
+}
+
+func (c *Cache) bucket(freq int) *list.List {
+	l, ok := c.freqs[freq]
+	if !ok {
+		l = list.New()
+		c.freqs[freq] = l
+	}
+	return l
+}
+
+func (c *Cache) lowestFreq() int {
+	min := 0
+	for f := range c.freqs {
+		if min == 0 || f < min {
+			min = f
+		}
+	}
+	return min
+}
@@ -0,0 +1,56 @@
+package lfu
+
+import (
+	"reflect"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestGet(t *testing.T) {
+	lfu := New(int64(0), nil)
+	lfu.Add("key1", String("1234"))
+	if v, ok := lfu.Get("key1"); !ok || string(v.(String)) != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	if _, ok := lfu.Get("key2"); ok {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+func TestRemoveLeastFrequent(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	lfu := New(int64(8), callback)
+	lfu.Add("k1", String("v1"))
+	lfu.Add("k2", String("v2"))
+	lfu.Get("k1")
+	lfu.Add("k3", String("v3"))
+	lfu.Get("k3")
+	lfu.Get("k3")
+	lfu.Add("k4", String("v4"))
+
+	expect := []string{"k2", "k4"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s but got %s", expect, keys)
+	}
+	if lfu.Len() != 2 || lfu.nbytes != 8 {
+		t.Fatalf("expected 2 entries and 8 bytes but got %d and %d", lfu.Len(), lfu.nbytes)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	lfu := New(int64(0), nil)
+	lfu.Add("key", String("1"))
+	lfu.Add("key", String("111"))
+
+	if lfu.nbytes != int64(len("key")+len("111")) {
+		t.Fatal("expected 6 but got", lfu.nbytes)
+	}
+}
@@ -0,0 +1,77 @@
+package lru
+
+import "sync"
+
+// DropPolicy 异步派发淘汰回调时，队列满了之后的处理方式
+type DropPolicy int
+
+const (
+	// Block 阻塞直到队列有空位，对调用方形成背压
+	Block DropPolicy = iota
+	// Drop 丢弃新的淘汰事件，并计入 Stats().DroppedEvictions
+	Drop
+)
+
+// WithAsyncEviction 把 OnEvicted、OnEvictedWithReason 放到后台协程中异步执行，
+// 避免耗时的回调阻塞缓存（以及调用方持有的锁）。
+// 事件先写入长度为 queueSize 的队列，队列满时按 drop 处理。使用完毕后需要调用 Close。
+func WithAsyncEviction(queueSize int, drop DropPolicy) Option {
+	return func(o *options) {
+		o.async = true
+		o.queueSize = queueSize
+		o.drop = drop
+	}
+}
+
+type evictEvent[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// dispatcher 淘汰回调的异步派发器
+type dispatcher[K comparable, V any] struct {
+	ch   chan evictEvent[K, V]
+	drop DropPolicy
+	once sync.Once
+	done chan struct{}
+}
+
+func (c *TypedCache[K, V]) startDispatcher(queueSize int, drop DropPolicy) {
+	d := &dispatcher[K, V]{
+		ch:   make(chan evictEvent[K, V], queueSize),
+		drop: drop,
+		done: make(chan struct{}),
+	}
+	c.dispatcher = d
+	go func() {
+		defer close(d.done)
+		for ev := range d.ch {
+			c.runEvictCallbacks(ev.key, ev.value, ev.reason)
+		}
+	}()
+}
+
+// dispatch 把淘汰事件写入队列
+func (d *dispatcher[K, V]) dispatch(ev evictEvent[K, V], stats *counters) {
+	if d.drop == Block {
+		d.ch <- ev
+		return
+	}
+	select {
+	case d.ch <- ev:
+	default:
+		stats.incr(&stats.dropped)
+	}
+}
+
+// Close 停止异步派发协程，并等待队列中剩余的回调执行完毕。
+// 没有配置 WithAsyncEviction 时什么也不做，Close 之后不能再修改缓存。
+func (c *TypedCache[K, V]) Close() {
+	d := c.dispatcher
+	if d == nil {
+		return
+	}
+	d.once.Do(func() { close(d.ch) })
+	<-d.done
+}
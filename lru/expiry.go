@@ -0,0 +1,60 @@
+package lru
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryHeap 按过期时间排序的最小堆，只包含设置了有效期的记录。
+// 清理过期记录时只需要从堆顶依次弹出，不需要扫描整个链表。
+type expiryHeap[K comparable, V any] []*entry[K, V]
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool { return h[i].expire.Before(h[j].expire) }
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i + 1
+	h[j].heapIndex = j + 1
+}
+
+func (h *expiryHeap[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	*h = append(*h, e)
+	e.heapIndex = len(*h)
+}
+
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	e.heapIndex = 0
+	return e
+}
+
+// setExpire 修改记录的过期时间，并保持堆的一致性，零值表示永不过期
+func (c *TypedCache[K, V]) setExpire(ele *entry[K, V], expire time.Time) {
+	ele.expire = expire
+	switch {
+	case ele.heapIndex != 0 && expire.IsZero():
+		heap.Remove(&c.expiry, ele.heapIndex-1)
+	case ele.heapIndex != 0:
+		heap.Fix(&c.expiry, ele.heapIndex-1)
+	case !expire.IsZero():
+		heap.Push(&c.expiry, ele)
+	}
+}
+
+// RemoveExpired 删除所有已过期的记录，返回删除的条数
+func (c *TypedCache[K, V]) RemoveExpired() int {
+	now := time.Now()
+	n := 0
+	for len(c.expiry) > 0 && c.expiry[0].expired(now) {
+		c.removeElement(c.expiry[0], Expired)
+		c.stats.incr(&c.stats.expired)
+		n++
+	}
+	return n
+}
@@ -0,0 +1,89 @@
+package lru
+
+// entryList 侵入式双向链表，链表指针直接保存在 entry 中，
+// 相比 container/list 每条记录少一次内存分配，访问时也不需要类型断言。
+// root 是哨兵节点，root.next 是头部，root.prev 是尾部。
+type entryList[K comparable, V any] struct {
+	root entry[K, V]
+	len  int
+}
+
+func newEntryList[K comparable, V any]() *entryList[K, V] {
+	l := &entryList[K, V]{}
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	return l
+}
+
+// Len 返回链表中节点的数量
+func (l *entryList[K, V]) Len() int {
+	return l.len
+}
+
+// Front 返回头部节点，链表为空时返回 nil
+func (l *entryList[K, V]) Front() *entry[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back 返回尾部节点，链表为空时返回 nil
+func (l *entryList[K, V]) Back() *entry[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// PushFront 把 e 插入到头部
+func (l *entryList[K, V]) PushFront(e *entry[K, V]) *entry[K, V] {
+	l.insertAfter(e, &l.root)
+	l.len++
+	return e
+}
+
+// MoveToFront 把 e 移动到头部
+func (l *entryList[K, V]) MoveToFront(e *entry[K, V]) {
+	if l.root.next == e {
+		return
+	}
+	l.unlink(e)
+	l.insertAfter(e, &l.root)
+}
+
+// Remove 把 e 从链表中删除
+func (l *entryList[K, V]) Remove(e *entry[K, V]) {
+	l.unlink(e)
+	e.next, e.prev, e.list = nil, nil, nil
+	l.len--
+}
+
+func (l *entryList[K, V]) insertAfter(e, at *entry[K, V]) {
+	e.prev = at
+	e.next = at.next
+	at.next.prev = e
+	at.next = e
+	e.list = l
+}
+
+func (l *entryList[K, V]) unlink(e *entry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+}
+
+// Next 返回下一个节点，已经是尾部时返回 nil
+func (e *entry[K, V]) Next() *entry[K, V] {
+	if e.list == nil || e.next == &e.list.root {
+		return nil
+	}
+	return e.next
+}
+
+// Prev 返回上一个节点，已经是头部时返回 nil
+func (e *entry[K, V]) Prev() *entry[K, V] {
+	if e.list == nil || e.prev == &e.list.root {
+		return nil
+	}
+	return e.prev
+}
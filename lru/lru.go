@@ -1,27 +1,21 @@
 package lru
 
-import "container/list"
+import (
+	"errors"
+	"go-cache/policy"
+	"time"
+	"unsafe"
+)
 
 // Cache 包含字典和双向链表的结构体类型 Cache，方便实现后续的增删查改操作。
 // lru 缓存淘汰策略
+// 它是 key 为字符串、value 为 Value 的 TypedCache 的简单封装。
 type Cache struct {
-	// 允许使用的最大内存
-	maxBytes int64
-	// 当前已使用的内存
-	nbytes int64
-	// Go 语言标准库实现的双向链表list.List
-	ll *list.List
-	// 键是字符串，值是双向链表中节点型指针。
-	cache map[string]*list.Element
-	// 某条记录被移除时的回调函数，可以为 nil。
-	OnEvicted func(key string, value Value)
+	*TypedCache[string, Value]
 }
 
-// 键值对 entry 是双向链表节点的数据类型
-type entry struct {
-	key   string
-	value Value
-}
+// Admission 准入策略接口，例如 tinylfu.TinyLFU
+type Admission = KeyAdmission[string]
 
 /*
 Value 接口
@@ -32,58 +26,169 @@ type Value interface {
 	Len() int
 }
 
-// Len 方法, Cache 类实现 Len 方法，返回双向链表中节点的 len
-func (c *Cache) Len() int {
-	return c.ll.Len()
+// Option 构造 Cache 时的可选配置
+type Option func(*options)
+
+type options struct {
+	maxBytes   int64
+	maxEntries int
+	ttl        time.Duration
+	onEvicted  any
+	noStats    bool
+	overhead   int64
+	policy     policy.EvictionPolicy
+	weigher    func(key string, value any) int64
+	async      bool
+	queueSize  int
+	drop       DropPolicy
+	shardHash  func(key string) uint64
+	admission  func() Admission
 }
 
-// New 方便实例化 Cache
-func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
-	return &Cache{
-		maxBytes:  maxBytes,
-		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
-		OnEvicted: onEvicted,
+// WithPolicy 使用指定的淘汰策略，例如 policy.LFU()，只能用于 key 为字符串的缓存
+func WithPolicy(p policy.EvictionPolicy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithAdmission 使用 newAdmission 创建的准入策略，例如 tinylfu.New，只能用于 key 为字符串的缓存。
+// ShardedCache 的每个分片分别调用 newAdmission，各自拥有独立的计数器
+func WithAdmission(newAdmission func() Admission) Option {
+	return func(o *options) {
+		o.admission = newAdmission
+	}
+}
+
+// WithMaxBytes 设置允许使用的最大内存，覆盖构造函数的 maxBytes 参数，0 表示不限制
+func WithMaxBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBytes = n
+	}
+}
+
+// WithTTL 设置 Add 写入的记录的有效期，AddWithTTL 不受影响
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
 	}
 }
 
-// Add 新增/修改
-func (c *Cache) Add(key string, value Value) {
-	if ele, ok := c.cache[key]; ok {
-		c.ll.MoveToFront(ele)
-		kv := ele.Value.(*entry)
-		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
-		kv.value = value
-	} else {
-		ele := c.ll.PushFront(&entry{key, value})
-		c.cache[key] = ele
-		c.nbytes += int64(len(key)) + int64(value.Len())
+// WithOnEvicted 设置记录被移除时的回调函数，覆盖构造函数的 onEvicted 参数，只能用于 Cache
+func WithOnEvicted(fn func(key string, value Value)) Option {
+	return func(o *options) {
+		o.onEvicted = fn
 	}
-	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
-		c.RemoveOldest()
+}
+
+// WithStats 是否统计命中率等信息（见 Stats），默认统计，关闭后可以省去原子操作的开销
+func WithStats(enabled bool) Option {
+	return func(o *options) {
+		o.noStats = !enabled
 	}
 }
 
-// Get 获取 value
-func (c *Cache) Get(key string) (value Value, ok bool) {
-	if ele, ok := c.cache[key]; ok {
-		c.ll.MoveToFront(ele)
-		kv := ele.Value.(*entry)
-		return kv.value, true
+// WithMaxEntries 限制最大记录数，记录数或内存任一超出限制时都会触发淘汰
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
 	}
-	return
-}
-
-// RemoveOldest 移除 “最近最少使用的值”
-func (c *Cache) RemoveOldest() {
-	ele := c.ll.Back()
-	if ele != nil {
-		c.ll.Remove(ele)
-		kv := ele.Value.(*entry)
-		delete(c.cache, kv.key)
-		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
-		if c.OnEvicted != nil {
-			c.OnEvicted(kv.key, kv.value)
-		}
+}
+
+// WithWeigher 使用 weigher 计算每条记录占用的内存，代替 Value.Len()，只能用于 key 为字符串的缓存。
+// 配合 NewTyped[string, any] 使用时，value 可以是任意类型而不必实现 Value 接口：
+//
+//	c := lru.NewTyped[string, any](maxBytes, nil, nil, lru.WithWeigher(weigher))
+func WithWeigher(weigher func(key string, value any) int64) Option {
+	return func(o *options) {
+		o.weigher = weigher
 	}
 }
+
+// WithShardHash 设置 ShardedCache 选择分片的哈希函数，默认为 xxhash.Sum64String，只能用于 ShardedCache
+func WithShardHash(fn func(key string) uint64) Option {
+	return func(o *options) {
+		o.shardHash = fn
+	}
+}
+
+// EstimatedEntryOverhead 估算的每条记录额外占用的内存：
+// entry 结构体，以及字典中 key 和指针所占的空间
+const EstimatedEntryOverhead = int64(unsafe.Sizeof(entry[string, Value]{})) +
+	int64(unsafe.Sizeof("")) + int64(unsafe.Sizeof(&entry[string, Value]{}))
+
+// WithEntryOverhead 设置每条记录额外占用的内存，使 maxBytes 更接近真实的进程内存，
+// 可以传入 EstimatedEntryOverhead
+func WithEntryOverhead(n int64) Option {
+	return func(o *options) {
+		o.overhead = n
+	}
+}
+
+// New 方便实例化 Cache
+func New(maxBytes int64, onEvicted func(string, Value), opts ...Option) *Cache {
+	return &Cache{NewTyped[string, Value](maxBytes, sizeOfValue, onEvicted, opts...)}
+}
+
+// NewCache 只使用 Option 实例化 Cache，例如
+//
+//	c := lru.NewCache(lru.WithMaxBytes(1<<20), lru.WithTTL(time.Minute), lru.WithPolicy(policy.LFU()))
+func NewCache(opts ...Option) *Cache {
+	return New(0, nil, opts...)
+}
+
+// Config Cache 的配置，与对应的 Option 含义相同，零值字段表示不设置
+type Config struct {
+	MaxBytes      int64
+	MaxEntries    int
+	TTL           time.Duration
+	EntryOverhead int64
+	Policy        policy.EvictionPolicy
+	OnEvicted     func(key string, value Value)
+	DisableStats  bool
+}
+
+// Validate 检查配置是否合法
+func (c Config) Validate() error {
+	switch {
+	case c.MaxBytes < 0:
+		return errors.New("lru: MaxBytes must not be negative")
+	case c.MaxEntries < 0:
+		return errors.New("lru: MaxEntries must not be negative")
+	case c.TTL < 0:
+		return errors.New("lru: TTL must not be negative")
+	case c.EntryOverhead < 0:
+		return errors.New("lru: EntryOverhead must not be negative")
+	}
+	return nil
+}
+
+// Options 把配置转换为 Option
+func (c Config) Options() []Option {
+	opts := []Option{
+		WithMaxBytes(c.MaxBytes),
+		WithMaxEntries(c.MaxEntries),
+		WithTTL(c.TTL),
+		WithEntryOverhead(c.EntryOverhead),
+		WithStats(!c.DisableStats),
+	}
+	if c.Policy != nil {
+		opts = append(opts, WithPolicy(c.Policy))
+	}
+	if c.OnEvicted != nil {
+		opts = append(opts, WithOnEvicted(c.OnEvicted))
+	}
+	return opts
+}
+
+// NewFromConfig 检查配置后实例化 Cache，opts 在 cfg 之后应用
+func NewFromConfig(cfg Config, opts ...Option) (*Cache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewCache(append(cfg.Options(), opts...)...), nil
+}
+
+func sizeOfValue(key string, value Value) int64 {
+	return int64(len(key)) + int64(value.Len())
+}
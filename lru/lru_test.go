@@ -1,8 +1,16 @@
 package lru
 
 import (
+	"bytes"
+	"fmt"
+	"go-cache/policy"
+	"go-cache/tinylfu"
 	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type String string
@@ -63,3 +71,746 @@ func TestAdd(t *testing.T) {
 		t.Fatal("expected 6 but got", lru.nbytes)
 	}
 }
+
+func TestAddWithTTL(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("key1", String("1234"), 10*time.Millisecond)
+	lru.Add("key2", String("5678"))
+	if _, ok := lru.Get("key1"); !ok {
+		t.Fatalf("cache hit key1 before expire failed")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := lru.Get("key1"); ok {
+		t.Fatalf("key1 should be expired")
+	}
+	if _, ok := lru.Get("key2"); !ok || lru.Len() != 1 {
+		t.Fatalf("key2 should never expire")
+	}
+	if lru.nbytes != int64(len("key2")+len("5678")) {
+		t.Fatal("expected 8 but got", lru.nbytes)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	var mu sync.Mutex
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("key1", String("1234"), 10*time.Millisecond)
+	stop := lru.StartJanitor(5*time.Millisecond, &mu)
+	defer stop()
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if lru.Len() != 0 {
+		t.Fatalf("janitor should remove expired key1")
+	}
+}
+
+func TestSafeCache(t *testing.T) {
+	lru := NewSafe(int64(0), nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i)
+			lru.Add(key, String(key))
+			if v, ok := lru.Get(key); !ok || string(v.(String)) != key {
+				t.Errorf("cache hit %s failed", key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if lru.Len() != 10 {
+		t.Fatalf("expected 10 entries but got %d", lru.Len())
+	}
+}
+
+func TestSafeCacheGetOldestConcurrent(t *testing.T) {
+	// CLOCK 选出记录时会清除引用位，并发的 GetOldest 不能只持有读锁
+	lru := NewSafe(int64(0), nil, WithPolicy(policy.CLOCK()))
+	for i := 0; i < 10; i++ {
+		lru.Add(strconv.Itoa(i), String("v"))
+		lru.Get(strconv.Itoa(i))
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, _, ok := lru.GetOldest(); !ok {
+					t.Errorf("GetOldest should find an entry")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if lru.Len() != 10 {
+		t.Fatalf("GetOldest should not remove entries, got %d", lru.Len())
+	}
+}
+
+func TestSafeCacheStats(t *testing.T) {
+	safe := NewSafe(int64(0), nil)
+	sharded := NewSharded(4, int64(0), nil)
+	for _, c := range []interface {
+		Add(string, Value)
+		Get(string) (Value, bool)
+		Stats() Stats
+	}{safe, sharded} {
+		c.Add("k1", String("v1"))
+		c.Get("k1")
+		c.Get("k2")
+		c.Get("k3")
+		if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+			t.Fatalf("expected 1 hit and 2 misses but got %+v", stats)
+		}
+	}
+}
+
+func TestShardHash(t *testing.T) {
+	lru := NewSharded(4, int64(0), nil, WithShardHash(func(string) uint64 { return 2 }))
+	for i := 0; i < 10; i++ {
+		lru.Add(strconv.Itoa(i), String("v"))
+	}
+	if n := lru.shards[2].Len(); n != 10 {
+		t.Fatalf("every key should land on shard 2, got %d", n)
+	}
+
+	// 默认的哈希函数把短的数字 key 均匀地分散到各个分片上
+	lru = NewSharded(16, int64(0), nil)
+	for i := 0; i < 16000; i++ {
+		lru.Add(strconv.Itoa(i), String("v"))
+	}
+	for i, sh := range lru.shards {
+		if n := sh.Len(); n < 800 || n > 1200 {
+			t.Fatalf("shard %d has %d keys, expected about 1000", i, n)
+		}
+	}
+}
+
+func TestShardedCache(t *testing.T) {
+	lru := NewSharded(4, int64(0), nil)
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		lru.Add(key, String(key))
+	}
+	if lru.Len() != 100 {
+		t.Fatalf("expected 100 entries but got %d", lru.Len())
+	}
+	if v, ok := lru.Get("42"); !ok || string(v.(String)) != "42" {
+		t.Fatalf("cache hit 42 failed")
+	}
+	var bytes int64
+	for i := 0; i < 100; i++ {
+		bytes += int64(2 * len(strconv.Itoa(i)))
+	}
+	if lru.Bytes() != bytes {
+		t.Fatalf("expected %d bytes but got %d", bytes, lru.Bytes())
+	}
+	lru.RemoveOldest()
+	if lru.Len() != 99 {
+		t.Fatalf("RemoveOldest failed")
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	lru := New(int64(8), nil)
+	lru.Admission = tinylfu.New(64)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	for i := 0; i < 3; i++ {
+		lru.Get("k1")
+		lru.Get("k2")
+	}
+	lru.Add("k3", String("v3"))
+	if _, ok := lru.Get("k3"); ok || lru.Len() != 2 {
+		t.Fatalf("one-hit key k3 should not be admitted")
+	}
+}
+
+func TestSafeCacheAdmission(t *testing.T) {
+	newAdmission := func() Admission { return tinylfu.New(64) }
+	safe := NewSafe(int64(8), nil, WithAdmission(newAdmission))
+	sharded := NewSharded(1, int64(8), nil, WithAdmission(newAdmission))
+	for _, c := range []interface {
+		Add(string, Value)
+		Get(string) (Value, bool)
+		Len() int
+	}{safe, sharded} {
+		c.Add("k1", String("v1"))
+		c.Add("k2", String("v2"))
+		for i := 0; i < 2; i++ {
+			c.Get("k1")
+			c.Get("k2")
+		}
+		c.Add("k3", String("v3"))
+		if _, ok := c.Get("k3"); ok {
+			t.Fatalf("one-hit key k3 should not be admitted")
+		}
+		// 未命中也计入访问频次，k4 被频繁读取之后可以挤掉旧记录
+		for i := 0; i < 5; i++ {
+			c.Get("k4")
+		}
+		c.Add("k4", String("v4"))
+		if _, ok := c.Get("k4"); !ok || c.Len() != 2 {
+			t.Fatalf("frequently missed key k4 should be admitted")
+		}
+	}
+}
+
+func TestWithPolicy(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	lru := New(int64(8), callback, WithPolicy(policy.FIFO()))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Get("k1")
+	lru.Add("k3", String("v3"))
+
+	expect := []string{"k1"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s", expect)
+	}
+}
+
+func TestSamplePolicy(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	lru := New(int64(0), callback, WithMaxEntries(3), WithPolicy(policy.Sample(0)))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Pin("k2")
+	lru.Get("k1")
+	lru.Add("k4", String("v4"))
+	lru.Add("k5", String("v5"))
+
+	// k2 被固定，k1 被访问过，采样数大于记录数时精确淘汰最久未访问的 k3 和 k1
+	expect := []string{"k3", "k1"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("expect evicted keys %v but got %v", expect, keys)
+	}
+
+	// 随机采样淘汰不要求 string 类型的 key
+	typed := NewTyped[int, int](0, nil, nil, WithMaxEntries(2), WithPolicy(policy.Sample(0)))
+	typed.Add(1, 1)
+	typed.Add(2, 2)
+	typed.Get(1)
+	typed.Add(3, 3)
+	if typed.Contains(2) || !typed.Contains(1) {
+		t.Fatalf("least recently accessed key 2 should be evicted, got %v", typed.Keys())
+	}
+}
+
+func TestRemove(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	lru := New(int64(0), callback)
+	lru.Add("key1", String("1234"))
+	lru.Add("key2", String("5678"))
+	if !lru.Remove("key1") || lru.Remove("key1") {
+		t.Fatalf("Remove key1 failed")
+	}
+	if _, ok := lru.Get("key1"); ok || lru.Len() != 1 {
+		t.Fatalf("key1 should be removed")
+	}
+	if lru.nbytes != int64(len("key2")+len("5678")) {
+		t.Fatal("expected 8 but got", lru.nbytes)
+	}
+	if !reflect.DeepEqual([]string{"key1"}, keys) {
+		t.Fatalf("Remove should call OnEvicted")
+	}
+}
+
+func TestClear(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	lru := New(int64(0), callback)
+	lru.Add("key1", String("1234"))
+	lru.Add("key2", String("5678"))
+	lru.Clear()
+	if lru.Len() != 0 || lru.nbytes != 0 {
+		t.Fatalf("Clear failed, %d entries and %d bytes left", lru.Len(), lru.nbytes)
+	}
+	if !reflect.DeepEqual([]string{"key1", "key2"}, keys) {
+		t.Fatalf("Clear should call OnEvicted for every entry")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	k1, k2, k3 := "key1", "key2", "k3"
+	v1, v2, v3 := "value1", "value2", "v3"
+	cap := len(k1 + k2 + v1 + v2)
+	lru := New(int64(cap), nil)
+	lru.Add(k1, String(v1))
+	lru.Add(k2, String(v2))
+	if v, ok := lru.Peek(k1); !ok || string(v.(String)) != v1 {
+		t.Fatalf("Peek key1 failed")
+	}
+	lru.Add(k3, String(v3))
+	if lru.Contains(k1) || !lru.Contains(k2) {
+		t.Fatalf("Peek should not change the order of key1")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Get("k1")
+
+	expect := []string{"k1", "k3", "k2"}
+	if keys := lru.Keys(); !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("expect keys equals to %s but got %s", expect, keys)
+	}
+
+	visited := make([]string, 0)
+	lru.Range(func(key string, value Value) bool {
+		visited = append(visited, key)
+		return len(visited) < 2
+	})
+	if !reflect.DeepEqual(expect[:2], visited) {
+		t.Fatalf("Range should stop when fn returns false, got %s", visited)
+	}
+	if keys := lru.Keys(); !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Range should not change the order, got %s", keys)
+	}
+}
+
+func TestStats(t *testing.T) {
+	lru := New(int64(8), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.AddWithTTL("k3", String("v3"), time.Millisecond)
+	lru.Get("k2")
+	lru.Get("k1")
+	time.Sleep(5 * time.Millisecond)
+	lru.Get("k3")
+
+	expect := Stats{Hits: 1, Misses: 2, Adds: 3, Updates: 1, Evictions: 1, Expired: 1, Bytes: 4, MaxBytes: 8}
+	if stats := lru.Stats(); stats != expect {
+		t.Fatalf("expect stats %+v but got %+v", expect, stats)
+	}
+}
+
+func TestResize(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Resize(int64(8))
+	if lru.Contains("k1") || lru.Len() != 2 || lru.nbytes != 8 {
+		t.Fatalf("Resize should evict k1")
+	}
+	lru.Resize(int64(12))
+	lru.Add("k4", String("v4"))
+	if lru.Len() != 3 {
+		t.Fatalf("Resize should allow 3 entries")
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	lru := New(int64(0), nil, WithMaxEntries(2))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	if lru.Contains("k1") || lru.Len() != 2 {
+		t.Fatalf("maxEntries should evict k1")
+	}
+}
+
+func TestEntryOverhead(t *testing.T) {
+	lru := New(int64(0), nil, WithEntryOverhead(EstimatedEntryOverhead))
+	lru.Add("key", String("1"))
+	lru.Add("key", String("111"))
+	if lru.nbytes != int64(len("key")+len("111"))+EstimatedEntryOverhead {
+		t.Fatal("expected overhead to be counted but got", lru.nbytes)
+	}
+	lru.Remove("key")
+	if lru.nbytes != 0 {
+		t.Fatal("expected 0 but got", lru.nbytes)
+	}
+}
+
+func TestTypedCache(t *testing.T) {
+	cache := NewTyped[int, string](int64(2), func(key int, value string) int64 {
+		return 1
+	}, nil)
+	cache.Add(1, "one")
+	cache.Add(2, "two")
+	cache.Add(3, "three")
+	if v, ok := cache.Get(3); !ok || v != "three" {
+		t.Fatalf("cache hit 3=three failed")
+	}
+	if _, ok := cache.Get(1); ok {
+		t.Fatalf("key 1 should be evicted")
+	}
+	if keys := cache.Keys(); !reflect.DeepEqual([]int{3, 2}, keys) {
+		t.Fatalf("expect keys equals to [3 2] but got %v", keys)
+	}
+}
+
+func TestWithWeigher(t *testing.T) {
+	weigher := func(key string, value any) int64 {
+		return int64(len(value.([]int)))
+	}
+	cache := NewTyped[string, any](int64(4), nil, nil, WithWeigher(weigher))
+	cache.Add("k1", []int{1, 2})
+	cache.Add("k2", []int{3, 4})
+	cache.Add("k3", []int{5})
+	if cache.Contains("k1") || cache.nbytes != 3 {
+		t.Fatalf("weigher should be used for byte accounting, got %d bytes", cache.nbytes)
+	}
+}
+
+func TestEvictReason(t *testing.T) {
+	reasons := make([]EvictReason, 0)
+	lru := New(int64(8), nil)
+	lru.OnEvictedWithReason = func(key string, value Value, reason EvictReason) {
+		reasons = append(reasons, reason)
+	}
+	lru.Add("k1", String("v1"))
+	lru.Add("k1", String("v2"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.AddWithTTL("k4", String("v4"), time.Millisecond)
+	lru.Remove("k3")
+	time.Sleep(5 * time.Millisecond)
+	lru.Get("k4")
+	lru.Add("k5", String("v5"))
+	lru.Clear()
+
+	expect := []EvictReason{Replaced, Capacity, Capacity, Removed, Expired, Cleared}
+	if !reflect.DeepEqual(expect, reasons) {
+		t.Fatalf("expect reasons %v but got %v", expect, reasons)
+	}
+}
+
+func TestOnAddedAndOnUpdated(t *testing.T) {
+	added := make([]string, 0)
+	updated := make([]string, 0)
+	lru := New(int64(0), nil)
+	lru.OnAdded = func(key string, value Value) {
+		added = append(added, key)
+	}
+	lru.OnUpdated = func(key string, oldValue, newValue Value) {
+		updated = append(updated, string(oldValue.(String))+"->"+string(newValue.(String)))
+	}
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k1", String("v3"))
+
+	if !reflect.DeepEqual([]string{"k1", "k2"}, added) {
+		t.Fatalf("Call OnAdded failed, got %s", added)
+	}
+	if !reflect.DeepEqual([]string{"v1->v3"}, updated) {
+		t.Fatalf("Call OnUpdated failed, got %s", updated)
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	lru := NewSafe(int64(0), nil)
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := lru.GetOrCompute("key", func() (Value, error) {
+				atomic.AddInt32(&calls, 1)
+				return String("value"), nil
+			})
+			if err != nil || string(v.(String)) != "value" {
+				t.Errorf("GetOrCompute failed")
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("fn should be called once but got %d", calls)
+	}
+	if _, err := lru.GetOrCompute("err", func() (Value, error) {
+		return nil, fmt.Errorf("failed")
+	}); err == nil || lru.Contains("err") {
+		t.Fatalf("error result should not be cached")
+	}
+	if v, loaded := lru.GetOrAdd("key", String("other")); !loaded || string(v.(String)) != "value" {
+		t.Fatalf("GetOrAdd should return the cached value")
+	}
+}
+
+func TestTouch(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("k1", String("v1"), 20*time.Millisecond)
+	lru.Add("k2", String("v2"))
+	time.Sleep(10 * time.Millisecond)
+	if !lru.Touch("k1") || lru.Touch("unknown") {
+		t.Fatalf("Touch failed")
+	}
+	if keys := lru.Keys(); !reflect.DeepEqual([]string{"k1", "k2"}, keys) {
+		t.Fatalf("Touch should move k1 to front, got %s", keys)
+	}
+	time.Sleep(15 * time.Millisecond)
+	if !lru.Contains("k1") {
+		t.Fatalf("Touch should refresh the ttl of k1")
+	}
+	if !lru.Persist("k1") {
+		t.Fatalf("Persist failed")
+	}
+	time.Sleep(25 * time.Millisecond)
+	if !lru.Contains("k1") {
+		t.Fatalf("k1 should never expire after Persist")
+	}
+	lru.Expire("k2", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if lru.Contains("k2") {
+		t.Fatalf("k2 should be expired")
+	}
+}
+
+func TestRemoveOldestN(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	if key, v, ok := lru.GetOldest(); !ok || key != "k1" || string(v.(String)) != "v1" {
+		t.Fatalf("GetOldest failed")
+	}
+	if n := lru.RemoveOldestN(2); n != 2 || !reflect.DeepEqual([]string{"k3"}, lru.Keys()) {
+		t.Fatalf("RemoveOldestN(2) failed")
+	}
+	if n := lru.RemoveOldestN(5); n != 1 || lru.Len() != 0 {
+		t.Fatalf("RemoveOldestN(5) should remove the last entry")
+	}
+	if _, _, ok := lru.GetOldest(); ok {
+		t.Fatalf("GetOldest on empty cache should fail")
+	}
+}
+
+func TestPin(t *testing.T) {
+	lru := New(int64(8), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	if !lru.Pin("k1") || lru.Pin("unknown") {
+		t.Fatalf("Pin failed")
+	}
+	lru.Add("k3", String("v3"))
+	if !lru.Contains("k1") || lru.Contains("k2") {
+		t.Fatalf("pinned k1 should not be evicted")
+	}
+	lru.Pin("k3")
+	lru.Add("k3", String("v3v3"))
+	if lru.Len() != 2 || lru.nbytes != 10 {
+		t.Fatalf("all entries are pinned, cache should exceed the limit")
+	}
+	lru.Unpin("k1")
+	if lru.Contains("k1") || !lru.Contains("k3") {
+		t.Fatalf("Unpin should evict k1 down to the limit")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.AddWithTTL("k2", String("v2"), time.Hour)
+	lru.AddWithTTL("k3", String("v3"), time.Millisecond)
+	lru.Pin("k1")
+	lru.Get("k1")
+	time.Sleep(5 * time.Millisecond)
+
+	var buf bytes.Buffer
+	encode := func(v Value) ([]byte, error) { return []byte(v.(String)), nil }
+	if err := lru.Snapshot(&buf, encode); err != nil {
+		t.Fatal(err)
+	}
+	restored := New(int64(0), nil)
+	decode := func(b []byte) (Value, error) { return String(b), nil }
+	if err := restored.Restore(&buf, decode); err != nil {
+		t.Fatal(err)
+	}
+	if keys := restored.Keys(); !reflect.DeepEqual([]string{"k1", "k2"}, keys) {
+		t.Fatalf("Restore should keep the order, got %s", keys)
+	}
+	ele := restored.cache["k2"]
+	if ele.expire.IsZero() || ele.ttl != time.Hour {
+		t.Fatalf("Restore should keep the ttl of k2")
+	}
+	if !restored.cache["k1"].pinned {
+		t.Fatalf("Restore should keep k1 pinned")
+	}
+}
+
+func TestGetEntryInfo(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	created := time.Now()
+	time.Sleep(time.Millisecond)
+	lru.Get("k1")
+	lru.Get("k1")
+	info, ok := lru.GetEntryInfo("k1")
+	if !ok || info.Hits != 2 || info.Size != 4 {
+		t.Fatalf("GetEntryInfo failed, got %+v", info)
+	}
+	if info.Created.After(created) || !info.LastAccess.After(created) {
+		t.Fatalf("GetEntryInfo should track created and last access time, got %+v", info)
+	}
+	if _, ok := lru.GetEntryInfo("unknown"); ok {
+		t.Fatalf("GetEntryInfo of unknown key should fail")
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	lru := New(int64(0), nil, WithMaxEntries(512))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		lru.Add(key, String(key))
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	keys := make([]string, 1024)
+	lru := New(int64(0), nil)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		lru.Add(keys[i], String(keys[i]))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Get(keys[i%len(keys)])
+	}
+}
+
+// BenchmarkGetSample 随机采样淘汰的 Get 只更新访问时间，与 BenchmarkGet 对比
+func BenchmarkGetSample(b *testing.B) {
+	keys := make([]string, 1024)
+	lru := New(int64(0), nil, WithPolicy(policy.Sample(0)))
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		lru.Add(keys[i], String(keys[i]))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Get(keys[i%len(keys)])
+	}
+}
+
+func TestRemoveExpired(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("k1", String("v1"), time.Millisecond)
+	lru.AddWithTTL("k2", String("v2"), time.Millisecond)
+	lru.AddWithTTL("k3", String("v3"), time.Millisecond)
+	lru.AddWithTTL("k4", String("v4"), time.Hour)
+	lru.Add("k5", String("v5"))
+	lru.Remove("k2")
+	lru.Persist("k3")
+	lru.Expire("k5", time.Millisecond)
+	if len(lru.expiry) != 3 {
+		t.Fatalf("expiry index should contain k1, k4 and k5, got %d entries", len(lru.expiry))
+	}
+	time.Sleep(5 * time.Millisecond)
+	if n := lru.RemoveExpired(); n != 2 {
+		t.Fatalf("expected 2 expired entries but got %d", n)
+	}
+	if keys := lru.Keys(); !reflect.DeepEqual([]string{"k4", "k3"}, keys) {
+		t.Fatalf("expected k4 and k3 left but got %s", keys)
+	}
+	if len(lru.expiry) != 1 || lru.expiry[0].key != "k4" {
+		t.Fatalf("expiry index should only contain k4")
+	}
+}
+
+func TestAsyncEviction(t *testing.T) {
+	keys := make([]string, 0)
+	release := make(chan struct{})
+	callback := func(key string, value Value) {
+		<-release
+		keys = append(keys, key)
+	}
+	lru := New(int64(4), callback, WithAsyncEviction(1, Drop))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Add("k4", String("v4"))
+	close(release)
+	lru.Close()
+
+	if stats := lru.Stats(); stats.Evictions != 3 || stats.DroppedEvictions == 0 {
+		t.Fatalf("slow callback should not block Add, got %+v", stats)
+	}
+	if len(keys)+int(lru.Stats().DroppedEvictions) != 3 {
+		t.Fatalf("every eviction should be either dispatched or dropped, got %s", keys)
+	}
+}
+
+func TestShardedClose(t *testing.T) {
+	var evicted int32
+	callback := func(key string, value Value) {
+		atomic.AddInt32(&evicted, 1)
+	}
+	lru := NewSharded(4, int64(0), callback, WithMaxEntries(1), WithAsyncEviction(16, Block))
+	for i := 0; i < 100; i++ {
+		lru.Add(strconv.Itoa(i), String("v"))
+	}
+	lru.Close()
+	if n := atomic.LoadInt32(&evicted); int64(n) != lru.Stats().Evictions || n == 0 {
+		t.Fatalf("Close should wait for every shard's callbacks, got %d of %d", n, lru.Stats().Evictions)
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	var evicted []string
+	c := NewCache(WithMaxBytes(10), WithTTL(time.Millisecond), WithOnEvicted(func(key string, value Value) {
+		evicted = append(evicted, key)
+	}))
+	c.Add("k1", String("1234"))
+	c.Add("k2", String("1234"))
+	if _, ok := c.Get("k1"); ok || !reflect.DeepEqual(evicted, []string{"k1"}) {
+		t.Fatalf("WithMaxBytes and WithOnEvicted should apply, got %v", evicted)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("k2"); ok {
+		t.Fatalf("Add should use the TTL from WithTTL")
+	}
+
+	c = NewCache(WithStats(false))
+	c.Add("k", String("v"))
+	c.Get("k")
+	if s := c.Stats(); s.Hits != 0 || s.Adds != 0 {
+		t.Fatalf("stats should be disabled, got %+v", s)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	if _, err := NewFromConfig(Config{MaxBytes: -1}); err == nil {
+		t.Fatalf("negative MaxBytes should be rejected")
+	}
+	c, err := NewFromConfig(Config{MaxEntries: 1, Policy: policy.FIFO()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("k1", String("1"))
+	c.Add("k2", String("2"))
+	if c.Len() != 1 || c.Contains("k1") {
+		t.Fatalf("config should be applied")
+	}
+}
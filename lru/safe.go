@@ -0,0 +1,231 @@
+package lru
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// SafeCache 并发安全的 lru 缓存，内部持有读写锁，可以直接在多个协程之间共享
+type SafeCache struct {
+	mu sync.RWMutex
+	c  *Cache
+}
+
+// NewSafe 实例化并发安全的 SafeCache
+func NewSafe(maxBytes int64, onEvicted func(string, Value), opts ...Option) *SafeCache {
+	return &SafeCache{c: New(maxBytes, onEvicted, opts...)}
+}
+
+// Add 新增/修改
+func (s *SafeCache) Add(key string, value Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Add(key, value)
+}
+
+// AddWithTTL 新增/修改，记录在 ttl 之后过期
+func (s *SafeCache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.AddWithTTL(key, value, ttl)
+}
+
+// Get 获取 value。
+// 未命中时只持有读锁即可返回（同样计入 Stats 的未命中次数），命中时才需要写锁来更新链表顺序。
+// 配置了准入策略时未命中也需要写锁，以便记录 key 的访问频次
+func (s *SafeCache) Get(key string) (value Value, ok bool) {
+	s.mu.RLock()
+	_, ok = s.c.cache[key]
+	fast := !ok && s.c.Admission == nil
+	if fast {
+		s.c.stats.incr(&s.c.stats.misses)
+	}
+	s.mu.RUnlock()
+	if fast {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Get(key)
+}
+
+// GetOrAdd 在同一把锁内完成查询和写入，返回已缓存的值或写入 value，loaded 表示值是否已存在
+func (s *SafeCache) GetOrAdd(key string, value Value) (actual Value, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.GetOrAdd(key, value)
+}
+
+// GetOrCompute 在同一把锁内完成查询、计算和写入，避免“先查后写”的竞争。
+// fn 执行期间持有锁，因此 fn 应当尽量快，且不能再调用 SafeCache 的方法。
+func (s *SafeCache) GetOrCompute(key string, fn func() (Value, error)) (Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.GetOrCompute(key, fn)
+}
+
+// Touch 刷新记录的访问顺序和过期时间，记录存在时返回 true
+func (s *SafeCache) Touch(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Touch(key)
+}
+
+// Expire 修改记录的有效期为 ttl，记录存在时返回 true
+func (s *SafeCache) Expire(key string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Expire(key, ttl)
+}
+
+// Persist 去掉记录的有效期，记录存在时返回 true
+func (s *SafeCache) Persist(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Persist(key)
+}
+
+// Pin 固定记录，使其不会被淘汰，记录存在时返回 true
+func (s *SafeCache) Pin(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Pin(key)
+}
+
+// Unpin 取消固定，记录存在时返回 true
+func (s *SafeCache) Unpin(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Unpin(key)
+}
+
+// GetEntryInfo 返回记录的元数据，只需要读锁
+func (s *SafeCache) GetEntryInfo(key string) (EntryInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.GetEntryInfo(key)
+}
+
+// Peek 获取 value，但不改变记录的访问顺序，只需要读锁
+func (s *SafeCache) Peek(key string) (value Value, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.Peek(key)
+}
+
+// Contains 判断记录是否存在，但不改变记录的访问顺序，只需要读锁
+func (s *SafeCache) Contains(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.Contains(key)
+}
+
+// Keys 按从最近访问到最久未访问的顺序返回所有未过期的 key
+func (s *SafeCache) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.Keys()
+}
+
+// Range 在读锁内按从最近访问到最久未访问的顺序遍历记录，fn 中不能再调用 SafeCache 的方法
+func (s *SafeCache) Range(fn func(key string, value Value) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.c.Range(fn)
+}
+
+// RemoveOldest 移除 “最近最少使用的值”
+func (s *SafeCache) RemoveOldest() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.RemoveOldest()
+}
+
+// GetOldest 返回下一个将被 RemoveOldest 移除的记录，但不改变访问顺序。
+// 淘汰策略选出记录时会修改策略的状态（例如 CLOCK 的引用位），所以需要持有写锁
+func (s *SafeCache) GetOldest() (key string, value Value, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.GetOldest()
+}
+
+// RemoveOldestN 在同一把锁内批量移除至多 n 条记录，返回实际移除的条数
+func (s *SafeCache) RemoveOldestN(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.RemoveOldestN(n)
+}
+
+// Remove 删除指定的记录，记录存在时返回 true
+func (s *SafeCache) Remove(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Remove(key)
+}
+
+// Clear 清空所有记录
+func (s *SafeCache) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Clear()
+}
+
+// RemoveExpired 删除所有已过期的记录，返回删除的条数
+func (s *SafeCache) RemoveExpired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.RemoveExpired()
+}
+
+// Resize 修改允许使用的最大内存，超出新的限制时立即淘汰记录
+func (s *SafeCache) Resize(maxBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Resize(maxBytes)
+}
+
+// Len 返回缓存的记录数
+func (s *SafeCache) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.Len()
+}
+
+// Bytes 返回当前已使用的内存
+func (s *SafeCache) Bytes() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.nbytes
+}
+
+// Stats 返回缓存的统计信息
+func (s *SafeCache) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.Stats()
+}
+
+// Snapshot 在读锁内把所有未过期的记录写入 w
+func (s *SafeCache) Snapshot(w io.Writer, encode func(Value) ([]byte, error)) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.Snapshot(w, encode)
+}
+
+// Restore 从 r 读取 Snapshot 写入的记录并添加到缓存中
+func (s *SafeCache) Restore(r io.Reader, decode func([]byte) (Value, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Restore(r, decode)
+}
+
+// Close 停止异步派发淘汰回调的协程，参见 WithAsyncEviction
+func (s *SafeCache) Close() {
+	s.c.Close()
+}
+
+// StartJanitor 启动后台清理协程，每隔 interval 主动删除过期记录
+func (s *SafeCache) StartJanitor(interval time.Duration) (stop func()) {
+	return s.c.StartJanitor(interval, &s.mu)
+}
@@ -0,0 +1,64 @@
+package lru
+
+import (
+	"math/rand"
+	"time"
+)
+
+// sampler 配置了 policy.Sampled 的淘汰策略（例如 policy.Sample）时使用的随机采样淘汰，
+// 直接在缓存的记录上采样各自最近一次访问的时间，访问记录时不需要移动链表节点，也不需要通知策略
+type sampler[K comparable, V any] struct {
+	n int
+	// 参与淘汰的记录，被固定的记录不在其中
+	entries []*entry[K, V]
+	rnd     *rand.Rand
+}
+
+func newSampler[K comparable, V any](n int) *sampler[K, V] {
+	return &sampler[K, V]{n: n, rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// add 记录参与淘汰
+func (s *sampler[K, V]) add(e *entry[K, V]) {
+	if e.sampleIndex != 0 {
+		return
+	}
+	s.entries = append(s.entries, e)
+	e.sampleIndex = len(s.entries)
+}
+
+// remove 记录不再参与淘汰，用最后一个元素填补空位，保持 O(1) 删除
+func (s *sampler[K, V]) remove(e *entry[K, V]) {
+	if e.sampleIndex == 0 {
+		return
+	}
+	i, last := e.sampleIndex-1, len(s.entries)-1
+	s.entries[i] = s.entries[last]
+	s.entries[i].sampleIndex = i + 1
+	s.entries[last] = nil
+	s.entries = s.entries[:last]
+	e.sampleIndex = 0
+}
+
+// victim 随机采样 n 条记录，返回其中最久未访问的，记录数不超过 n 时精确查找
+func (s *sampler[K, V]) victim() *entry[K, V] {
+	if len(s.entries) == 0 {
+		return nil
+	}
+	if len(s.entries) <= s.n {
+		best := s.entries[0]
+		for _, e := range s.entries[1:] {
+			if e.accessed.Before(best.accessed) {
+				best = e
+			}
+		}
+		return best
+	}
+	best := s.entries[s.rnd.Intn(len(s.entries))]
+	for i := 1; i < s.n; i++ {
+		if e := s.entries[s.rnd.Intn(len(s.entries))]; e.accessed.Before(best.accessed) {
+			best = e
+		}
+	}
+	return best
+}
@@ -0,0 +1,253 @@
+package lru
+
+import (
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// DefaultShards 默认分片数
+const DefaultShards = 256
+
+// ShardedCache 分片的并发安全 lru 缓存。
+// 按 key 的哈希值把记录分散到多个 SafeCache 上，每个分片拥有独立的锁，
+// 高并发下可以显著减少锁竞争。
+type ShardedCache struct {
+	shards []*SafeCache
+	hash   func(key string) uint64
+}
+
+// NewSharded 实例化 ShardedCache，shards <= 0 时使用 DefaultShards。
+// maxBytes 平均分配给每个分片，为 0 时表示不限制。opts 应用于每个分片（WithMaxBytes 设置的是每个分片的上限），
+// 可以通过 WithShardHash 替换选择分片的哈希函数
+func NewSharded(shards int, maxBytes int64, onEvicted func(string, Value), opts ...Option) *ShardedCache {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+	perShard := maxBytes / int64(shards)
+	if maxBytes != 0 && perShard == 0 {
+		perShard = 1
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	s := &ShardedCache{shards: make([]*SafeCache, shards), hash: o.shardHash}
+	if s.hash == nil {
+		s.hash = xxhash.Sum64String
+	}
+	for i := range s.shards {
+		s.shards[i] = NewSafe(perShard, onEvicted, opts...)
+	}
+	return s
+}
+
+// Resize 修改允许使用的最大内存，平均分配给每个分片
+func (s *ShardedCache) Resize(maxBytes int64) {
+	perShard := maxBytes / int64(len(s.shards))
+	if maxBytes != 0 && perShard == 0 {
+		perShard = 1
+	}
+	for _, sh := range s.shards {
+		sh.Resize(perShard)
+	}
+}
+
+func (s *ShardedCache) shard(key string) *SafeCache {
+	return s.shards[s.hash(key)%uint64(len(s.shards))]
+}
+
+// Add 新增/修改
+func (s *ShardedCache) Add(key string, value Value) {
+	s.shard(key).Add(key, value)
+}
+
+// AddWithTTL 新增/修改，记录在 ttl 之后过期
+func (s *ShardedCache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	s.shard(key).AddWithTTL(key, value, ttl)
+}
+
+// Get 获取 value
+func (s *ShardedCache) Get(key string) (value Value, ok bool) {
+	return s.shard(key).Get(key)
+}
+
+// GetOrAdd 返回已缓存的值或写入 value，loaded 表示值是否已存在
+func (s *ShardedCache) GetOrAdd(key string, value Value) (actual Value, loaded bool) {
+	return s.shard(key).GetOrAdd(key, value)
+}
+
+// GetOrCompute 返回已缓存的值，不存在时调用 fn 计算并写入，只会锁住 key 所在的分片
+func (s *ShardedCache) GetOrCompute(key string, fn func() (Value, error)) (Value, error) {
+	return s.shard(key).GetOrCompute(key, fn)
+}
+
+// Touch 刷新记录的访问顺序和过期时间，记录存在时返回 true
+func (s *ShardedCache) Touch(key string) bool {
+	return s.shard(key).Touch(key)
+}
+
+// Expire 修改记录的有效期为 ttl，记录存在时返回 true
+func (s *ShardedCache) Expire(key string, ttl time.Duration) bool {
+	return s.shard(key).Expire(key, ttl)
+}
+
+// Persist 去掉记录的有效期，记录存在时返回 true
+func (s *ShardedCache) Persist(key string) bool {
+	return s.shard(key).Persist(key)
+}
+
+// Pin 固定记录，使其不会被淘汰，记录存在时返回 true
+func (s *ShardedCache) Pin(key string) bool {
+	return s.shard(key).Pin(key)
+}
+
+// Unpin 取消固定，记录存在时返回 true
+func (s *ShardedCache) Unpin(key string) bool {
+	return s.shard(key).Unpin(key)
+}
+
+// GetEntryInfo 返回记录的元数据
+func (s *ShardedCache) GetEntryInfo(key string) (EntryInfo, bool) {
+	return s.shard(key).GetEntryInfo(key)
+}
+
+// Peek 获取 value，但不改变记录的访问顺序
+func (s *ShardedCache) Peek(key string) (value Value, ok bool) {
+	return s.shard(key).Peek(key)
+}
+
+// Contains 判断记录是否存在，但不改变记录的访问顺序
+func (s *ShardedCache) Contains(key string) bool {
+	return s.shard(key).Contains(key)
+}
+
+// Keys 返回所有分片中未过期的 key，同一分片内按访问顺序排列
+func (s *ShardedCache) Keys() []string {
+	keys := make([]string, 0)
+	for _, sh := range s.shards {
+		keys = append(keys, sh.Keys()...)
+	}
+	return keys
+}
+
+// Range 依次遍历每个分片的记录，fn 返回 false 时停止遍历
+func (s *ShardedCache) Range(fn func(key string, value Value) bool) {
+	next := true
+	for _, sh := range s.shards {
+		sh.Range(func(key string, value Value) bool {
+			next = fn(key, value)
+			return next
+		})
+		if !next {
+			return
+		}
+	}
+}
+
+// RemoveOldest 从占用内存最多的分片中移除 “最近最少使用的值”。
+// 各分片之间没有全局的访问顺序，因此这是一个近似的 lru。
+func (s *ShardedCache) RemoveOldest() {
+	var target *SafeCache
+	var max int64
+	for _, sh := range s.shards {
+		if b := sh.Bytes(); b > max {
+			target, max = sh, b
+		}
+	}
+	if target != nil {
+		target.RemoveOldest()
+	}
+}
+
+// RemoveOldestN 按占用内存比例从每个分片批量移除记录，合计至多 n 条，返回实际移除的条数
+func (s *ShardedCache) RemoveOldestN(n int) int {
+	total := s.Bytes()
+	if total == 0 || n <= 0 {
+		return 0
+	}
+	removed := 0
+	for _, sh := range s.shards {
+		quota := int(int64(n) * sh.Bytes() / total)
+		removed += sh.RemoveOldestN(quota)
+	}
+	// 按比例取整后剩余的部分逐个分片移除，直到没有可以淘汰的记录
+	for removed < n {
+		before := removed
+		for _, sh := range s.shards {
+			if removed == n {
+				break
+			}
+			removed += sh.RemoveOldestN(1)
+		}
+		if removed == before {
+			break
+		}
+	}
+	return removed
+}
+
+// Remove 删除指定的记录，记录存在时返回 true
+func (s *ShardedCache) Remove(key string) bool {
+	return s.shard(key).Remove(key)
+}
+
+// Clear 清空所有分片
+func (s *ShardedCache) Clear() {
+	for _, sh := range s.shards {
+		sh.Clear()
+	}
+}
+
+// RemoveExpired 删除所有分片中已过期的记录，返回删除的条数
+func (s *ShardedCache) RemoveExpired() int {
+	n := 0
+	for _, sh := range s.shards {
+		n += sh.RemoveExpired()
+	}
+	return n
+}
+
+// Len 返回所有分片的记录数之和
+func (s *ShardedCache) Len() int {
+	n := 0
+	for _, sh := range s.shards {
+		n += sh.Len()
+	}
+	return n
+}
+
+// Bytes 返回所有分片已使用的内存之和
+func (s *ShardedCache) Bytes() int64 {
+	var n int64
+	for _, sh := range s.shards {
+		n += sh.Bytes()
+	}
+	return n
+}
+
+// Close 关闭每个分片，停止 WithAsyncEviction 启动的派发协程并等待剩余的回调执行完毕，
+// Close 之后不能再修改缓存
+func (s *ShardedCache) Close() {
+	for _, sh := range s.shards {
+		sh.Close()
+	}
+}
+
+// Stats 返回所有分片统计信息之和
+func (s *ShardedCache) Stats() Stats {
+	var total Stats
+	for _, sh := range s.shards {
+		st := sh.Stats()
+		total.Hits += st.Hits
+		total.Misses += st.Misses
+		total.Adds += st.Adds
+		total.Updates += st.Updates
+		total.Evictions += st.Evictions
+		total.Expired += st.Expired
+		total.DroppedEvictions += st.DroppedEvictions
+		total.Bytes += st.Bytes
+		total.MaxBytes += st.MaxBytes
+	}
+	return total
+}
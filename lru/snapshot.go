@@ -0,0 +1,68 @@
+package lru
+
+import (
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// snapshotEntry 快照中的一条记录
+type snapshotEntry[K comparable] struct {
+	Key    K
+	Value  []byte
+	Expire time.Time
+	TTL    time.Duration
+	Pinned bool
+}
+
+// Snapshot 使用 gob 把所有未过期的记录写入 w，value 由 encode 编码为字节。
+// 记录按从最久未访问到最近访问的顺序写入，Restore 时可以还原访问顺序。
+func (c *TypedCache[K, V]) Snapshot(w io.Writer, encode func(V) ([]byte, error)) error {
+	now := time.Now()
+	entries := make([]snapshotEntry[K], 0, c.ll.Len())
+	for ele := c.ll.Back(); ele != nil; ele = ele.Prev() {
+		if ele.expired(now) {
+			continue
+		}
+		b, err := encode(ele.value)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, snapshotEntry[K]{
+			Key:    ele.key,
+			Value:  b,
+			Expire: ele.expire,
+			TTL:    ele.ttl,
+			Pinned: ele.pinned,
+		})
+	}
+	return gob.NewEncoder(w).Encode(entries)
+}
+
+// Restore 从 r 读取 Snapshot 写入的记录并添加到缓存中，value 由 decode 解码。
+// 已有的同名记录会被覆盖，快照之后已经过期的记录会被跳过。
+func (c *TypedCache[K, V]) Restore(r io.Reader, decode func([]byte) (V, error)) error {
+	var entries []snapshotEntry[K]
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if !e.Expire.IsZero() && now.After(e.Expire) {
+			continue
+		}
+		v, err := decode(e.Value)
+		if err != nil {
+			return err
+		}
+		c.Add(e.Key, v)
+		if ele, ok := c.cache[e.Key]; ok {
+			ele.ttl = e.TTL
+			c.setExpire(ele, e.Expire)
+			if e.Pinned {
+				c.Pin(e.Key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package lru
+
+import "sync/atomic"
+
+// Stats 缓存的统计信息
+type Stats struct {
+	// 命中次数
+	Hits int64
+	// 未命中次数
+	Misses int64
+	// 新增记录的次数
+	Adds int64
+	// 修改已有记录的次数
+	Updates int64
+	// 因内存不足被淘汰的记录数
+	Evictions int64
+	// 因过期被删除的记录数
+	Expired int64
+	// 异步派发队列已满而被丢弃的淘汰事件数
+	DroppedEvictions int64
+	// 当前已使用的内存
+	Bytes int64
+	// 允许使用的最大内存
+	MaxBytes int64
+}
+
+// counters 统计计数器，使用原子操作维护
+type counters struct {
+	hits, misses, adds, updates, evictions, expired, dropped int64
+	// WithStats(false) 时不统计
+	disabled bool
+}
+
+func (n *counters) incr(p *int64) {
+	if n.disabled {
+		return
+	}
+	atomic.AddInt64(p, 1)
+}
+
+func (n *counters) load() Stats {
+	return Stats{
+		Hits:             atomic.LoadInt64(&n.hits),
+		Misses:           atomic.LoadInt64(&n.misses),
+		Adds:             atomic.LoadInt64(&n.adds),
+		Updates:          atomic.LoadInt64(&n.updates),
+		Evictions:        atomic.LoadInt64(&n.evictions),
+		Expired:          atomic.LoadInt64(&n.expired),
+		DroppedEvictions: atomic.LoadInt64(&n.dropped),
+	}
+}
+
+// Stats 返回缓存的统计信息
+func (c *TypedCache[K, V]) Stats() Stats {
+	s := c.stats.load()
+	s.Bytes = c.nbytes
+	s.MaxBytes = c.maxBytes
+	return s
+}
@@ -0,0 +1,618 @@
+package lru
+
+import (
+	"go-cache/policy"
+	"sync"
+	"time"
+)
+
+// TypedCache 泛型版本的 lru 缓存，key 可以是任意可比较的类型，value 不需要装箱为接口。
+// 每条记录占用的内存由 sizer 计算。
+type TypedCache[K comparable, V any] struct {
+	// 允许使用的最大内存
+	maxBytes int64
+	// 当前已使用的内存
+	nbytes int64
+	// 允许的最大记录数，0 表示不限制
+	maxEntries int
+	// Add 写入的记录的有效期，0 表示永不过期
+	ttl time.Duration
+	// 每条记录额外占用的内存，计入 nbytes
+	overhead int64
+	// 计算一条记录占用的内存，为 nil 时只计算 overhead
+	sizer func(key K, value V) int64
+	// 侵入式双向链表，头部是最近访问的记录
+	ll *entryList[K, V]
+	// 值是双向链表中节点型指针。
+	cache map[K]*entry[K, V]
+	// 某条记录被移除时的回调函数，可以为 nil。
+	OnEvicted func(key K, value V)
+	// 与 OnEvicted 相同，但额外传入移除的原因；旧值被新值替换时也会调用，可以为 nil。
+	OnEvictedWithReason func(key K, value V, reason EvictReason)
+	// 新增一条记录时的回调函数，可以为 nil。
+	OnAdded func(key K, value V)
+	// 已有记录的值被替换时的回调函数，可以为 nil。
+	OnUpdated func(key K, oldValue, newValue V)
+	// 准入策略，新记录需要挤掉旧记录时由其决定是否写入，可以为 nil。
+	Admission KeyAdmission[K]
+	// 淘汰策略，为 nil 时使用链表本身的 lru 顺序
+	policy KeyPolicy[K]
+	// 淘汰策略实现了 policy.Sampled 时按记录的访问时间随机采样淘汰，此时 policy 为 nil
+	sampler *sampler[K, V]
+	// 过期时间索引
+	expiry expiryHeap[K, V]
+	// 淘汰回调的异步派发器，为 nil 时同步执行回调
+	dispatcher *dispatcher[K, V]
+	// 统计信息
+	stats counters
+}
+
+// EvictReason 记录被移除的原因
+type EvictReason int
+
+const (
+	// Capacity 内存或记录数超出限制被淘汰
+	Capacity EvictReason = iota
+	// Expired 过期被删除
+	Expired
+	// Removed 被 Remove 删除
+	Removed
+	// Replaced 旧值被 Add 写入的新值替换
+	Replaced
+	// Cleared 被 Clear 清空
+	Cleared
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case Capacity:
+		return "capacity"
+	case Expired:
+		return "expired"
+	case Removed:
+		return "removed"
+	case Replaced:
+		return "replaced"
+	case Cleared:
+		return "cleared"
+	}
+	return "unknown"
+}
+
+// KeyAdmission 准入策略接口
+type KeyAdmission[K comparable] interface {
+	// Record 记录一次 key 的访问
+	Record(key K)
+	// Admit 判断 candidate 是否可以挤掉 victim
+	Admit(candidate, victim K) bool
+}
+
+// KeyPolicy 淘汰策略接口，与 policy.EvictionPolicy 相同，但 key 是泛型
+type KeyPolicy[K comparable] interface {
+	OnAdd(key K)
+	OnAccess(key K)
+	OnRemove(key K)
+	Victim() (key K, ok bool)
+}
+
+// 键值对 entry 是双向链表节点的数据类型
+type entry[K comparable, V any] struct {
+	// 链表指针以及所在的链表
+	prev, next *entry[K, V]
+	list       *entryList[K, V]
+
+	key   K
+	value V
+	// 过期时间，零值表示永不过期
+	expire time.Time
+	// 写入时设置的有效期，Touch 时据此刷新过期时间
+	ttl time.Duration
+	// 在 expiryHeap 中的下标加一，0 表示不在堆中
+	heapIndex int
+	// 在 sampler 中的下标加一，0 表示不参与采样
+	sampleIndex int
+	// 被固定的记录不会被淘汰
+	pinned bool
+	// 写入时间、最近一次访问时间和命中次数
+	created, accessed time.Time
+	hits              int64
+}
+
+// expired 判断 entry 在 now 时刻是否已经过期
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expire.IsZero() && now.After(e.expire)
+}
+
+// NewTyped 实例化 TypedCache
+func NewTyped[K comparable, V any](maxBytes int64, sizer func(K, V) int64, onEvicted func(K, V), opts ...Option) *TypedCache[K, V] {
+	o := options{maxBytes: maxBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &TypedCache[K, V]{
+		maxBytes:   o.maxBytes,
+		maxEntries: o.maxEntries,
+		ttl:        o.ttl,
+		overhead:   o.overhead,
+		sizer:      sizer,
+		ll:         newEntryList[K, V](),
+		cache:      make(map[K]*entry[K, V]),
+		OnEvicted:  onEvicted,
+	}
+	c.stats.disabled = o.noStats
+	if o.onEvicted != nil {
+		fn, ok := o.onEvicted.(func(K, V))
+		if !ok {
+			panic("lru: WithOnEvicted requires string keys and Value values")
+		}
+		c.OnEvicted = fn
+	}
+	if o.async {
+		c.startDispatcher(o.queueSize, o.drop)
+	}
+	if o.weigher != nil {
+		if _, ok := any(*new(K)).(string); !ok {
+			panic("lru: weigher requires string keys")
+		}
+		c.sizer = func(key K, value V) int64 {
+			return o.weigher(any(key).(string), value)
+		}
+	}
+	if o.admission != nil {
+		a, ok := o.admission().(KeyAdmission[K])
+		if !ok {
+			panic("lru: WithAdmission requires string keys")
+		}
+		c.Admission = a
+	}
+	if sp, ok := o.policy.(policy.Sampled); ok {
+		c.sampler = newSampler[K, V](sp.Samples())
+	} else if o.policy != nil {
+		p, ok := o.policy.(KeyPolicy[K])
+		if !ok {
+			panic("lru: policy.EvictionPolicy requires string keys")
+		}
+		c.policy = p
+	}
+	return c
+}
+
+// Len 返回双向链表中节点的 len
+func (c *TypedCache[K, V]) Len() int {
+	return c.ll.Len()
+}
+
+// Add 新增/修改，记录的有效期为 WithTTL 设置的时间，默认永不过期
+func (c *TypedCache[K, V]) Add(key K, value V) {
+	c.AddWithTTL(key, value, c.ttl)
+}
+
+// AddWithTTL 新增/修改，记录在 ttl 之后过期，ttl <= 0 表示永不过期
+func (c *TypedCache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	now := time.Now()
+	var expire time.Time
+	if ttl > 0 {
+		expire = now.Add(ttl)
+	}
+	if ele, ok := c.cache[key]; ok {
+		c.nbytes += c.sizeOf(key, value) - c.sizeOf(key, ele.value)
+		old := ele.value
+		ele.value = value
+		ele.ttl = ttl
+		c.setExpire(ele, expire)
+		ele.accessed = now
+		c.stats.incr(&c.stats.updates)
+		c.access(ele)
+		if c.OnEvictedWithReason != nil {
+			c.evicted(key, old, Replaced)
+		}
+		if c.OnUpdated != nil {
+			c.OnUpdated(key, old, value)
+		}
+	} else {
+		if c.Admission != nil {
+			c.Admission.Record(key)
+			if !c.admit(key, c.sizeOf(key, value)) {
+				return
+			}
+		}
+		ele := c.ll.PushFront(&entry[K, V]{
+			key: key, value: value, ttl: ttl, created: now, accessed: now,
+		})
+		c.setExpire(ele, expire)
+		c.cache[key] = ele
+		c.nbytes += c.sizeOf(key, value)
+		c.stats.incr(&c.stats.adds)
+		if c.policy != nil {
+			c.policy.OnAdd(key)
+		}
+		if c.sampler != nil {
+			c.sampler.add(ele)
+		}
+		if c.OnAdded != nil {
+			c.OnAdded(key, value)
+		}
+	}
+	c.shrink()
+}
+
+// Resize 修改允许使用的最大内存，超出新的限制时立即淘汰记录
+func (c *TypedCache[K, V]) Resize(maxBytes int64) {
+	c.maxBytes = maxBytes
+	c.shrink()
+}
+
+// shrink 淘汰记录直到已使用的内存和记录数都不超过限制
+// 剩余的记录全部被固定时无法继续淘汰，允许暂时超出限制
+func (c *TypedCache[K, V]) shrink() {
+	for (c.maxBytes != 0 && c.maxBytes < c.nbytes) ||
+		(c.maxEntries != 0 && c.maxEntries < c.ll.Len()) {
+		ele := c.victim()
+		if ele == nil {
+			return
+		}
+		c.removeElement(ele, Capacity)
+		c.stats.incr(&c.stats.evictions)
+	}
+}
+
+// Get 获取 value，已过期的记录会被惰性删除
+func (c *TypedCache[K, V]) Get(key K) (value V, ok bool) {
+	if c.Admission != nil {
+		c.Admission.Record(key)
+	}
+	if ele, ok := c.cache[key]; ok {
+		now := time.Now()
+		if ele.expired(now) {
+			c.removeElement(ele, Expired)
+			c.stats.incr(&c.stats.expired)
+			c.stats.incr(&c.stats.misses)
+			return value, false
+		}
+		c.stats.incr(&c.stats.hits)
+		ele.accessed = now
+		ele.hits++
+		c.access(ele)
+		return ele.value, true
+	}
+	c.stats.incr(&c.stats.misses)
+	return
+}
+
+// GetOrAdd 返回已缓存的值，不存在时写入 value 并返回，loaded 表示值是否已存在
+func (c *TypedCache[K, V]) GetOrAdd(key K, value V) (actual V, loaded bool) {
+	if v, ok := c.Get(key); ok {
+		return v, true
+	}
+	c.Add(key, value)
+	return value, false
+}
+
+// GetOrCompute 返回已缓存的值，不存在时调用 fn 计算并写入，fn 返回错误时不写入
+func (c *TypedCache[K, V]) GetOrCompute(key K, fn func() (V, error)) (V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	v, err := fn()
+	if err != nil {
+		return v, err
+	}
+	c.Add(key, v)
+	return v, nil
+}
+
+// Touch 刷新记录的访问顺序和过期时间（滑动过期），但不返回 value，记录存在时返回 true
+func (c *TypedCache[K, V]) Touch(key K) bool {
+	ele, ok := c.live(key)
+	if !ok {
+		return false
+	}
+	ele.accessed = time.Now()
+	if ele.ttl > 0 {
+		c.setExpire(ele, ele.accessed.Add(ele.ttl))
+	}
+	c.access(ele)
+	return true
+}
+
+// access 记录一次访问：把节点移到链表头部并通知淘汰策略，
+// 随机采样淘汰只需要调用方已经更新的访问时间，不移动节点
+func (c *TypedCache[K, V]) access(ele *entry[K, V]) {
+	if c.sampler != nil {
+		return
+	}
+	c.ll.MoveToFront(ele)
+	if c.policy != nil {
+		c.policy.OnAccess(ele.key)
+	}
+}
+
+// Expire 修改记录的有效期为 ttl，ttl <= 0 表示永不过期，记录存在时返回 true
+func (c *TypedCache[K, V]) Expire(key K, ttl time.Duration) bool {
+	ele, ok := c.live(key)
+	if !ok {
+		return false
+	}
+	ele.ttl = ttl
+	var expire time.Time
+	if ttl > 0 {
+		expire = time.Now().Add(ttl)
+	}
+	c.setExpire(ele, expire)
+	return true
+}
+
+// Persist 去掉记录的有效期，使其永不过期，记录存在时返回 true
+func (c *TypedCache[K, V]) Persist(key K) bool {
+	return c.Expire(key, 0)
+}
+
+// Pin 固定记录，使其不会被淘汰策略淘汰，但仍然计入内存并且可以过期或被删除，记录存在时返回 true
+func (c *TypedCache[K, V]) Pin(key K) bool {
+	ele, ok := c.live(key)
+	if !ok {
+		return false
+	}
+	if !ele.pinned && c.policy != nil {
+		// 被固定的记录不参与淘汰策略
+		c.policy.OnRemove(key)
+	}
+	if c.sampler != nil {
+		c.sampler.remove(ele)
+	}
+	ele.pinned = true
+	return true
+}
+
+// Unpin 取消固定，记录存在时返回 true
+func (c *TypedCache[K, V]) Unpin(key K) bool {
+	ele, ok := c.live(key)
+	if !ok {
+		return false
+	}
+	if ele.pinned && c.policy != nil {
+		c.policy.OnAdd(key)
+	}
+	if c.sampler != nil {
+		c.sampler.add(ele)
+	}
+	ele.pinned = false
+	c.shrink()
+	return true
+}
+
+// EntryInfo 记录的元数据
+type EntryInfo struct {
+	// 写入时间
+	Created time.Time
+	// 最近一次访问（Get、Touch 或修改）的时间
+	LastAccess time.Time
+	// Get 命中的次数
+	Hits int64
+	// 计入 nbytes 的内存
+	Size int64
+	// 过期时间，零值表示永不过期
+	Expire time.Time
+	// 是否被固定
+	Pinned bool
+}
+
+// GetEntryInfo 返回记录的元数据，不改变访问顺序
+func (c *TypedCache[K, V]) GetEntryInfo(key K) (EntryInfo, bool) {
+	ele, ok := c.live(key)
+	if !ok {
+		return EntryInfo{}, false
+	}
+	return EntryInfo{
+		Created:    ele.created,
+		LastAccess: ele.accessed,
+		Hits:       ele.hits,
+		Size:       c.sizeOf(ele.key, ele.value),
+		Expire:     ele.expire,
+		Pinned:     ele.pinned,
+	}, true
+}
+
+// live 返回未过期的记录
+func (c *TypedCache[K, V]) live(key K) (*entry[K, V], bool) {
+	ele, ok := c.cache[key]
+	if !ok || ele.expired(time.Now()) {
+		return nil, false
+	}
+	return ele, true
+}
+
+// Peek 获取 value，但不改变记录的访问顺序，已过期的记录视为不存在
+func (c *TypedCache[K, V]) Peek(key K) (value V, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		if ele.expired(time.Now()) {
+			return value, false
+		}
+		return ele.value, true
+	}
+	return
+}
+
+// Contains 判断记录是否存在，但不改变记录的访问顺序
+func (c *TypedCache[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+	return ok
+}
+
+// Keys 按从最近访问到最久未访问的顺序返回所有未过期的 key，
+// 淘汰策略实现了 policy.Sampled 时按从最近写入到最早写入的顺序
+func (c *TypedCache[K, V]) Keys() []K {
+	keys := make([]K, 0, c.ll.Len())
+	c.Range(func(key K, value V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Range 按从最近访问到最久未访问的顺序遍历所有未过期的记录，fn 返回 false 时停止遍历，
+// 淘汰策略实现了 policy.Sampled 时按从最近写入到最早写入的顺序。
+// 遍历不改变记录的访问顺序，fn 中不能修改缓存。
+func (c *TypedCache[K, V]) Range(fn func(key K, value V) bool) {
+	now := time.Now()
+	for ele := c.ll.Front(); ele != nil; ele = ele.Next() {
+		if ele.expired(now) {
+			continue
+		}
+		if !fn(ele.key, ele.value) {
+			return
+		}
+	}
+}
+
+// admit 判断新记录写入后超出内存限制时，是否允许它挤掉最久未访问的记录
+func (c *TypedCache[K, V]) admit(key K, size int64) bool {
+	overBytes := c.maxBytes != 0 && c.nbytes+size > c.maxBytes
+	overEntries := c.maxEntries != 0 && c.ll.Len() >= c.maxEntries
+	if !overBytes && !overEntries {
+		return true
+	}
+	ele := c.victim()
+	if ele == nil {
+		return true
+	}
+	return c.Admission.Admit(key, ele.key)
+}
+
+// victim 返回下一个应该被淘汰的节点
+// 被固定的记录会被跳过，全部记录都被固定时返回 nil
+func (c *TypedCache[K, V]) victim() *entry[K, V] {
+	if c.sampler != nil {
+		return c.sampler.victim()
+	}
+	if c.policy == nil {
+		for ele := c.ll.Back(); ele != nil; ele = ele.Prev() {
+			if !ele.pinned {
+				return ele
+			}
+		}
+		return nil
+	}
+	if key, ok := c.policy.Victim(); ok {
+		return c.cache[key]
+	}
+	return nil
+}
+
+// RemoveOldest 移除 “最近最少使用的值”，配置了淘汰策略时移除策略选出的值
+func (c *TypedCache[K, V]) RemoveOldest() {
+	ele := c.victim()
+	if ele != nil {
+		c.removeElement(ele, Capacity)
+		c.stats.incr(&c.stats.evictions)
+	}
+}
+
+// GetOldest 返回下一个将被 RemoveOldest 移除的记录，但不改变访问顺序；
+// 配置了淘汰策略时会像 RemoveOldest 一样修改策略的状态，例如 CLOCK 会清除引用位并移动指针
+func (c *TypedCache[K, V]) GetOldest() (key K, value V, ok bool) {
+	ele := c.victim()
+	if ele == nil {
+		return
+	}
+	return ele.key, ele.value, true
+}
+
+// RemoveOldestN 批量移除至多 n 条记录，返回实际移除的条数
+func (c *TypedCache[K, V]) RemoveOldestN(n int) int {
+	removed := 0
+	for ; removed < n; removed++ {
+		ele := c.victim()
+		if ele == nil {
+			break
+		}
+		c.removeElement(ele, Capacity)
+		c.stats.incr(&c.stats.evictions)
+	}
+	return removed
+}
+
+// Remove 删除指定的记录，记录存在时返回 true
+func (c *TypedCache[K, V]) Remove(key K) bool {
+	if ele, ok := c.cache[key]; ok {
+		c.removeElement(ele, Removed)
+		return true
+	}
+	return false
+}
+
+// Clear 清空所有记录，设置了 OnEvicted 时对每条记录调用一次
+func (c *TypedCache[K, V]) Clear() {
+	for ele := c.ll.Back(); ele != nil; ele = c.ll.Back() {
+		c.removeElement(ele, Cleared)
+	}
+}
+
+// StartJanitor 启动后台清理协程，每隔 interval 主动删除过期记录，
+// 这样即使过期的 key 再也不会被读取，内存也能被回收。
+// TypedCache 本身不是并发安全的，mu 必须是调用方保护该 Cache 所用的锁。
+// 返回的 stop 函数用于停止清理协程，可以重复调用。
+func (c *TypedCache[K, V]) StartJanitor(interval time.Duration, mu sync.Locker) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mu.Lock()
+				c.RemoveExpired()
+				mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// sizeOf 返回一条记录计入 nbytes 的内存
+func (c *TypedCache[K, V]) sizeOf(key K, value V) int64 {
+	if c.sizer == nil {
+		return c.overhead
+	}
+	return c.sizer(key, value) + c.overhead
+}
+
+func (c *TypedCache[K, V]) removeElement(ele *entry[K, V], reason EvictReason) {
+	c.ll.Remove(ele)
+	c.setExpire(ele, time.Time{})
+	delete(c.cache, ele.key)
+	c.nbytes -= c.sizeOf(ele.key, ele.value)
+	if c.policy != nil {
+		c.policy.OnRemove(ele.key)
+	}
+	if c.sampler != nil {
+		c.sampler.remove(ele)
+	}
+	c.evicted(ele.key, ele.value, reason)
+}
+
+// evicted 调用淘汰回调，配置了异步派发时写入队列
+func (c *TypedCache[K, V]) evicted(key K, value V, reason EvictReason) {
+	if c.OnEvicted == nil && c.OnEvictedWithReason == nil {
+		return
+	}
+	if c.dispatcher != nil {
+		c.dispatcher.dispatch(evictEvent[K, V]{key: key, value: value, reason: reason}, &c.stats)
+		return
+	}
+	c.runEvictCallbacks(key, value, reason)
+}
+
+func (c *TypedCache[K, V]) runEvictCallbacks(key K, value V, reason EvictReason) {
+	if c.OnEvicted != nil && reason != Replaced {
+		c.OnEvicted(key, value)
+	}
+	if c.OnEvictedWithReason != nil {
+		c.OnEvictedWithReason(key, value, reason)
+	}
+}
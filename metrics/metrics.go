@@ -0,0 +1,133 @@
+// Package metrics 把 Group 的统计信息导出为 Prometheus 指标
+package metrics
+
+import (
+	gocache "go-cache"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector 实现 prometheus.Collector，通过 Instrument 注册需要导出的 Group：
+//
+//	c := metrics.NewCollector("gocache")
+//	prometheus.MustRegister(c)
+//	g := gocache.NewGroup("scores", 2<<10, getter, c.Instrument())
+type Collector struct {
+	mu     sync.RWMutex
+	groups []*gocache.Group
+
+	loadLatency *prometheus.HistogramVec
+
+	gets          *prometheus.Desc
+	hits          *prometheus.Desc
+	hitRatio      *prometheus.Desc
+	loads         *prometheus.Desc
+	localLoadErrs *prometheus.Desc
+	peerLoads     *prometheus.Desc
+	peerErrors    *prometheus.Desc
+	oversized     *prometheus.Desc
+	suppressed    *prometheus.Desc
+	readRepairs   *prometheus.Desc
+	bytes         *prometheus.Desc
+	items         *prometheus.Desc
+	evictions     *prometheus.Desc
+}
+
+// NewCollector 创建 Collector，指标名以 namespace 为前缀
+func NewCollector(namespace string) *Collector {
+	groupDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, []string{"group"}, nil)
+	}
+	cacheDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, []string{"group", "cache"}, nil)
+	}
+	return &Collector{
+		loadLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "load_duration_seconds",
+			Help:      "Latency of loads from the backing Getter.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"group", "result"}),
+		gets:          groupDesc("gets_total", "Number of Get requests, including cache hits."),
+		hits:          groupDesc("cache_hits_total", "Number of Get requests served from the main or hot cache."),
+		hitRatio:      groupDesc("hit_ratio", "Ratio of cache hits to Get requests."),
+		loads:         groupDesc("loads_total", "Number of cache misses that required a load."),
+		localLoadErrs: groupDesc("local_load_errors_total", "Number of failed loads from the backing Getter."),
+		peerLoads:     groupDesc("peer_loads_total", "Number of values loaded from peers."),
+		peerErrors:    groupDesc("peer_errors_total", "Number of failed loads from peers."),
+		oversized:     groupDesc("oversized_values_total", "Number of values not cached because they exceeded the max value size."),
+		suppressed:    groupDesc("suppressed_loads_total", "Number of loads skipped because a recent error was cached."),
+		readRepairs:   groupDesc("read_repairs_total", "Number of stale replicas repaired on read."),
+		bytes:         cacheDesc("bytes", "Bytes used by the cache."),
+		items:         cacheDesc("items", "Number of entries in the cache."),
+		evictions:     cacheDesc("evictions_total", "Number of entries evicted for lack of space."),
+	}
+}
+
+// Instrument 返回一个 GroupOption，把 Group 注册到 Collector 并统计它的加载延迟
+func (c *Collector) Instrument() gocache.GroupOption {
+	return func(g *gocache.Group) {
+		c.mu.Lock()
+		c.groups = append(c.groups, g)
+		c.mu.Unlock()
+		name := g.Name()
+		gocache.WithLoadObserver(func(d time.Duration, err error) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+			c.loadLatency.WithLabelValues(name, result).Observe(d.Seconds())
+		})(g)
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.loadLatency.Describe(ch)
+	for _, d := range []*prometheus.Desc{
+		c.gets, c.hits, c.hitRatio, c.loads, c.localLoadErrs,
+		c.peerLoads, c.peerErrors, c.oversized, c.suppressed, c.readRepairs, c.bytes, c.items, c.evictions,
+	} {
+		ch <- d
+	}
+}
+
+// Collect 实现 prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.loadLatency.Collect(ch)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, g := range c.groups {
+		name := g.Name()
+		s := g.Stats()
+		counter := func(d *prometheus.Desc, v int64) {
+			ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, float64(v), name)
+		}
+		counter(c.gets, s.Gets)
+		counter(c.hits, s.CacheHits)
+		counter(c.loads, s.Loads)
+		counter(c.localLoadErrs, s.LocalLoadErrs)
+		counter(c.peerLoads, s.PeerLoads)
+		counter(c.peerErrors, s.PeerErrors)
+		counter(c.oversized, s.OversizedValues)
+		counter(c.suppressed, s.SuppressedLoads)
+		counter(c.readRepairs, s.ReadRepairs)
+		var ratio float64
+		if s.Gets > 0 {
+			ratio = float64(s.CacheHits) / float64(s.Gets)
+		}
+		ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, ratio, name)
+
+		for _, tier := range []struct {
+			label string
+			which gocache.CacheType
+		}{{"main", gocache.MainCache}, {"hot", gocache.HotCache}} {
+			cs := g.CacheStats(tier.which)
+			ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(cs.Bytes), name, tier.label)
+			ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(cs.Items), name, tier.label)
+			ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(cs.Evictions), name, tier.label)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	gocache "go-cache"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector("gocache")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	g := gocache.NewGroup("metrics", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			if key == "bad" {
+				return nil, errors.New("boom")
+			}
+			return []byte(key), nil
+		}), c.Instrument())
+	g.Get(context.Background(), "Tom")
+	g.Get(context.Background(), "Tom")
+	g.Get(context.Background(), "bad")
+
+	expected := `
+# HELP gocache_cache_hits_total Number of Get requests served from the main or hot cache.
+# TYPE gocache_cache_hits_total counter
+gocache_cache_hits_total{group="metrics"} 1
+# HELP gocache_gets_total Number of Get requests, including cache hits.
+# TYPE gocache_gets_total counter
+gocache_gets_total{group="metrics"} 3
+# HELP gocache_items Number of entries in the cache.
+# TYPE gocache_items gauge
+gocache_items{cache="hot",group="metrics"} 0
+gocache_items{cache="main",group="metrics"} 1
+# HELP gocache_local_load_errors_total Number of failed loads from the backing Getter.
+# TYPE gocache_local_load_errors_total counter
+gocache_local_load_errors_total{group="metrics"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"gocache_cache_hits_total", "gocache_gets_total", "gocache_items", "gocache_local_load_errors_total"); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(c, "gocache_load_duration_seconds"); n != 2 {
+		t.Fatalf("expected load latency for ok and error results, got %d series", n)
+	}
+}
@@ -0,0 +1,44 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// GetFunc Group.Get 的函数签名
+type GetFunc func(ctx context.Context, key string) (ByteView, error)
+
+// SetFunc Group.Set 的函数签名
+type SetFunc func(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+// GetMiddleware 包装 Group.Get，可以在调用 next 前后加入日志、鉴权、计量、故障注入等逻辑
+type GetMiddleware func(next GetFunc) GetFunc
+
+// SetMiddleware 包装 Group.Set
+type SetMiddleware func(next SetFunc) SetFunc
+
+// WithGetMiddleware 为 Group.Get 添加中间件，先添加的中间件在外层，最先执行
+func WithGetMiddleware(mw ...GetMiddleware) GroupOption {
+	return func(g *Group) {
+		g.getMiddleware = append(g.getMiddleware, mw...)
+	}
+}
+
+// WithSetMiddleware 为 Group.Set 添加中间件，先添加的中间件在外层，最先执行
+func WithSetMiddleware(mw ...SetMiddleware) GroupOption {
+	return func(g *Group) {
+		g.setMiddleware = append(g.setMiddleware, mw...)
+	}
+}
+
+// buildMiddleware 组合所有中间件，NewGroup 应用完 GroupOption 之后调用
+func (g *Group) buildMiddleware() {
+	g.getFunc = g.traceGet
+	for i := len(g.getMiddleware) - 1; i >= 0; i-- {
+		g.getFunc = g.getMiddleware[i](g.getFunc)
+	}
+	g.setFunc = g.set
+	for i := len(g.setMiddleware) - 1; i >= 0; i-- {
+		g.setFunc = g.setMiddleware[i](g.setFunc)
+	}
+}
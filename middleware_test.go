@@ -0,0 +1,46 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	logging := func(name string) GetMiddleware {
+		return func(next GetFunc) GetFunc {
+			return func(ctx context.Context, key string) (ByteView, error) {
+				calls = append(calls, name+" before")
+				v, err := next(ctx, key)
+				calls = append(calls, name+" after")
+				return v, err
+			}
+		}
+	}
+	denied := errors.New("permission denied")
+	readOnly := func(next SetFunc) SetFunc {
+		return func(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+			return denied
+		}
+	}
+	store := &memStore{m: map[string]string{"Tom": "630"}}
+	gee := NewGroup("middleware", 2<<10, store,
+		WithGetMiddleware(logging("outer"), logging("inner")), WithSetMiddleware(readOnly))
+
+	if v, err := gee.Get(context.Background(), "Tom"); err != nil || v.String() != "630" {
+		t.Fatalf("unexpected result %q %v", v.String(), err)
+	}
+	want := []string{"outer before", "inner before", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("expected %v, got %v", want, calls)
+	}
+	if err := gee.Set(context.Background(), "Tom", []byte("700"), 0); err != denied {
+		t.Fatalf("set middleware should be applied, got %v", err)
+	}
+	if store.m["Tom"] != "630" {
+		t.Fatalf("rejected write should not reach the backend")
+	}
+}
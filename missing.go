@@ -0,0 +1,146 @@
+package go_cache
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// WithMissingKeyFilter 使用布隆过滤器记录数据源中不存在（Getter 返回 ErrNotFound）的 key，
+// 之后再获取这些 key 时直接返回 ErrNotFound 而不访问数据源，适合大量请求不存在的 key 的场景。
+// 与 WithNegativeTTL 相比，每个 key 只占用几个 bit，但存在误判：
+// 约 fpRate 比例的其他 key 也会被当作不存在，直到过滤器每隔 resetInterval 被清空。
+// expectedKeys 为预计记录的 key 数量，Set 和 Remove 会把 key 从过滤器中删除。
+func WithMissingKeyFilter(expectedKeys int, fpRate float64, resetInterval time.Duration) GroupOption {
+	return func(g *Group) {
+		g.missing = newMissingFilter(expectedKeys, fpRate, resetInterval)
+	}
+}
+
+// missingFilter 计数布隆过滤器，计数器使得 key 可以被删除
+type missingFilter struct {
+	mu       sync.Mutex
+	counters []uint8
+	// 哈希函数的个数
+	k        int
+	interval time.Duration
+	resetAt  time.Time
+}
+
+func newMissingFilter(n int, p float64, interval time.Duration) *missingFilter {
+	if n <= 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := int(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	f := &missingFilter{counters: make([]uint8, m), k: k, interval: interval}
+	f.resetAt = time.Now().Add(interval)
+	return f
+}
+
+// indexes 使用双重哈希计算 key 对应的 k 个计数器
+func (f *missingFilter) indexes(key string) []int {
+	h := hashKey(key)
+	h1, h2 := h&math.MaxUint32, h>>32
+	idx := make([]int, f.k)
+	for i := range idx {
+		idx[i] = int((h1 + uint64(i)*h2) % uint64(len(f.counters)))
+	}
+	return idx
+}
+
+// maybeReset 距离上次清空超过 interval 时清空过滤器，调用时需要持有 mu
+func (f *missingFilter) maybeReset() {
+	if f.interval <= 0 || time.Now().Before(f.resetAt) {
+		return
+	}
+	for i := range f.counters {
+		f.counters[i] = 0
+	}
+	f.resetAt = time.Now().Add(f.interval)
+}
+
+// clear 清空过滤器
+func (f *missingFilter) clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.counters {
+		f.counters[i] = 0
+	}
+	f.resetAt = time.Now().Add(f.interval)
+}
+
+func (f *missingFilter) add(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maybeReset()
+	for _, i := range f.indexes(key) {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]++
+		}
+	}
+}
+
+func (f *missingFilter) remove(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.containsLocked(key) {
+		return
+	}
+	for _, i := range f.indexes(key) {
+		// 饱和的计数器无法确定真实值，保持不变
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]--
+		}
+	}
+}
+
+func (f *missingFilter) contains(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maybeReset()
+	return f.containsLocked(key)
+}
+
+func (f *missingFilter) containsLocked(key string) bool {
+	for _, i := range f.indexes(key) {
+		if f.counters[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// knownMissing 判断 key 是否已知在数据源中不存在
+func (g *Group) knownMissing(key string) bool {
+	if g.negativeTTL > 0 {
+		if _, ok := g.negCache.get(key); ok {
+			return true
+		}
+	}
+	return g.missing != nil && g.missing.contains(key)
+}
+
+// recordMissing 记录 key 在数据源中不存在
+func (g *Group) recordMissing(key string) {
+	if g.negativeTTL > 0 {
+		g.negCache.addWithTTL(key, ByteView{}, g.negativeTTL)
+	}
+	if g.missing != nil {
+		g.missing.add(key)
+	}
+}
+
+// forgetMissing 删除 key 不存在的记录
+func (g *Group) forgetMissing(key string) {
+	g.negCache.remove(key)
+	if g.missing != nil {
+		g.missing.remove(key)
+	}
+}
@@ -0,0 +1,60 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMissingFilter(t *testing.T) {
+	f := newMissingFilter(1000, 0.01, 0)
+	for i := 0; i < 1000; i++ {
+		f.add(fmt.Sprintf("missing%d", i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.contains(fmt.Sprintf("missing%d", i)) {
+			t.Fatalf("bloom filter must not have false negatives")
+		}
+	}
+	fp := 0
+	for i := 0; i < 10000; i++ {
+		if f.contains(fmt.Sprintf("present%d", i)) {
+			fp++
+		}
+	}
+	if fp > 300 {
+		t.Fatalf("false positive rate too high: %d/10000", fp)
+	}
+	f.remove("missing0")
+	if f.contains("missing0") {
+		t.Fatalf("removed key should not be reported")
+	}
+}
+
+func TestMissingKeyFilter(t *testing.T) {
+	store := &memStore{m: map[string]string{}}
+	loads := 0
+	gee := NewGroup("missing-filter", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			loads++
+			return store.Get(ctx, key)
+		}), WithMissingKeyFilter(100, 0.01, 20*time.Millisecond))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := gee.Get(ctx, "ghost"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("known missing key should not reach the getter, got %d loads", loads)
+	}
+
+	store.m["ghost"] = "boo"
+	time.Sleep(25 * time.Millisecond)
+	if v, err := gee.Get(ctx, "ghost"); err != nil || v.String() != "boo" {
+		t.Fatalf("filter should be reset periodically, got %q %v", v.String(), err)
+	}
+}
@@ -0,0 +1,169 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-cache/gocachepb"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WithPeerTimeout 每次请求其他节点最多等待 d，超时后视为这个节点失败，换下一个副本或者从本地加载
+func WithPeerTimeout(d time.Duration) GroupOption {
+	return func(g *Group) {
+		g.peerTimeout = d
+	}
+}
+
+// WithPeerRetries 请求其他节点失败后最多重试 n 次，每次重试换下一个副本（只有一个副本时重试同一个节点），
+// 全部失败后从本地加载。默认每个副本请求一次
+func WithPeerRetries(n int) GroupOption {
+	return func(g *Group) {
+		g.peerRetries = n
+	}
+}
+
+// WithPeerHedging 请求其他节点超过 delay 还没有返回时，再向下一个副本发送相同的请求，
+// 使用先返回的结果，避免一个慢节点拉高整个集群的尾延迟。delay <= 0 时使用最近请求延迟的 p95。
+// 需要每个 key 有多个副本（PeerPicker 实现了 ReplicaPicker）
+func WithPeerHedging(delay time.Duration) GroupOption {
+	return func(g *Group) {
+		g.hedging = &hedging{delay: delay}
+	}
+}
+
+// peerAttempts 返回对 n 个副本最多请求的次数
+func (g *Group) peerAttempts(n int) int {
+	if g.peerRetries >= 0 {
+		return g.peerRetries + 1
+	}
+	return n
+}
+
+// fetchFromPeer 请求 peer，配置了 WithPeerHedging 并且 hedge 不为 nil 时，peer 足够慢时再请求 hedge
+func (g *Group) fetchFromPeer(ctx context.Context, peer, hedge PeerGetter, key string) (*gocachepb.GetResponse, error) {
+	if g.peerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.peerTimeout)
+		defer cancel()
+	}
+	delay, ok := g.hedging.after()
+	if hedge == nil || !ok {
+		return g.callPeer(ctx, peer, key)
+	}
+
+	// 返回之后取消另一个仍在进行的请求
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		res *gocachepb.GetResponse
+		err error
+	}
+	results := make(chan result, 2)
+	call := func(peer PeerGetter) {
+		res, err := g.callPeer(ctx, peer, key)
+		results <- result{res, err}
+	}
+	go call(peer)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	pending := 1
+	select {
+	case r := <-results:
+		return r.res, r.err
+	case <-timer.C:
+		go call(hedge)
+		pending++
+	}
+	var r result
+	for ; pending > 0; pending-- {
+		if r = <-results; r.err == nil || errors.Is(r.err, ErrNotFound) {
+			break
+		}
+	}
+	return r.res, r.err
+}
+
+// callPeer 向 peer 发送一次请求，成功时记录延迟，peer 的熔断器断开时返回 errCircuitOpen
+func (g *Group) callPeer(ctx context.Context, peer PeerGetter, key string) (*gocachepb.GetResponse, error) {
+	if !g.breakers.allow(peer) {
+		return nil, errCircuitOpen
+	}
+	start := time.Now()
+	req := &gocachepb.GetRequest{Group: g.name, Key: key}
+	res := &gocachepb.GetResponse{}
+	err := peer.Get(ctx, req, res)
+	g.breakers.record(peer, err)
+	if err != nil {
+		return nil, err
+	}
+	if res.GetFlags()&^knownFlags != 0 {
+		return nil, fmt.Errorf("go-cache: unknown response flags %#x", res.GetFlags())
+	}
+	g.hedging.observe(time.Since(start))
+	return res, nil
+}
+
+// hedgingSamples 计算 p95 使用的最近的请求延迟个数，
+// minHedgingSamples 样本不足时不发送 hedged 请求
+const (
+	hedgingSamples    = 256
+	minHedgingSamples = 20
+)
+
+// hedging 记录最近的请求延迟，决定多久之后发送 hedged 请求
+type hedging struct {
+	// 固定的等待时间，<= 0 时使用 p95
+	delay time.Duration
+
+	mu      sync.Mutex
+	samples [hedgingSamples]time.Duration
+	n       int
+	// 缓存的 p95，dirty 时重新计算
+	p95   time.Duration
+	dirty bool
+}
+
+// after 返回发送 hedged 请求之前需要等待的时间，ok 为 false 时不发送
+func (h *hedging) after() (d time.Duration, ok bool) {
+	if h == nil {
+		return 0, false
+	}
+	if h.delay > 0 {
+		return h.delay, true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.n < minHedgingSamples {
+		return 0, false
+	}
+	if h.dirty {
+		n := h.n
+		if n > hedgingSamples {
+			n = hedgingSamples
+		}
+		sorted := make([]time.Duration, n)
+		copy(sorted, h.samples[:n])
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		h.p95 = sorted[n*95/100]
+		h.dirty = false
+	}
+	return h.p95, true
+}
+
+// observe 记录一次成功请求的延迟
+func (h *hedging) observe(d time.Duration) {
+	if h == nil || h.delay > 0 {
+		return
+	}
+	h.mu.Lock()
+	h.samples[h.n%hedgingSamples] = d
+	h.n++
+	// 每 16 个样本更新一次 p95，样本刚好足够时立即计算
+	if h.n%16 == 0 || h.n == minHedgingSamples {
+		h.dirty = true
+	}
+	h.mu.Unlock()
+}
@@ -0,0 +1,97 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// peerFunc 把函数转换为 PeerGetter
+type peerFunc func(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error
+
+func (f peerFunc) Get(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+	return f(ctx, in, out)
+}
+
+// slowPeer 等待 delay 之后返回 value，ctx 先结束时返回 ctx.Err()
+func slowPeer(delay time.Duration, value string) PeerGetter {
+	return peerFunc(func(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+		select {
+		case <-time.After(delay):
+			out.Value = []byte(value)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+var localGetter = GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+	return []byte("local"), nil
+})
+
+func TestPeerTimeout(t *testing.T) {
+	gee := NewGroup("peer-timeout", 2<<10, localGetter,
+		WithPeers(replicaList{slowPeer(time.Minute, "peer")}), WithPeerTimeout(20*time.Millisecond))
+	start := time.Now()
+	view, err := gee.Get(context.Background(), "Tom")
+	if err != nil || view.String() != "local" {
+		t.Fatalf("slow peer should fall back to local load, got %s %v", view, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("peer request should time out, took %v", d)
+	}
+}
+
+func TestPeerRetries(t *testing.T) {
+	var calls int32
+	flaky := peerFunc(func(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("connection reset")
+		}
+		out.Value = []byte("peer")
+		return nil
+	})
+	gee := NewGroup("peer-retries", 2<<10, localGetter, WithPeers(replicaList{flaky}), WithPeerRetries(2))
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "peer" || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %s after %d calls", view, calls)
+	}
+
+	calls = 0
+	gee = NewGroup("peer-no-retries", 2<<10, localGetter, WithPeers(replicaList{flaky}))
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "local" || calls != 1 {
+		t.Fatalf("single replica should be tried once by default, got %s after %d calls", view, calls)
+	}
+}
+
+func TestPeerHedging(t *testing.T) {
+	gee := NewGroup("peer-hedging", 2<<10, localGetter,
+		WithPeers(replicaList{slowPeer(time.Minute, "slow"), slowPeer(0, "fast")}),
+		WithPeerHedging(10*time.Millisecond))
+	start := time.Now()
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "fast" {
+		t.Fatalf("hedged request should win, got %s", view)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("hedged request should not wait for the slow peer, took %v", d)
+	}
+}
+
+func TestHedgingP95(t *testing.T) {
+	h := &hedging{}
+	for i := 1; i < minHedgingSamples; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+	if _, ok := h.after(); ok {
+		t.Fatalf("should not hedge before enough samples")
+	}
+	for i := minHedgingSamples; i <= 100; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+	if d, ok := h.after(); !ok || d < 90*time.Millisecond || d > 97*time.Millisecond {
+		t.Fatalf("expected p95 around 96ms, got %v %v", d, ok)
+	}
+}
@@ -0,0 +1,173 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// servePeerMultiConcurrency ServePeerMulti 同时加载的最大 key 数
+const servePeerMultiConcurrency = 16
+
+// getMultiFromPeers 把 keys 中由其他节点负责的 key 按节点合并为一次请求，结果写入 result，
+// 返回由本节点负责、需要从本地加载的 key。节点不支持批量请求、请求失败或者对方加载失败的 key
+// 通过 load 逐个加载，会重试其他副本或者从本地加载
+func (g *Group) getMultiFromPeers(ctx context.Context, keys []string, result map[string]ByteView) (local []string, err error) {
+	batches := make(map[PeerGetter][]string)
+	var single []string
+	for _, key := range keys {
+		peers := g.pickPeers(ctx, key)
+		switch {
+		case len(peers) == 0:
+			local = append(local, key)
+		case isBatchPeer(peers[0]):
+			batches[peers[0]] = append(batches[peers[0]], key)
+		default:
+			single = append(single, key)
+		}
+	}
+
+	type batch struct {
+		keys []string
+		res  *gocachepb.GetMultiResponse
+		err  error
+	}
+	list := make([]*batch, 0, len(batches))
+	var wg sync.WaitGroup
+	for peer, keys := range batches {
+		b := &batch{keys: keys}
+		list = append(list, b)
+		wg.Add(1)
+		go func(peer PeerGetter) {
+			defer wg.Done()
+			b.res, b.err = g.fetchMultiFromPeer(ctx, peer, b.keys)
+		}(peer)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, b := range list {
+		if b.err != nil {
+			if !errors.Is(b.err, errCircuitOpen) {
+				g.stats.add(&g.stats.peerErrors, len(b.keys))
+				log.Println("[GeeCache] Failed to get multiple keys from peer", b.err)
+			}
+			single = append(single, b.keys...)
+			continue
+		}
+		failed := make(map[string]bool, len(b.res.GetFailed()))
+		for _, key := range b.res.GetFailed() {
+			failed[key] = true
+		}
+		for _, key := range b.keys {
+			res, found := b.res.GetValues()[key]
+			if failed[key] || (found && res.GetFlags()&^knownFlags != 0) {
+				g.stats.incr(&g.stats.peerErrors)
+				single = append(single, key)
+				continue
+			}
+			g.stats.incr(&g.stats.loads)
+			g.stats.incr(&g.stats.loadsDeduped)
+			g.stats.incr(&g.stats.peerLoads)
+			if !found {
+				continue
+			}
+			value := ByteView{b: res.GetValue()}
+			if res.GetFlags()&uint32(gocachepb.Flag_FLAG_STALE) == 0 {
+				g.populateHotCache(key, value, res.GetTtl().AsDuration(), res.GetVersion())
+			}
+			result[key] = value
+		}
+	}
+
+	for _, key := range single {
+		v, _, err := g.load(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return local, nil
+}
+
+func isBatchPeer(peer PeerGetter) bool {
+	_, ok := peer.(BatchPeerGetter)
+	return ok
+}
+
+// fetchMultiFromPeer 向 peer 发送一次批量请求，peer 的熔断器断开时返回 errCircuitOpen
+func (g *Group) fetchMultiFromPeer(ctx context.Context, peer PeerGetter, keys []string) (*gocachepb.GetMultiResponse, error) {
+	if g.peerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.peerTimeout)
+		defer cancel()
+	}
+	if !g.breakers.allow(peer) {
+		return nil, errCircuitOpen
+	}
+	req := &gocachepb.GetMultiRequest{Group: g.name, Keys: keys}
+	res := &gocachepb.GetMultiResponse{}
+	err := peer.(BatchPeerGetter).GetMulti(ctx, req, res)
+	g.breakers.record(peer, err)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ServePeerMulti 处理其他节点的批量请求，每个 key 与 ServePeer 一样获取。
+// 数据源中不存在的 key 不出现在 res 中，加载失败的 key 放入 res.Failed，
+// 只有 Group 已经关闭或者 ctx 结束时才返回错误
+func (g *Group) ServePeerMulti(ctx context.Context, req *gocachepb.GetMultiRequest, res *gocachepb.GetMultiResponse) error {
+	if atomic.LoadInt32(&g.closed) == 1 {
+		return ErrGroupClosed
+	}
+	res.Values = make(map[string]*gocachepb.GetResponse, len(req.GetKeys()))
+	res.Failed = nil
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, servePeerMultiConcurrency)
+	)
+	seen := make(map[string]bool, len(req.GetKeys()))
+	for _, key := range req.GetKeys() {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			v := &gocachepb.GetResponse{}
+			err := g.ServePeer(ctx, key, v)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				res.Values[key] = v
+			case !errors.Is(err, ErrNotFound):
+				res.Failed = append(res.Failed, key)
+			}
+		}(key)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	sort.Strings(res.Failed)
+	return nil
+}
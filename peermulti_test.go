@@ -0,0 +1,110 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"reflect"
+	"testing"
+)
+
+// batchPeer 在 fakePeer 的基础上支持批量请求，"remoteMissing" 不存在，"remoteFail" 加载失败
+type batchPeer struct {
+	*fakePeer
+	calls [][]string
+	err   error
+}
+
+func (p *batchPeer) PickPeer(key string) (PeerGetter, bool) {
+	_, ok := p.fakePeer.PickPeer(key)
+	return p, ok
+}
+
+func (p *batchPeer) GetMulti(ctx context.Context, in *gocachepb.GetMultiRequest, out *gocachepb.GetMultiResponse) error {
+	p.calls = append(p.calls, in.GetKeys())
+	if p.err != nil {
+		return p.err
+	}
+	out.Values = make(map[string]*gocachepb.GetResponse)
+	for _, key := range in.GetKeys() {
+		switch key {
+		case "remoteMissing":
+		case "remoteFail":
+			out.Failed = append(out.Failed, key)
+		default:
+			out.Values[key] = &gocachepb.GetResponse{Value: []byte("batch:" + key)}
+		}
+	}
+	return nil
+}
+
+func TestGetMultiBatchPeer(t *testing.T) {
+	peer := &batchPeer{fakePeer: &fakePeer{}}
+	getter := &batchGetter{}
+	gee := NewGroup("peers-batch", 2<<10, getter, WithPeers(peer))
+
+	keys := []string{"Tom", "remote1", "remote2", "remoteMissing", "remoteFail"}
+	values, err := gee.GetMulti(context.Background(), keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Tom":        "630",
+		"remote1":    "batch:remote1",
+		"remote2":    "batch:remote2",
+		"remoteFail": "peer:remoteFail",
+	}
+	got := make(map[string]string, len(values))
+	for key, v := range values {
+		got[key] = v.String()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if len(peer.calls) != 1 || len(peer.calls[0]) != 4 {
+		t.Fatalf("keys owned by the peer should be fetched in one request, got %v", peer.calls)
+	}
+	if peer.gets != 1 {
+		t.Fatalf("only the failed key should be fetched on its own, got %d gets", peer.gets)
+	}
+	if len(getter.calls) != 1 || !reflect.DeepEqual(getter.calls[0], []string{"Tom"}) {
+		t.Fatalf("only own keys should be batch loaded, got %v", getter.calls)
+	}
+
+	// 批量请求失败时逐个请求
+	peer.err = errors.New("connection reset")
+	values, err = gee.GetMulti(context.Background(), []string{"remote3", "remote4"})
+	if err != nil || values["remote3"].String() != "peer:remote3" || values["remote4"].String() != "peer:remote4" {
+		t.Fatalf("failed batch should fall back to single gets, got %v %v", values, err)
+	}
+}
+
+func TestServePeerMulti(t *testing.T) {
+	gee := NewGroup("serve-multi", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			switch key {
+			case "missing":
+				return nil, ErrNotFound
+			case "bad":
+				return nil, errors.New("db down")
+			}
+			return []byte(key), nil
+		}))
+
+	req := &gocachepb.GetMultiRequest{Keys: []string{"a", "missing", "bad", "b", "a"}}
+	res := &gocachepb.GetMultiResponse{}
+	if err := gee.ServePeerMulti(context.Background(), req, res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetValues()) != 2 || string(res.GetValues()["a"].GetValue()) != "a" || string(res.GetValues()["b"].GetValue()) != "b" {
+		t.Fatalf("unexpected values %v", res.GetValues())
+	}
+	if !reflect.DeepEqual(res.GetFailed(), []string{"bad"}) {
+		t.Fatalf("unexpected failed keys %v", res.GetFailed())
+	}
+
+	gee.Close(context.Background())
+	if err := gee.ServePeerMulti(context.Background(), req, res); !errors.Is(err, ErrGroupClosed) {
+		t.Fatalf("closed group should return ErrGroupClosed, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// PeerPicker 根据 key 选择负责它的节点
+type PeerPicker interface {
+	// PickPeer 返回负责 key 的其他节点，key 由本节点负责时 ok 为 false
+	PickPeer(key string) (peer PeerGetter, ok bool)
+}
+
+// PeerGetter 从其他节点获取缓存值的客户端，具体的传输方式（HTTP、gRPC 等）由实现决定，
+// 服务端通过 Group.ServePeer 生成响应。key 不存在时应当返回 ErrNotFound（或包装了该错误的错误），
+// out.Value 归 Group 所有。
+type PeerGetter interface {
+	Get(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error
+}
+
+// ReplicaPicker 可选接口，PeerPicker 同时实现 ReplicaPicker 时每个 key 保存在多个副本上：
+// 缓存未命中时按顺序尝试各个副本，前一个失败时再请求下一个；Group.Set 之后把新值写入其他副本
+type ReplicaPicker interface {
+	// PickReplicas 按一致性哈希的顺序返回负责 key 的副本中除本节点之外的节点，本节点也是副本之一时 self 为 true
+	PickReplicas(key string) (peers []PeerGetter, self bool)
+}
+
+// PeerSetter 可选接口，PeerGetter 同时实现 PeerSetter 时，Group.Set 通过它把新值写入其他副本的缓存，
+// 服务端通过 Group.ServePeerSet 处理
+type PeerSetter interface {
+	Set(ctx context.Context, in *gocachepb.SetRequest) error
+}
+
+// PeerInvalidator 可选接口，PeerGetter 同时实现 PeerInvalidator 时，Group.Remove 通过它
+// 从其他节点的缓存中删除 key，服务端通过 Group.ServePeerInvalidate 处理
+type PeerInvalidator interface {
+	Invalidate(ctx context.Context, in *gocachepb.InvalidateRequest) error
+}
+
+// BatchPeerGetter 可选接口，PeerGetter 同时实现 BatchPeerGetter 时，Group.GetMulti 把由同一个节点负责的
+// 未命中的 key 合并为一次请求，服务端通过 Group.ServePeerMulti 处理
+type BatchPeerGetter interface {
+	GetMulti(ctx context.Context, in *gocachepb.GetMultiRequest, out *gocachepb.GetMultiResponse) error
+}
+
+// WithPeers 缓存未命中时先通过 peers 选择负责 key 的节点并从该节点获取，
+// 选出的是本节点或者请求其他节点失败时，再调用本地的 Getter 加载
+func WithPeers(peers PeerPicker) GroupOption {
+	return func(g *Group) {
+		g.peers = peers
+	}
+}
+
+type peerRequestKey struct{}
+
+// NewPeerContext 标记 ctx 来自其他节点的请求，PeerGetter 的服务端应当使用它调用 Group.Get。
+// Group 收到这样的请求时只从本地加载，避免节点之间对 key 的归属看法不一致时来回转发。
+func NewPeerContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, peerRequestKey{}, true)
+}
+
+// pickPeers 按顺序返回应当请求的其他节点，key 由本节点负责（本节点是副本之一）时返回 nil
+func (g *Group) pickPeers(ctx context.Context, key string) []PeerGetter {
+	if g.peers == nil || ctx.Value(peerRequestKey{}) != nil {
+		return nil
+	}
+	if rp, ok := g.peers.(ReplicaPicker); ok {
+		peers, self := rp.PickReplicas(key)
+		if self {
+			return nil
+		}
+		return peers
+	}
+	if peer, ok := g.peers.PickPeer(key); ok {
+		return []PeerGetter{peer}
+	}
+	return nil
+}
+
+// loadFromPeers 依次请求负责 key 的其他节点，ok 为 false 时应当从本地加载
+func (g *Group) loadFromPeers(ctx context.Context, key string) (value ByteView, ok bool, err error) {
+	peers := g.pickPeers(ctx, key)
+	for i := 0; len(peers) > 0 && i < g.peerAttempts(len(peers)); i++ {
+		var hedge PeerGetter
+		if len(peers) > 1 {
+			hedge = peers[(i+1)%len(peers)]
+		}
+		value, err := g.getFromPeer(ctx, peers[i%len(peers)], hedge, key)
+		// 其他节点返回 ErrNotFound 说明数据源中确实没有，不必再请求其他副本或从本地加载
+		if err == nil || errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+			if err == nil && g.readRepair > 0 && rand.Float64() < g.readRepair {
+				go g.repairReplicas(key)
+			}
+			return value, true, err
+		}
+	}
+	return ByteView{}, false, nil
+}
+
+// replicate 把 Set 写入的新值同步到负责 key 的其他副本，失败时只记录日志，
+// 数据源中已经是新值，副本中的旧值最迟在过期后更新
+func (g *Group) replicate(ctx context.Context, key string, value []byte, ttl time.Duration, version int64) {
+	rp, ok := g.peers.(ReplicaPicker)
+	if !ok || ctx.Value(peerRequestKey{}) != nil {
+		return
+	}
+	peers, _ := rp.PickReplicas(key)
+	req := &gocachepb.SetRequest{Group: g.name, Key: key, Value: value, Version: version}
+	if ttl > 0 {
+		req.Ttl = durationpb.New(ttl)
+	}
+	var wg sync.WaitGroup
+	for _, peer := range peers {
+		ps, ok := peer.(PeerSetter)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(peer PeerGetter) {
+			defer wg.Done()
+			if err := ps.Set(ctx, req); err != nil {
+				g.stats.incr(&g.stats.peerErrors)
+				log.Println("[GeeCache] Failed to replicate to peer", err)
+				if g.handoff != nil {
+					g.handoff.add(peer, req)
+				}
+			}
+		}(peer)
+	}
+	wg.Wait()
+}
+
+// ServePeerSet 处理其他节点同步过来的新值，只更新本节点的缓存，不会写入数据源。
+// req.Hot 为 true 时写入 hotCache，本节点本来就负责 key 时忽略。
+// 本节点已有的值版本不低于 req.Version 时忽略这次写入，避免乱序到达的旧值覆盖新值
+func (g *Group) ServePeerSet(ctx context.Context, req *gocachepb.SetRequest) error {
+	key := req.GetKey()
+	if err := g.checkKey(key); err != nil {
+		return err
+	}
+	mu := g.keyLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	version := req.GetVersion()
+	if req.GetHot() {
+		if len(g.pickPeers(ctx, key)) > 0 && !g.hotCache.newerThan(key, version) {
+			g.addToHotCache(key, ByteView{b: cloneBytes(req.GetValue())}, req.GetTtl().AsDuration(), version)
+		}
+		return nil
+	}
+	if g.mainCache.newerThan(key, version) {
+		return nil
+	}
+	if version == 0 {
+		version = g.nextVersion()
+	}
+	g.observeVersion(version)
+	g.removeLocally(key)
+	ttl := req.GetTtl().AsDuration()
+	if ttl <= 0 {
+		ttl = g.ttl
+	}
+	g.addToCache(&g.mainCache, key, ByteView{b: cloneBytes(req.GetValue())}, ttl, version)
+	g.invalidateDependents(key)
+	return nil
+}
+
+// knownFlags 本节点能够处理的 GetResponse.flags
+const knownFlags = uint32(gocachepb.Flag_FLAG_STALE)
+
+// ServePeer 处理其他节点对 key 的请求，把值、剩余的有效期以及是否过期写入 res，
+// 供 PeerGetter 的服务端使用。请求只会从本地加载，不会再转发给其他节点。
+func (g *Group) ServePeer(ctx context.Context, key string, res *gocachepb.GetResponse) error {
+	view, info, err := g.GetWithInfo(NewPeerContext(ctx), key)
+	if err != nil {
+		return err
+	}
+	res.Value = view.ByteSlice()
+	res.Ttl = nil
+	res.Flags = 0
+	switch {
+	case info.Stale():
+		res.Flags |= uint32(gocachepb.Flag_FLAG_STALE)
+	case info.TTL > 0:
+		res.Ttl = durationpb.New(info.TTL)
+	}
+	// 没有版本的值使用写入时间
+	res.Version = info.Version
+	if res.Version == 0 {
+		res.Version = time.Now().Add(-info.Age).UnixNano()
+	}
+	return nil
+}
+
+// getFromPeer 从其他节点获取 key，hedge 为 hedged 请求发往的节点，访问足够频繁的 key 放入 hotCache
+func (g *Group) getFromPeer(ctx context.Context, peer, hedge PeerGetter, key string) (ByteView, error) {
+	res, err := g.fetchFromPeer(ctx, peer, hedge, key)
+	if errors.Is(err, errCircuitOpen) {
+		return ByteView{}, err
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		g.stats.incr(&g.stats.peerErrors)
+		log.Println("[GeeCache] Failed to get from peer", err)
+		return ByteView{}, err
+	}
+	g.stats.incr(&g.stats.peerLoads)
+	if err != nil {
+		return ByteView{}, err
+	}
+	value := ByteView{b: res.GetValue()}
+	if res.GetFlags()&uint32(gocachepb.Flag_FLAG_STALE) == 0 {
+		g.populateHotCache(key, value, res.GetTtl().AsDuration(), res.GetVersion())
+	}
+	return value, nil
+}
@@ -0,0 +1,229 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// fakePeer 负责所有以 "remote" 开头的 key
+type fakePeer struct {
+	gets  int32
+	err   error
+	ttl   *durationpb.Duration
+	flags uint32
+}
+
+func (p *fakePeer) PickPeer(key string) (PeerGetter, bool) {
+	return p, len(key) >= 6 && key[:6] == "remote"
+}
+
+func (p *fakePeer) Get(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+	atomic.AddInt32(&p.gets, 1)
+	if p.err != nil {
+		return p.err
+	}
+	out.Value = []byte("peer:" + in.GetKey())
+	out.Ttl = p.ttl
+	out.Flags = p.flags
+	return nil
+}
+
+func TestGetFromPeer(t *testing.T) {
+	var loads int32
+	peer := &fakePeer{}
+	gee := NewGroup("peers", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return []byte("local:" + key), nil
+		}), WithPeers(peer))
+
+	view, info, err := gee.GetWithInfo(context.Background(), "remote1")
+	if err != nil || view.String() != "peer:remote1" || info.Source != SourcePeer {
+		t.Fatalf("expected value from peer, got %s %+v %v", view, info, err)
+	}
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "local:Tom" {
+		t.Fatalf("own key should be loaded locally, got %s", view)
+	}
+
+	// 访问频繁之后放入 hotCache，不再请求其他节点
+	gee.Get(context.Background(), "remote1")
+	gee.Get(context.Background(), "remote1")
+	if peer.gets != 2 {
+		t.Fatalf("hot key should be served from hot cache, got %d peer gets", peer.gets)
+	}
+	if _, ok := gee.mainCache.get("remote1"); ok {
+		t.Fatalf("key owned by peer should not be stored in main cache")
+	}
+	if stats := gee.Stats(); stats.PeerLoads != 2 || stats.LocalLoads != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// 来自其他节点的请求只从本地加载
+	if view, _ := gee.Get(NewPeerContext(context.Background()), "remote2"); view.String() != "local:remote2" {
+		t.Fatalf("peer request should be loaded locally, got %s", view)
+	}
+}
+
+func TestGetFromPeerFallback(t *testing.T) {
+	peer := &fakePeer{err: errors.New("connection refused")}
+	gee := NewGroup("peers-fallback", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("local:" + key), nil
+		}), WithPeers(peer))
+
+	if view, _ := gee.Get(context.Background(), "remote1"); view.String() != "local:remote1" {
+		t.Fatalf("should fall back to local getter, got %s", view)
+	}
+	if stats := gee.Stats(); stats.PeerErrors != 1 || stats.LocalLoads != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	peer.err = ErrNotFound
+	if _, err := gee.Get(context.Background(), "remote2"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ErrNotFound from peer should be returned, got %v", err)
+	}
+	if stats := gee.Stats(); stats.LocalLoads != 1 {
+		t.Fatalf("ErrNotFound from peer should not fall back, got %+v", stats)
+	}
+}
+
+func TestGetMultiWithPeers(t *testing.T) {
+	peer := &fakePeer{}
+	getter := &batchGetter{}
+	gee := NewGroup("peers-multi", 2<<10, getter, WithPeers(peer))
+
+	values, err := gee.GetMulti(context.Background(), []string{"Tom", "remote1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["remote1"].String() != "peer:remote1" || values["Tom"].Len() == 0 {
+		t.Fatalf("unexpected values %v", values)
+	}
+	if len(getter.calls) != 1 || len(getter.calls[0]) != 1 {
+		t.Fatalf("only own keys should be batch loaded, got %v", getter.calls)
+	}
+}
+
+func TestGetFromPeerMetadata(t *testing.T) {
+	peer := &fakePeer{ttl: durationpb.New(time.Second)}
+	gee := NewGroup("peers-metadata", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("local:" + key), nil
+		}), WithPeers(peer), WithTTL(time.Minute))
+
+	gee.Get(context.Background(), "remote1")
+	_, info, _ := gee.GetWithInfo(context.Background(), "remote1")
+	if info.Source != SourcePeer || info.TTL <= 0 || info.TTL > time.Second {
+		t.Fatalf("hot cache should use the shorter TTL from peer, got %+v", info)
+	}
+
+	peer.flags = uint32(gocachepb.Flag_FLAG_STALE)
+	for i := 0; i < 3; i++ {
+		gee.Get(context.Background(), "remote2")
+	}
+	if _, ok := gee.hotCache.get("remote2"); ok {
+		t.Fatalf("stale value from peer should not be cached")
+	}
+
+	peer.flags = 1 << 10
+	if view, _ := gee.Get(context.Background(), "remote3"); view.String() != "local:remote3" {
+		t.Fatalf("response with unknown flags should be discarded, got %s", view)
+	}
+}
+
+func TestServePeer(t *testing.T) {
+	gee := NewGroup("serve-peer", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}), WithTTL(time.Minute), WithPeers(&fakePeer{}))
+
+	res := &gocachepb.GetResponse{}
+	if err := gee.ServePeer(context.Background(), "remote1", res); err != nil {
+		t.Fatal(err)
+	}
+	if string(res.GetValue()) != "remote1" || res.GetFlags() != 0 {
+		t.Fatalf("peer request should be served locally, got %v", res)
+	}
+	if ttl := res.GetTtl().AsDuration(); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("unexpected TTL %v", ttl)
+	}
+	if v := time.Unix(0, res.GetVersion()); time.Since(v) < 0 || time.Since(v) > time.Second {
+		t.Fatalf("version should be the time the value was cached, got %v", v)
+	}
+}
+
+// fakeReplicas 每个 key 有两个副本：down 总是失败，up 返回值并记录写入
+type fakeReplicas struct {
+	down, up *fakePeer
+	self     bool
+	sets     []*gocachepb.SetRequest
+}
+
+func (r *fakeReplicas) PickPeer(key string) (PeerGetter, bool) {
+	return r.down, !r.self
+}
+
+func (r *fakeReplicas) PickReplicas(key string) ([]PeerGetter, bool) {
+	return []PeerGetter{r.down, &setterPeer{r.up, r}}, r.self
+}
+
+type setterPeer struct {
+	*fakePeer
+	r *fakeReplicas
+}
+
+func (p *setterPeer) Set(ctx context.Context, in *gocachepb.SetRequest) error {
+	p.r.sets = append(p.r.sets, in)
+	return nil
+}
+
+func TestReplicas(t *testing.T) {
+	replicas := &fakeReplicas{down: &fakePeer{err: errors.New("connection refused")}, up: &fakePeer{}}
+	store := &memStore{m: make(map[string]string)}
+	gee := NewGroup("replicas", 2<<10, store, WithPeers(replicas))
+
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "peer:Tom" {
+		t.Fatalf("should fall back to the next replica, got %s", view)
+	}
+	if replicas.down.gets != 1 || replicas.up.gets != 1 {
+		t.Fatalf("replicas should be tried in order, got %d %d", replicas.down.gets, replicas.up.gets)
+	}
+
+	if err := gee.Set(context.Background(), "Jack", []byte("v1"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if len(replicas.sets) != 1 || string(replicas.sets[0].GetValue()) != "v1" || replicas.sets[0].GetTtl().AsDuration() != time.Minute {
+		t.Fatalf("Set should be replicated, got %v", replicas.sets)
+	}
+
+	// 本节点是副本之一时从本地加载
+	replicas.self = true
+	store.m["Sam"] = "local"
+	if view, _ := gee.Get(context.Background(), "Sam"); view.String() != "local" {
+		t.Fatalf("replica should load locally, got %s", view)
+	}
+}
+
+func TestServePeerSet(t *testing.T) {
+	gee := NewGroup("serve-peer-set", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte("old"), nil
+		}))
+	gee.Get(context.Background(), "Tom")
+	err := gee.ServePeerSet(context.Background(), &gocachepb.SetRequest{Key: "Tom", Value: []byte("new")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "new" {
+		t.Fatalf("replicated value should replace the cached one, got %s", view)
+	}
+	if err := gee.ServePeerSet(context.Background(), &gocachepb.SetRequest{}); !errors.Is(err, ErrEmptyKey) {
+		t.Fatalf("expected ErrEmptyKey but got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package policy
+
+import "container/ring"
+
+type clockEntry struct {
+	key string
+	// 引用位，被访问后置为 true，指针扫过时给予一次“第二次机会”
+	ref bool
+}
+
+type clock struct {
+	// hand 指向下一个待检查的节点
+	hand  *ring.Ring
+	elems map[string]*ring.Ring
+}
+
+// CLOCK 时钟（第二次机会）淘汰策略，访问时只需要设置引用位
+func CLOCK() EvictionPolicy {
+	return &clock{elems: make(map[string]*ring.Ring)}
+}
+
+func (p *clock) OnAdd(key string) {
+	if _, ok := p.elems[key]; ok {
+		p.OnAccess(key)
+		return
+	}
+	r := ring.New(1)
+	r.Value = &clockEntry{key: key}
+	if p.hand == nil {
+		p.hand = r
+	} else {
+		// 插入到 hand 之前，即最后才会被检查
+		p.hand.Prev().Link(r)
+	}
+	p.elems[key] = r
+}
+
+func (p *clock) OnAccess(key string) {
+	if r, ok := p.elems[key]; ok {
+		r.Value.(*clockEntry).ref = true
+	}
+}
+
+func (p *clock) OnRemove(key string) {
+	r, ok := p.elems[key]
+	if !ok {
+		return
+	}
+	delete(p.elems, key)
+	if len(p.elems) == 0 {
+		p.hand = nil
+		return
+	}
+	if p.hand == r {
+		p.hand = r.Next()
+	}
+	r.Prev().Unlink(1)
+}
+
+func (p *clock) Victim() (string, bool) {
+	if p.hand == nil {
+		return "", false
+	}
+	for {
+		e := p.hand.Value.(*clockEntry)
+		if !e.ref {
+			return e.key, true
+		}
+		e.ref = false
+		p.hand = p.hand.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package policy
+
+import "container/list"
+
+type lfuEntry struct {
+	key  string
+	freq int
+}
+
+type lfu struct {
+	elems   map[string]*list.Element
+	freqs   map[int]*list.List
+	minFreq int
+}
+
+// LFU 淘汰访问频次最低的记录，频次相同时淘汰最久未访问的记录
+func LFU() EvictionPolicy {
+	return &lfu{
+		elems: make(map[string]*list.Element),
+		freqs: make(map[int]*list.List),
+	}
+}
+
+func (p *lfu) OnAdd(key string) {
+	if _, ok := p.elems[key]; ok {
+		p.OnAccess(key)
+		return
+	}
+	p.elems[key] = p.bucket(1).PushFront(&lfuEntry{key: key, freq: 1})
+	p.minFreq = 1
+}
+
+func (p *lfu) OnAccess(key string) {
+	ele, ok := p.elems[key]
+	if !ok {
+		return
+	}
+	e := ele.Value.(*lfuEntry)
+	p.unlink(ele)
+	if _, ok := p.freqs[e.freq]; !ok && p.minFreq == e.freq {
+		p.minFreq++
+	}
+	e.freq++
+	p.elems[key] = p.bucket(e.freq).PushFront(e)
+}
+
+func (p *lfu) OnRemove(key string) {
+	if ele, ok := p.elems[key]; ok {
+		p.unlink(ele)
+		delete(p.elems, key)
+	}
+}
+
+func (p *lfu) Victim() (string, bool) {
+	if len(p.freqs) == 0 {
+		return "", false
+	}
+	l, ok := p.freqs[p.minFreq]
+	if !ok {
+		p.minFreq = 0
+		for f := range p.freqs {
+			if p.minFreq == 0 || f < p.minFreq {
+				p.minFreq = f
+			}
+		}
+		l = p.freqs[p.minFreq]
+	}
+	return l.Back().Value.(*lfuEntry).key, true
+}
+
+func (p *lfu) unlink(ele *list.Element) {
+	e := ele.Value.(*lfuEntry)
+	l := p.freqs[e.freq]
+	l.Remove(ele)
+	if l.Len() == 0 {
+		delete(p.freqs, e.freq)
+	}
+}
+
+func (p *lfu) bucket(freq int) *list.List {
+	l, ok := p.freqs[freq]
+	if !ok {
+		l = list.New()
+		p.freqs[freq] = l
+	}
+	return l
+}
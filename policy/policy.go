@@ -0,0 +1,82 @@
+package policy
+
+import "container/list"
+
+// EvictionPolicy 缓存淘汰策略接口，只负责维护 key 的淘汰顺序，不保存 value。
+// 缓存在新增、访问、删除记录时通知策略，需要腾出内存时向策略询问淘汰哪个 key。
+// 实现不需要是并发安全的，由缓存负责加锁。
+type EvictionPolicy interface {
+	// OnAdd 新增了一条记录
+	OnAdd(key string)
+	// OnAccess 访问或修改了一条已有的记录
+	OnAccess(key string)
+	// OnRemove 删除了一条记录（包括被淘汰）
+	OnRemove(key string)
+	// Victim 返回下一个应该被淘汰的 key，没有记录时 ok 为 false
+	Victim() (key string, ok bool)
+}
+
+// Sampled 可选接口，实现该接口的策略按每条记录最近一次访问的时间随机采样 Samples 条淘汰。
+// lru 缓存遇到该接口时直接在自己的记录上采样，访问记录时只更新时间戳，
+// 既不移动链表节点也不调用策略的方法
+type Sampled interface {
+	Samples() int
+}
+
+// keyList 双向链表 + 字典，作为 lru 和 fifo 的公共实现
+type keyList struct {
+	ll    *list.List
+	elems map[string]*list.Element
+}
+
+func newKeyList() keyList {
+	return keyList{ll: list.New(), elems: make(map[string]*list.Element)}
+}
+
+func (l *keyList) OnAdd(key string) {
+	if ele, ok := l.elems[key]; ok {
+		l.ll.MoveToFront(ele)
+		return
+	}
+	l.elems[key] = l.ll.PushFront(key)
+}
+
+func (l *keyList) OnRemove(key string) {
+	if ele, ok := l.elems[key]; ok {
+		l.ll.Remove(ele)
+		delete(l.elems, key)
+	}
+}
+
+func (l *keyList) Victim() (string, bool) {
+	if ele := l.ll.Back(); ele != nil {
+		return ele.Value.(string), true
+	}
+	return "", false
+}
+
+type lru struct {
+	keyList
+}
+
+// LRU 淘汰最近最少使用的记录
+func LRU() EvictionPolicy {
+	return &lru{newKeyList()}
+}
+
+func (p *lru) OnAccess(key string) {
+	if ele, ok := p.elems[key]; ok {
+		p.ll.MoveToFront(ele)
+	}
+}
+
+type fifo struct {
+	keyList
+}
+
+// FIFO 淘汰最早写入的记录，访问不影响淘汰顺序
+func FIFO() EvictionPolicy {
+	return &fifo{newKeyList()}
+}
+
+func (p *fifo) OnAccess(key string) {}
@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"strconv"
+	"testing"
+)
+
+func victim(t *testing.T, p EvictionPolicy) string {
+	key, ok := p.Victim()
+	if !ok {
+		t.Fatalf("victim expected")
+	}
+	p.OnRemove(key)
+	return key
+}
+
+func TestLRU(t *testing.T) {
+	p := LRU()
+	p.OnAdd("k1")
+	p.OnAdd("k2")
+	p.OnAccess("k1")
+	if key := victim(t, p); key != "k2" {
+		t.Fatalf("expected k2 but got %s", key)
+	}
+}
+
+func TestFIFO(t *testing.T) {
+	p := FIFO()
+	p.OnAdd("k1")
+	p.OnAdd("k2")
+	p.OnAccess("k1")
+	if key := victim(t, p); key != "k1" {
+		t.Fatalf("expected k1 but got %s", key)
+	}
+}
+
+func TestLFU(t *testing.T) {
+	p := LFU()
+	p.OnAdd("k1")
+	p.OnAdd("k2")
+	p.OnAccess("k1")
+	p.OnAccess("k1")
+	p.OnAccess("k2")
+	if key := victim(t, p); key != "k2" {
+		t.Fatalf("expected k2 but got %s", key)
+	}
+	if key := victim(t, p); key != "k1" {
+		t.Fatalf("expected k1 but got %s", key)
+	}
+	if _, ok := p.Victim(); ok {
+		t.Fatalf("empty policy should have no victim")
+	}
+}
+
+func TestCLOCK(t *testing.T) {
+	p := CLOCK()
+	p.OnAdd("k1")
+	p.OnAdd("k2")
+	p.OnAdd("k3")
+	p.OnAccess("k1")
+	if key := victim(t, p); key != "k2" {
+		t.Fatalf("expected k2 but got %s", key)
+	}
+	if key := victim(t, p); key != "k3" {
+		t.Fatalf("expected k3 but got %s", key)
+	}
+	if key := victim(t, p); key != "k1" {
+		t.Fatalf("expected k1 but got %s", key)
+	}
+}
+
+func TestSample(t *testing.T) {
+	p := Sample(3)
+	p.OnAdd("k1")
+	p.OnAdd("k2")
+	p.OnAdd("k3")
+	p.OnAccess("k1")
+	if key := victim(t, p); key != "k2" {
+		t.Fatalf("expected k2 but got %s", key)
+	}
+	for i := 0; i < 100; i++ {
+		p.OnAdd(strconv.Itoa(i))
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 102; i++ {
+		seen[victim(t, p)] = true
+	}
+	if _, ok := p.Victim(); ok || len(seen) != 102 {
+		t.Fatalf("every key should be evicted exactly once")
+	}
+}
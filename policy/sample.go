@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"math/rand"
+	"time"
+)
+
+// DefaultSamples 随机采样淘汰默认的采样数量，与 Redis 的 maxmemory-samples 相同
+const DefaultSamples = 5
+
+type sampleEntry struct {
+	key string
+	// 最近一次访问的逻辑时钟
+	accessed uint64
+}
+
+type sample struct {
+	n int
+	// 逻辑时钟，每次新增或访问加一
+	clock   uint64
+	entries []sampleEntry
+	// key -> entries 中的下标
+	index map[string]int
+	rnd   *rand.Rand
+}
+
+// Sample 近似 lru 的随机采样淘汰策略（类似 Redis）：
+// 只记录每个 key 最近一次访问的时间，淘汰时随机采样 n 个 key，淘汰其中最久未访问的。
+// 访问时只需要更新时间戳，不需要维护链表，代价是淘汰的精度略低。n <= 0 时使用 DefaultSamples。
+// 返回的策略实现了 Sampled，lru 缓存会直接在自己的记录上采样，不再调用下面的方法。
+func Sample(n int) EvictionPolicy {
+	if n <= 0 {
+		n = DefaultSamples
+	}
+	return &sample{
+		n:     n,
+		index: make(map[string]int),
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (p *sample) Samples() int { return p.n }
+
+func (p *sample) OnAdd(key string) {
+	p.clock++
+	if i, ok := p.index[key]; ok {
+		p.entries[i].accessed = p.clock
+		return
+	}
+	p.index[key] = len(p.entries)
+	p.entries = append(p.entries, sampleEntry{key: key, accessed: p.clock})
+}
+
+func (p *sample) OnAccess(key string) {
+	if i, ok := p.index[key]; ok {
+		p.clock++
+		p.entries[i].accessed = p.clock
+	}
+}
+
+func (p *sample) OnRemove(key string) {
+	i, ok := p.index[key]
+	if !ok {
+		return
+	}
+	// 用最后一个元素填补空位，保持 O(1) 删除
+	last := len(p.entries) - 1
+	p.entries[i] = p.entries[last]
+	p.index[p.entries[i].key] = i
+	p.entries = p.entries[:last]
+	delete(p.index, key)
+}
+
+func (p *sample) Victim() (string, bool) {
+	if len(p.entries) == 0 {
+		return "", false
+	}
+	if len(p.entries) <= p.n {
+		// 记录数不超过采样数量时直接精确查找
+		return p.oldest(p.entries), true
+	}
+	best := p.entries[p.rnd.Intn(len(p.entries))]
+	for i := 1; i < p.n; i++ {
+		e := p.entries[p.rnd.Intn(len(p.entries))]
+		if e.accessed < best.accessed {
+			best = e
+		}
+	}
+	return best.key, true
+}
+
+func (p *sample) oldest(entries []sampleEntry) string {
+	best := entries[0]
+	for _, e := range entries[1:] {
+		if e.accessed < best.accessed {
+			best = e
+		}
+	}
+	return best.key
+}
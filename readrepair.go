@@ -0,0 +1,89 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"go-cache/gocachepb"
+	"log"
+	"sync"
+	"time"
+)
+
+// readRepairTimeout 一次读修复请求各个副本的最长时间
+const readRepairTimeout = 5 * time.Second
+
+// WithReadRepair 每个 key 保存在多个副本上（PeerPicker 实现了 ReplicaPicker）时，
+// 从其他节点加载成功后以 chance 的概率在后台读取 key 的全部副本，
+// 把最新的值（版本最高的）写入值与它不同的副本，修复同步或删除部分失败之后副本之间的差异。
+// 版本由写入时间生成，比较新旧依赖节点之间的时钟大致同步，写入副本需要 PeerGetter 实现 PeerSetter。
+func WithReadRepair(chance float64) GroupOption {
+	return func(g *Group) {
+		g.readRepair = chance
+	}
+}
+
+// repairReplicas 读取 key 在其他节点上的副本，把最新的值写入不一致的副本
+func (g *Group) repairReplicas(key string) {
+	rp, ok := g.peers.(ReplicaPicker)
+	if !ok {
+		return
+	}
+	peers, _ := rp.PickReplicas(key)
+	if len(peers) < 2 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), readRepairTimeout)
+	defer cancel()
+
+	// 读取失败、不存在或者已经过期的副本为 nil，不参与比较
+	replicas := make([]*gocachepb.GetResponse, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer PeerGetter) {
+			defer wg.Done()
+			res := &gocachepb.GetResponse{}
+			err := peer.Get(ctx, &gocachepb.GetRequest{Group: g.name, Key: key}, res)
+			if err == nil && res.GetFlags() == 0 {
+				replicas[i] = res
+			}
+		}(i, peer)
+	}
+	wg.Wait()
+
+	var freshest *gocachepb.GetResponse
+	for _, res := range replicas {
+		if res != nil && (freshest == nil || res.GetVersion() > freshest.GetVersion()) {
+			freshest = res
+		}
+	}
+	if freshest == nil {
+		return
+	}
+	req := &gocachepb.SetRequest{Group: g.name, Key: key, Value: freshest.GetValue(), Ttl: freshest.GetTtl(), Version: freshest.GetVersion()}
+	for i, res := range replicas {
+		if res == nil || bytes.Equal(res.GetValue(), freshest.GetValue()) {
+			continue
+		}
+		ps, ok := peers[i].(PeerSetter)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ps.Set(ctx, req); err != nil {
+				g.stats.incr(&g.stats.peerErrors)
+				log.Println("[GeeCache] Failed to repair replica", err)
+				return
+			}
+			g.stats.incr(&g.stats.readRepairs)
+		}()
+	}
+	wg.Wait()
+
+	// 本节点 hotCache 中的值可能来自旧的副本
+	if v, ok := g.hotCache.get(key); ok && !v.Equal(ByteView{b: freshest.GetValue()}) {
+		g.hotCache.remove(key)
+	}
+}
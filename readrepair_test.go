@@ -0,0 +1,67 @@
+package go_cache
+
+import (
+	"context"
+	"go-cache/gocachepb"
+	"sync"
+	"testing"
+	"time"
+)
+
+// versionedPeer 保存一个带版本的值，Set 替换这个值
+type versionedPeer struct {
+	mu      sync.Mutex
+	value   string
+	version int64
+}
+
+func (p *versionedPeer) Get(ctx context.Context, in *gocachepb.GetRequest, out *gocachepb.GetResponse) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out.Value = []byte(p.value)
+	out.Version = p.version
+	return nil
+}
+
+func (p *versionedPeer) Set(ctx context.Context, in *gocachepb.SetRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.value = string(in.GetValue())
+	p.version = time.Now().UnixNano()
+	return nil
+}
+
+func (p *versionedPeer) get() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value
+}
+
+type replicaList []PeerGetter
+
+func (l replicaList) PickPeer(key string) (PeerGetter, bool)       { return l[0], true }
+func (l replicaList) PickReplicas(key string) ([]PeerGetter, bool) { return l, false }
+
+func TestReadRepair(t *testing.T) {
+	peers := []*versionedPeer{{value: "v1", version: 1}, {value: "v2", version: 2}, {value: "v1", version: 1}}
+	gee := NewGroup("read-repair", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return nil, ErrNotFound
+		}), WithPeers(replicaList{peers[0], peers[1], peers[2]}), WithReadRepair(1))
+
+	if view, _ := gee.Get(context.Background(), "Tom"); view.String() != "v1" {
+		t.Fatalf("value should come from the first replica, got %s", view)
+	}
+	deadline := time.Now().Add(time.Second)
+	for gee.Stats().ReadRepairs < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	for i, p := range peers {
+		if p.get() != "v2" {
+			t.Fatalf("replica %d should be repaired to the freshest value, got %s", i, p.get())
+		}
+	}
+	if n := gee.Stats().ReadRepairs; n != 2 {
+		t.Fatalf("expected 2 repairs, got %d", n)
+	}
+}
@@ -0,0 +1,195 @@
+// Package redis 订阅 Redis 中的失效通知，从本节点的缓存中删除对应的 key 或者清空整个 Group，
+// 使已经通过 Redis 发布失效通知的服务不需要改动就能让进程内的缓存保持一致。
+// 支持 Pub/Sub 频道和 Stream 两种方式，它是一个独立的 module，只有使用 Redis 的程序才会引入 Redis 依赖：
+//
+//	sub := redis.New(client)
+//	go sub.Subscribe(ctx, "cache-invalidations")
+//
+// 每个节点都会收到通知，因此只删除本节点的缓存，不会再通过 Group.Remove 通知其他节点。
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	gocache "go-cache"
+	"log"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// DefaultRetryInterval 连接中断之后重试的默认间隔
+const DefaultRetryInterval = time.Second
+
+// streamBlock 每次读取 Stream 最多阻塞的时间，之后检查 ctx 是否结束
+const streamBlock = time.Second
+
+// Message 一条失效通知，Key 为空时清空整个 Group
+type Message struct {
+	Group string `json:"group"`
+	Key   string `json:"key"`
+}
+
+// Decoder 把 Pub/Sub 消息的内容解码为 Message
+type Decoder func(payload string) (Message, error)
+
+// JSONDecoder 默认的 Decoder，消息内容为 {"group": "scores", "key": "Tom"}
+func JSONDecoder(payload string) (Message, error) {
+	var m Message
+	err := json.Unmarshal([]byte(payload), &m)
+	return m, err
+}
+
+// Subscriber 订阅 Redis 中的失效通知
+type Subscriber struct {
+	client  goredis.UniversalClient
+	decoder Decoder
+	// Stream 中 Group 和 key 所在的字段
+	groupField, keyField string
+	// 读取 Stream 失败之后重试的间隔
+	retryInterval time.Duration
+	// 处理完一条通知之后调用，用于测试和监控
+	onMessage func(m Message, err error)
+}
+
+// Option 构造 Subscriber 时的可选配置
+type Option func(*Subscriber)
+
+// WithDecoder 使用 decoder 代替 JSONDecoder 解码 Pub/Sub 消息
+func WithDecoder(decoder Decoder) Option {
+	return func(s *Subscriber) {
+		s.decoder = decoder
+	}
+}
+
+// WithStreamFields 设置 Stream 中 Group 和 key 所在的字段，默认为 "group" 和 "key"
+func WithStreamFields(group, key string) Option {
+	return func(s *Subscriber) {
+		s.groupField, s.keyField = group, key
+	}
+}
+
+// WithRetryInterval 设置读取 Stream 失败之后重试的间隔，默认为 DefaultRetryInterval
+func WithRetryInterval(d time.Duration) Option {
+	return func(s *Subscriber) {
+		s.retryInterval = d
+	}
+}
+
+// WithOnMessage 每处理完一条通知调用一次 fn，err 不为 nil 表示通知无法解码或者处理失败
+func WithOnMessage(fn func(m Message, err error)) Option {
+	return func(s *Subscriber) {
+		s.onMessage = fn
+	}
+}
+
+// New 使用 client 实例化 Subscriber
+func New(client goredis.UniversalClient, opts ...Option) *Subscriber {
+	s := &Subscriber{
+		client:        client,
+		decoder:       JSONDecoder,
+		groupField:    "group",
+		keyField:      "key",
+		retryInterval: DefaultRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Subscribe 订阅 channels 中的失效通知并处理，阻塞直到 ctx 结束时返回 ctx.Err()。
+// 连接中断期间发布的通知会丢失，需要可靠投递时使用 ConsumeStream
+func (s *Subscriber) Subscribe(ctx context.Context, channels ...string) error {
+	ps := s.client.Subscribe(ctx, channels...)
+	defer ps.Close()
+	// Channel 在连接中断时自动重连并重新订阅
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-ch:
+			m, err := s.decoder(msg.Payload)
+			if err == nil {
+				err = s.apply(m)
+			}
+			s.done(m, err)
+		}
+	}
+}
+
+// ConsumeStream 从 Stream 中读取 ConsumeStream 调用之后写入的失效通知并处理，
+// 阻塞直到 ctx 结束时返回 ctx.Err()。连接中断之后从上一条处理过的通知继续读取
+func (s *Subscriber) ConsumeStream(ctx context.Context, stream string) error {
+	lastID := "$"
+	for ctx.Err() == nil {
+		res, err := s.client.XRead(ctx, &goredis.XReadArgs{
+			Streams: []string{stream, lastID},
+			Count:   100,
+			Block:   streamBlock,
+		}).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, goredis.Nil) {
+				continue
+			}
+			log.Println("[GeeCache] Redis stream read failed, retrying", err)
+			if !sleep(ctx, s.retryInterval) {
+				return ctx.Err()
+			}
+			continue
+		}
+		for _, xs := range res {
+			for _, xm := range xs.Messages {
+				lastID = xm.ID
+				group, _ := xm.Values[s.groupField].(string)
+				key, _ := xm.Values[s.keyField].(string)
+				m := Message{Group: group, Key: key}
+				s.done(m, s.apply(m))
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// apply 删除本节点上的 key 或者清空整个 Group，本节点没有该 Group 时忽略
+func (s *Subscriber) apply(m Message) error {
+	if m.Group == "" {
+		return errors.New("missing group")
+	}
+	g := gocache.GetGroup(m.Group)
+	if g == nil {
+		return nil
+	}
+	if m.Key == "" {
+		g.Purge()
+		return nil
+	}
+	// 其他节点同样会收到通知，不需要再转发
+	return g.Remove(gocache.NewPeerContext(context.Background()), m.Key)
+}
+
+func (s *Subscriber) done(m Message, err error) {
+	if err != nil {
+		log.Printf("[GeeCache] Bad invalidation message %+v: %v", m, err)
+	}
+	if s.onMessage != nil {
+		s.onMessage(m, err)
+	}
+}
+
+// sleep 等待 d，ctx 先结束时返回 false
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
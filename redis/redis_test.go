@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	gocache "go-cache"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func newGroup(name string) *gocache.Group {
+	g := gocache.NewGroup(name, 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	g.Get(context.Background(), "Tom")
+	g.Get(context.Background(), "Jack")
+	return g
+}
+
+func cached(g *gocache.Group, key string) bool {
+	_, err := g.Peek(key)
+	return err == nil
+}
+
+func start(t *testing.T) (*miniredis.Miniredis, *goredis.Client) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func TestSubscribe(t *testing.T) {
+	_, client := start(t)
+	g := newGroup("redis-pubsub")
+	msgs := make(chan error, 16)
+	sub := New(client, WithOnMessage(func(m Message, err error) { msgs <- err }))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- sub.Subscribe(ctx, "invalidations") }()
+
+	publish := func(payload string) error {
+		t.Helper()
+		// 订阅是异步建立的，没有订阅者收到时重新发布
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			n, err := client.Publish(context.Background(), "invalidations", payload).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n > 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("no subscriber")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		select {
+		case err := <-msgs:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Fatalf("message not handled")
+		}
+		return nil
+	}
+
+	if err := publish(`{"group": "redis-pubsub", "key": "Tom"}`); err != nil {
+		t.Fatal(err)
+	}
+	if cached(g, "Tom") || !cached(g, "Jack") {
+		t.Fatalf("only Tom should be removed")
+	}
+	if err := publish(`not json`); err == nil {
+		t.Fatalf("bad message should be reported")
+	}
+	if err := publish(`{"group": "unknown", "key": "Tom"}`); err != nil {
+		t.Fatalf("unknown groups should be ignored, got %v", err)
+	}
+	if err := publish(`{"group": "redis-pubsub"}`); err != nil {
+		t.Fatal(err)
+	}
+	if cached(g, "Jack") {
+		t.Fatalf("group should be purged")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Subscribe should return ctx.Err(), got %v", err)
+	}
+}
+
+func TestConsumeStream(t *testing.T) {
+	_, client := start(t)
+	g := newGroup("redis-stream")
+	msgs := make(chan Message, 16)
+	sub := New(client, WithStreamFields("g", "k"), WithOnMessage(func(m Message, err error) { msgs <- m }))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- sub.ConsumeStream(ctx, "invalidations") }()
+
+	// ConsumeStream 只读取调用之后写入的通知，持续写入直到被处理
+	deadline := time.Now().Add(2 * time.Second)
+	for cached(g, "Tom") {
+		if time.Now().After(deadline) {
+			t.Fatalf("stream entry not handled")
+		}
+		client.XAdd(context.Background(), &goredis.XAddArgs{Stream: "invalidations", Values: map[string]any{"g": "redis-stream", "k": "Tom"}})
+		time.Sleep(5 * time.Millisecond)
+	}
+	if m := <-msgs; m.Group != "redis-stream" || m.Key != "Tom" {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if !cached(g, "Jack") {
+		t.Fatalf("only Tom should be removed")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("ConsumeStream should return ctx.Err(), got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("ConsumeStream should return after ctx is canceled")
+	}
+}
@@ -0,0 +1,212 @@
+// Package consul 基于 Consul 的节点注册与发现，实现了 registry.Registrar 和 registry.Discovery。
+// 每个节点注册为同名服务的一个实例，并附带 TTL 健康检查，节点停止续约后被 Consul 标记为不健康，
+// Watch 只返回健康的实例。它是一个独立的 module，只有使用 Consul 的程序才会引入 Consul 依赖：
+//
+//	reg := consul.New(client)
+//	go reg.Register(ctx, "http://10.0.0.1:8008")
+//	go reg.Watch(ctx, registry.OnChange(func(peers []string) { pool.Set(peers...) }))
+package consul
+
+import (
+	"context"
+	"fmt"
+	"go-cache/registry"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// DefaultService 注册的默认服务名
+const DefaultService = "gocache"
+
+// DefaultTTL 健康检查默认的 TTL，节点每隔 TTL/2 续约一次
+const DefaultTTL = 10 * time.Second
+
+// metaAddr 服务实例的元数据中保存节点完整地址的字段
+const metaAddr = "gocache_addr"
+
+// Registry 基于 Consul 的节点注册与发现
+type Registry struct {
+	client  *api.Client
+	service string
+	ttl     time.Duration
+	// 不健康超过该时间后 Consul 自动注销实例，0 表示不自动注销
+	deregisterAfter time.Duration
+	tags            []string
+	// 请求失败后重试的间隔
+	retryInterval time.Duration
+}
+
+var (
+	_ registry.Registrar = (*Registry)(nil)
+	_ registry.Discovery = (*Registry)(nil)
+)
+
+// Option 构造 Registry 时的可选配置
+type Option func(*Registry)
+
+// WithService 使用 service 代替 DefaultService，同一个集群的节点需要使用相同的服务名
+func WithService(service string) Option {
+	return func(r *Registry) {
+		r.service = service
+	}
+}
+
+// WithTTL 设置健康检查的 TTL，默认为 DefaultTTL
+func WithTTL(ttl time.Duration) Option {
+	return func(r *Registry) {
+		r.ttl = ttl
+	}
+}
+
+// WithDeregisterAfter 实例不健康超过 d 后由 Consul 自动注销，默认为 1 分钟，
+// 避免进程崩溃后留下不健康的实例
+func WithDeregisterAfter(d time.Duration) Option {
+	return func(r *Registry) {
+		r.deregisterAfter = d
+	}
+}
+
+// WithTags 为注册的实例添加标签
+func WithTags(tags ...string) Option {
+	return func(r *Registry) {
+		r.tags = tags
+	}
+}
+
+// New 使用 client 实例化 Registry
+func New(client *api.Client, opts ...Option) *Registry {
+	r := &Registry{
+		client:          client,
+		service:         DefaultService,
+		ttl:             DefaultTTL,
+		deregisterAfter: time.Minute,
+		retryInterval:   time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register 实现 registry.Registrar，注册 addr 并每隔 TTL/2 更新健康检查。
+// Consul 重启等原因导致实例丢失时重新注册，ctx 结束时注销实例并返回 ctx.Err()
+func (r *Registry) Register(ctx context.Context, addr string) error {
+	reg, err := r.registration(addr)
+	if err != nil {
+		return err
+	}
+	agent := r.client.Agent()
+	checkID := "service:" + reg.ID
+	defer agent.ServiceDeregister(reg.ID)
+
+	registered := false
+	ticker := time.NewTicker(r.ttl / 2)
+	defer ticker.Stop()
+	for {
+		if !registered {
+			if err := agent.ServiceRegisterOpts(reg, api.ServiceRegisterOpts{}.WithContext(ctx)); err != nil {
+				log.Printf("[GeeCache] consul registration of %s failed: %v", addr, err)
+			} else {
+				registered = true
+			}
+		}
+		if registered {
+			if err := agent.UpdateTTLOpts(checkID, "", api.HealthPassing, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+				log.Printf("[GeeCache] consul health check of %s failed: %v", addr, err)
+				registered = false
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// registration 根据节点地址生成服务实例的注册信息
+func (r *Registry) registration(addr string) (*api.AgentServiceRegistration, error) {
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		// addr 可能是不带协议的 host:port，例如 gRPC 的地址
+		u = &url.URL{Host: addr}
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("consul: invalid address %q: %v", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("consul: invalid port in %q", addr)
+	}
+	check := &api.AgentServiceCheck{
+		TTL:    r.ttl.String(),
+		Status: api.HealthPassing,
+	}
+	if r.deregisterAfter > 0 {
+		check.DeregisterCriticalServiceAfter = r.deregisterAfter.String()
+	}
+	return &api.AgentServiceRegistration{
+		ID:      r.service + "-" + u.Host,
+		Name:    r.service,
+		Tags:    r.tags,
+		Address: host,
+		Port:    port,
+		Meta:    map[string]string{metaAddr: addr},
+		Check:   check,
+	}, nil
+}
+
+// Watch 实现 registry.Discovery，通过阻塞查询监听服务的健康实例
+func (r *Registry) Watch(ctx context.Context, update func(peers []string)) error {
+	var index uint64
+	for {
+		opts := (&api.QueryOptions{WaitIndex: index, WaitTime: 5 * time.Minute}).WithContext(ctx)
+		entries, meta, err := r.client.Health().Service(r.service, "", true, opts)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Printf("[GeeCache] consul watch on %s failed: %v", r.service, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.retryInterval):
+			}
+			continue
+		}
+		// 阻塞查询超时返回时 index 不变，节点没有变化
+		if index != 0 && meta.LastIndex == index {
+			continue
+		}
+		// index 变小说明 Consul 的状态被重置，需要从头开始
+		if meta.LastIndex < index {
+			index = 0
+		} else {
+			index = meta.LastIndex
+		}
+		update(peersOf(entries))
+	}
+}
+
+// peersOf 从健康的实例中取出节点地址
+func peersOf(entries []*api.ServiceEntry) []string {
+	peers := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if addr := e.Service.Meta[metaAddr]; addr != "" {
+			peers = append(peers, addr)
+			continue
+		}
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		peers = append(peers, net.JoinHostPort(host, strconv.Itoa(e.Service.Port)))
+	}
+	return peers
+}
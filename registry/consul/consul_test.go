@@ -0,0 +1,160 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// fakeAgent 模拟 Consul agent 中用到的几个接口
+type fakeAgent struct {
+	mu       sync.Mutex
+	index    uint64
+	changed  chan struct{}
+	services map[string]*api.AgentServiceRegistration
+	passing  map[string]bool
+}
+
+func newFakeAgent() *fakeAgent {
+	return &fakeAgent{
+		index:    1,
+		changed:  make(chan struct{}),
+		services: make(map[string]*api.AgentServiceRegistration),
+		passing:  make(map[string]bool),
+	}
+}
+
+// bump 在持有锁时调用，唤醒阻塞查询
+func (a *fakeAgent) bump() {
+	a.index++
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch path := r.URL.Path; {
+	case path == "/v1/agent/service/register":
+		var reg api.AgentServiceRegistration
+		json.NewDecoder(r.Body).Decode(&reg)
+		a.services[reg.ID] = &reg
+		a.passing[reg.ID] = reg.Check.Status == api.HealthPassing
+		a.bump()
+	case strings.HasPrefix(path, "/v1/agent/check/update/service:"):
+		id := strings.TrimPrefix(path, "/v1/agent/check/update/service:")
+		if _, ok := a.services[id]; !ok {
+			http.Error(w, "unknown check", http.StatusNotFound)
+			return
+		}
+		if !a.passing[id] {
+			a.passing[id] = true
+			a.bump()
+		}
+	case strings.HasPrefix(path, "/v1/agent/service/deregister/"):
+		delete(a.services, strings.TrimPrefix(path, "/v1/agent/service/deregister/"))
+		a.bump()
+	case strings.HasPrefix(path, "/v1/health/service/"):
+		index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+		for index >= a.index {
+			changed := a.changed
+			a.mu.Unlock()
+			select {
+			case <-changed:
+			case <-time.After(100 * time.Millisecond):
+			case <-r.Context().Done():
+			}
+			a.mu.Lock()
+			if r.Context().Err() != nil {
+				return
+			}
+			if index >= a.index {
+				break
+			}
+		}
+		var entries []*api.ServiceEntry
+		for id, reg := range a.services {
+			if !a.passing[id] {
+				continue
+			}
+			entries = append(entries, &api.ServiceEntry{
+				Node:    &api.Node{Address: "127.0.0.1"},
+				Service: &api.AgentService{ID: id, Service: reg.Name, Address: reg.Address, Port: reg.Port, Meta: reg.Meta},
+			})
+		}
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(a.index, 10))
+		json.NewEncoder(w).Encode(entries)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	agent := newFakeAgent()
+	srv := httptest.NewServer(agent)
+	defer srv.Close()
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := New(client, WithTTL(200*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan []string, 16)
+	go reg.Watch(ctx, func(peers []string) {
+		sort.Strings(peers)
+		updates <- peers
+	})
+	expect := func(want ...string) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case got := <-updates:
+				if len(got) == 0 && len(want) == 0 || reflect.DeepEqual(got, want) {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for peers %v", want)
+			}
+		}
+	}
+	expect()
+
+	ctxA, cancelA := context.WithCancel(ctx)
+	doneA := make(chan error)
+	go func() { doneA <- reg.Register(ctxA, "http://10.0.0.1:8001") }()
+	go reg.Register(ctx, "10.0.0.2:8002")
+	expect("10.0.0.2:8002", "http://10.0.0.1:8001")
+
+	cancelA()
+	if err := <-doneA; err != context.Canceled {
+		t.Fatalf("Register should return ctx.Err(), got %v", err)
+	}
+	expect("10.0.0.2:8002")
+}
+
+func TestRegistration(t *testing.T) {
+	reg := New(nil, WithService("scores"), WithTags("v1"))
+	r, err := reg.registration("http://10.0.0.1:8001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name != "scores" || r.Address != "10.0.0.1" || r.Port != 8001 || r.Meta[metaAddr] != "http://10.0.0.1:8001" {
+		t.Fatalf("unexpected registration %+v", r)
+	}
+	if _, err := reg.registration("no-port"); err == nil {
+		t.Fatalf("address without port should be rejected")
+	}
+}
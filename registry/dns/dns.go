@@ -0,0 +1,139 @@
+// Package dns 定期解析 DNS 记录发现节点，实现了 registry.Discovery，
+// 适合没有 etcd、Consul 或 Kubernetes 的环境，例如位于 headless DNS 记录之后的虚拟机：
+//
+//	d := dns.NewA("gocache.internal", 8008)
+//	go d.Watch(ctx, func(peers []string) { pool.Set(peers...) })
+package dns
+
+import (
+	"context"
+	"go-cache/registry"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultInterval 默认的解析间隔
+const DefaultInterval = 30 * time.Second
+
+// Resolver 解析 DNS 记录，*net.Resolver 实现了该接口
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// Discovery 定期解析 DNS 记录得到全部节点
+type Discovery struct {
+	lookup   func(ctx context.Context) ([]string, error)
+	resolver Resolver
+	interval time.Duration
+	scheme   string
+}
+
+var _ registry.Discovery = (*Discovery)(nil)
+
+// Option 构造 Discovery 时的可选配置
+type Option func(*Discovery)
+
+// WithInterval 设置解析间隔，默认为 DefaultInterval
+func WithInterval(d time.Duration) Option {
+	return func(disc *Discovery) {
+		disc.interval = d
+	}
+}
+
+// WithScheme 设置节点地址的协议，默认为 "http"，与 HTTPPool 的节点地址一致；
+// 为空时得到 "10.0.0.1:8008" 形式的地址，适合 gRPC
+func WithScheme(scheme string) Option {
+	return func(d *Discovery) {
+		d.scheme = scheme
+	}
+}
+
+// WithResolver 使用 r 代替 net.DefaultResolver
+func WithResolver(r Resolver) Option {
+	return func(d *Discovery) {
+		d.resolver = r
+	}
+}
+
+func newDiscovery(opts []Option) *Discovery {
+	d := &Discovery{
+		resolver: net.DefaultResolver,
+		interval: DefaultInterval,
+		scheme:   "http",
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// NewA 解析 host 的 A/AAAA 记录，每个地址加上 port 作为一个节点
+func NewA(host string, port int, opts ...Option) *Discovery {
+	d := newDiscovery(opts)
+	d.lookup = func(ctx context.Context) ([]string, error) {
+		addrs, err := d.resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		peers := make([]string, 0, len(addrs))
+		for _, addr := range addrs {
+			peers = append(peers, d.peer(addr, port))
+		}
+		return peers, nil
+	}
+	return d
+}
+
+// NewSRV 解析 _service._proto.name 的 SRV 记录，端口来自每条记录，
+// service 和 proto 都为空时直接解析 name
+func NewSRV(service, proto, name string, opts ...Option) *Discovery {
+	d := newDiscovery(opts)
+	d.lookup = func(ctx context.Context) ([]string, error) {
+		_, srvs, err := d.resolver.LookupSRV(ctx, service, proto, name)
+		if err != nil {
+			return nil, err
+		}
+		peers := make([]string, 0, len(srvs))
+		for _, srv := range srvs {
+			peers = append(peers, d.peer(strings.TrimSuffix(srv.Target, "."), int(srv.Port)))
+		}
+		return peers, nil
+	}
+	return d
+}
+
+func (d *Discovery) peer(host string, port int) string {
+	peer := net.JoinHostPort(host, strconv.Itoa(port))
+	if d.scheme != "" {
+		peer = d.scheme + "://" + peer
+	}
+	return peer
+}
+
+// Watch 实现 registry.Discovery，节点列表变化时才调用 update。
+// 解析失败时保留上一次的结果，不会因为 DNS 的短暂故障清空一致性哈希环
+func (d *Discovery) Watch(ctx context.Context, update func(peers []string)) error {
+	update = registry.OnChange(update)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		peers, err := d.lookup(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Println("[GeeCache] dns lookup failed:", err)
+		} else {
+			update(peers)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
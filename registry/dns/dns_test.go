@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeResolver struct {
+	mu    sync.Mutex
+	hosts []string
+	srvs  []*net.SRV
+	err   error
+}
+
+func (r *fakeResolver) set(hosts []string, err error) {
+	r.mu.Lock()
+	r.hosts, r.err = hosts, err
+	r.mu.Unlock()
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hosts, r.err
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return "", r.srvs, r.err
+}
+
+func TestWatchA(t *testing.T) {
+	r := &fakeResolver{hosts: []string{"10.0.0.2", "10.0.0.1"}}
+	d := NewA("gocache.internal", 8008, WithResolver(r), WithInterval(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan []string, 16)
+	go d.Watch(ctx, func(peers []string) { updates <- peers })
+	if got := <-updates; !reflect.DeepEqual(got, []string{"http://10.0.0.1:8008", "http://10.0.0.2:8008"}) {
+		t.Fatalf("unexpected peers %v", got)
+	}
+
+	// 解析失败和结果不变时都不应调用 update
+	r.set(nil, errors.New("timeout"))
+	time.Sleep(30 * time.Millisecond)
+	r.set([]string{"10.0.0.1", "10.0.0.2"}, nil)
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case got := <-updates:
+		t.Fatalf("update should not be called without a change, got %v", got)
+	default:
+	}
+
+	r.set([]string{"10.0.0.1"}, nil)
+	select {
+	case got := <-updates:
+		if !reflect.DeepEqual(got, []string{"http://10.0.0.1:8008"}) {
+			t.Fatalf("unexpected peers %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("update should be called after a change")
+	}
+}
+
+func TestWatchSRV(t *testing.T) {
+	r := &fakeResolver{srvs: []*net.SRV{
+		{Target: "node-a.gocache.internal.", Port: 8001},
+		{Target: "node-b.gocache.internal.", Port: 8002},
+	}}
+	d := NewSRV("gocache", "tcp", "internal", WithResolver(r), WithScheme(""))
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan []string, 1)
+	go d.Watch(ctx, func(peers []string) {
+		updates <- peers
+		cancel()
+	})
+	want := []string{"node-a.gocache.internal:8001", "node-b.gocache.internal:8002"}
+	if got := <-updates; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but got %v", want, got)
+	}
+}
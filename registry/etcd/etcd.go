@@ -0,0 +1,175 @@
+// Package etcd 基于 etcd 的节点注册与发现，实现了 registry.Registrar 和 registry.Discovery。
+// 每个节点以租约写入 <prefix><addr>，节点退出或失联后租约过期，记录随之删除。
+// 它是一个独立的 module，只有使用 etcd 的程序才会引入 etcd 依赖：
+//
+//	reg := etcd.New(client)
+//	go reg.Register(ctx, "http://10.0.0.1:8008")
+//	go reg.Watch(ctx, registry.OnChange(func(peers []string) { pool.Set(peers...) }))
+package etcd
+
+import (
+	"context"
+	"errors"
+	"go-cache/registry"
+	"log"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// DefaultPrefix 节点注册的默认前缀
+const DefaultPrefix = "/gocache/peers/"
+
+// DefaultTTL 租约默认的有效期，节点失联超过该时间后被移除
+const DefaultTTL = 10 * time.Second
+
+// Registry 基于 etcd 的节点注册与发现
+type Registry struct {
+	client *clientv3.Client
+	prefix string
+	ttl    time.Duration
+	// 租约丢失或监听中断后重试的间隔
+	retryInterval time.Duration
+}
+
+var (
+	_ registry.Registrar = (*Registry)(nil)
+	_ registry.Discovery = (*Registry)(nil)
+)
+
+// Option 构造 Registry 时的可选配置
+type Option func(*Registry)
+
+// WithPrefix 使用 prefix 代替 DefaultPrefix，同一个集群的节点需要使用相同的前缀
+func WithPrefix(prefix string) Option {
+	return func(r *Registry) {
+		r.prefix = prefix
+	}
+}
+
+// WithTTL 设置租约的有效期，默认为 DefaultTTL，etcd 的租约以秒为单位
+func WithTTL(ttl time.Duration) Option {
+	return func(r *Registry) {
+		r.ttl = ttl
+	}
+}
+
+// New 使用 client 实例化 Registry
+func New(client *clientv3.Client, opts ...Option) *Registry {
+	r := &Registry{
+		client:        client,
+		prefix:        DefaultPrefix,
+		ttl:           DefaultTTL,
+		retryInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register 实现 registry.Registrar，以租约注册 addr 并持续续约。
+// 租约意外丢失（例如与 etcd 断开超过 TTL）时重新注册，ctx 结束时撤销租约并返回 ctx.Err()
+func (r *Registry) Register(ctx context.Context, addr string) error {
+	for {
+		err := r.register(ctx, addr)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("[GeeCache] etcd registration of %s lost: %v", addr, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.retryInterval):
+		}
+	}
+}
+
+// register 注册一次，租约失效或 ctx 结束时返回
+func (r *Registry) register(ctx context.Context, addr string) error {
+	ttl := int64(r.ttl / time.Second)
+	if ttl < 1 {
+		ttl = 1
+	}
+	lease, err := r.client.Grant(ctx, ttl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// ctx 可能已经结束，使用新的 ctx 撤销租约，让其他节点立即感知
+		revokeCtx, cancel := context.WithTimeout(context.Background(), r.ttl)
+		defer cancel()
+		r.client.Revoke(revokeCtx, lease.ID)
+	}()
+	if _, err := r.client.Put(ctx, r.prefix+addr, addr, clientv3.WithLease(lease.ID)); err != nil {
+		return err
+	}
+	ch, err := r.client.KeepAlive(ctx, lease.ID)
+	if err != nil {
+		return err
+	}
+	for range ch {
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errLeaseLost
+}
+
+var errLeaseLost = errors.New("lease keepalive stopped")
+
+// Watch 实现 registry.Discovery，监听 prefix 下的全部节点，监听中断时重新获取全部节点
+func (r *Registry) Watch(ctx context.Context, update func(peers []string)) error {
+	for {
+		err := r.watch(ctx, update)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("[GeeCache] etcd watch on %s interrupted: %v", r.prefix, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.retryInterval):
+		}
+	}
+}
+
+// watch 获取当前的全部节点，然后从下一个版本开始监听变化
+func (r *Registry) watch(ctx context.Context, update func(peers []string)) error {
+	resp, err := r.client.Get(ctx, r.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	peers := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		peers[string(kv.Key)] = string(kv.Value)
+	}
+	update(values(peers))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wch := r.client.Watch(ctx, r.prefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	for wresp := range wch {
+		if err := wresp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range wresp.Events {
+			switch ev.Type {
+			case clientv3.EventTypePut:
+				peers[string(ev.Kv.Key)] = string(ev.Kv.Value)
+			case clientv3.EventTypeDelete:
+				delete(peers, string(ev.Kv.Key))
+			}
+		}
+		update(values(peers))
+	}
+	return ctx.Err()
+}
+
+func values(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
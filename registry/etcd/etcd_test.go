@@ -0,0 +1,86 @@
+package etcd
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func startEtcd(t *testing.T) *clientv3.Client {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	lc, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenClientUrls = []url.URL{*lc}
+	cfg.AdvertiseClientUrls = []url.URL{*lc}
+	lp, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenPeerUrls = []url.URL{*lp}
+	cfg.AdvertisePeerUrls = []url.URL{*lp}
+	cfg.InitialCluster = cfg.Name + "=" + lp.String()
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd did not start")
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{e.Clients[0].Addr().String()},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRegistry(t *testing.T) {
+	client := startEtcd(t)
+	reg := New(client, WithTTL(time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan []string, 16)
+	go reg.Watch(ctx, func(peers []string) {
+		sort.Strings(peers)
+		updates <- peers
+	})
+	expect := func(want ...string) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case got := <-updates:
+				if len(got) == 0 && len(want) == 0 || reflect.DeepEqual(got, want) {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for peers %v", want)
+			}
+		}
+	}
+	expect()
+
+	ctxA, cancelA := context.WithCancel(ctx)
+	doneA := make(chan error)
+	go func() { doneA <- reg.Register(ctxA, "http://a:8001") }()
+	go reg.Register(ctx, "http://b:8002")
+	expect("http://a:8001", "http://b:8002")
+
+	// 注销后其他节点立即感知，不必等待租约过期
+	cancelA()
+	if err := <-doneA; err != context.Canceled {
+		t.Fatalf("Register should return ctx.Err(), got %v", err)
+	}
+	expect("http://b:8002")
+}
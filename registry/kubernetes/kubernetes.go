@@ -0,0 +1,158 @@
+// Package kubernetes 通过 Kubernetes headless Service 的 EndpointSlice 发现节点，实现了 registry.Discovery。
+// Pod 的增删和就绪状态由 Kubernetes 维护，节点不需要自己注册。
+// 它是一个独立的 module，只有部署在 Kubernetes 中的程序才会引入 client-go 依赖：
+//
+//	d := kubernetes.New(clientset, "default", "gocache", kubernetes.WithPort("http"))
+//	go d.Watch(ctx, registry.OnChange(func(peers []string) { pool.Set(peers...) }))
+package kubernetes
+
+import (
+	"context"
+	"go-cache/registry"
+	"net"
+	"strconv"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Discovery 监听一个 Service 的全部 EndpointSlice
+type Discovery struct {
+	client    k8s.Interface
+	namespace string
+	service   string
+	// 端口名，为空时使用 EndpointSlice 中的第一个端口
+	port      string
+	scheme    string
+	readyOnly bool
+	resync    time.Duration
+}
+
+var _ registry.Discovery = (*Discovery)(nil)
+
+// Option 构造 Discovery 时的可选配置
+type Option func(*Discovery)
+
+// WithPort 使用名为 name 的端口，默认使用第一个端口
+func WithPort(name string) Option {
+	return func(d *Discovery) {
+		d.port = name
+	}
+}
+
+// WithScheme 设置节点地址的协议，默认为 "http"，得到 "http://10.0.0.1:8008" 形式的地址，
+// 与 HTTPPool 的节点地址一致；为空时得到 "10.0.0.1:8008"，适合 gRPC
+func WithScheme(scheme string) Option {
+	return func(d *Discovery) {
+		d.scheme = scheme
+	}
+}
+
+// WithReadyOnly 是否只返回就绪的 Pod，默认为 true。
+// 关闭后正在启动或未通过 readinessProbe 的 Pod 也会加入一致性哈希环
+func WithReadyOnly(readyOnly bool) Option {
+	return func(d *Discovery) {
+		d.readyOnly = readyOnly
+	}
+}
+
+// New 实例化 Discovery，监听 namespace 中名为 service 的 Service
+func New(client k8s.Interface, namespace, service string, opts ...Option) *Discovery {
+	d := &Discovery{
+		client:    client,
+		namespace: namespace,
+		service:   service,
+		scheme:    "http",
+		readyOnly: true,
+		resync:    10 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Watch 实现 registry.Discovery，任意一个 EndpointSlice 变化时重新计算全部节点
+func (d *Discovery) Watch(ctx context.Context, update func(peers []string)) error {
+	selector := labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: d.service}).String()
+	factory := informers.NewSharedInformerFactoryWithOptions(d.client, d.resync,
+		informers.WithNamespace(d.namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = selector
+		}))
+	informer := factory.Discovery().V1().EndpointSlices()
+	lister := informer.Lister().EndpointSlices(d.namespace)
+
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	})
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+		return ctx.Err()
+	}
+
+	for {
+		slices, err := lister.List(labels.Everything())
+		if err == nil {
+			update(d.peersOf(slices))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// peersOf 从 EndpointSlice 中取出节点地址
+func (d *Discovery) peersOf(slices []*discoveryv1.EndpointSlice) []string {
+	var peers []string
+	for _, s := range slices {
+		port, ok := d.portOf(s)
+		if !ok {
+			continue
+		}
+		for _, ep := range s.Endpoints {
+			// Ready 为 nil 时按照 Kubernetes 的约定视为就绪
+			if d.readyOnly && ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			for _, addr := range ep.Addresses {
+				peer := net.JoinHostPort(addr, strconv.Itoa(int(port)))
+				if d.scheme != "" {
+					peer = d.scheme + "://" + peer
+				}
+				peers = append(peers, peer)
+			}
+		}
+	}
+	return peers
+}
+
+// portOf 返回 EndpointSlice 中要使用的端口
+func (d *Discovery) portOf(s *discoveryv1.EndpointSlice) (int32, bool) {
+	for _, p := range s.Ports {
+		if p.Port == nil {
+			continue
+		}
+		if d.port == "" || (p.Name != nil && *p.Name == d.port) {
+			return *p.Port, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func endpointSlice(name string, ready map[string]bool) *discoveryv1.EndpointSlice {
+	portName, port := "http", int32(8008)
+	s := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "gocache"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports:       []discoveryv1.EndpointPort{{Name: &portName, Port: &port}},
+	}
+	for addr, r := range ready {
+		r := r
+		s.Endpoints = append(s.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{addr},
+			Conditions: discoveryv1.EndpointConditions{Ready: &r},
+		})
+	}
+	return s
+}
+
+func TestDiscovery(t *testing.T) {
+	other := endpointSlice("other", map[string]bool{"10.0.0.9": true})
+	other.Labels[discoveryv1.LabelServiceName] = "other"
+	client := fake.NewSimpleClientset(
+		endpointSlice("gocache-a", map[string]bool{"10.0.0.1": true, "10.0.0.2": false}),
+		other,
+	)
+	d := New(client, "default", "gocache", WithPort("http"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan []string, 16)
+	go d.Watch(ctx, func(peers []string) {
+		sort.Strings(peers)
+		updates <- peers
+	})
+	expect := func(want ...string) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case got := <-updates:
+				if reflect.DeepEqual(got, want) {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for peers %v", want)
+			}
+		}
+	}
+	// 未就绪的 Pod 和其他 Service 的 EndpointSlice 不计入
+	expect("http://10.0.0.1:8008")
+
+	slices := client.DiscoveryV1().EndpointSlices("default")
+	slices.Create(ctx, endpointSlice("gocache-b", map[string]bool{"10.0.0.3": true}), metav1.CreateOptions{})
+	expect("http://10.0.0.1:8008", "http://10.0.0.3:8008")
+
+	slices.Update(ctx, endpointSlice("gocache-a", map[string]bool{"10.0.0.1": true, "10.0.0.2": true}), metav1.UpdateOptions{})
+	expect("http://10.0.0.1:8008", "http://10.0.0.2:8008", "http://10.0.0.3:8008")
+
+	slices.Delete(ctx, "gocache-a", metav1.DeleteOptions{})
+	expect("http://10.0.0.3:8008")
+}
+
+func TestPeersOf(t *testing.T) {
+	s := endpointSlice("gocache-a", map[string]bool{"10.0.0.1": true, "10.0.0.2": false})
+	d := New(nil, "default", "gocache", WithScheme(""), WithReadyOnly(false))
+	peers := d.peersOf([]*discoveryv1.EndpointSlice{s})
+	sort.Strings(peers)
+	if !reflect.DeepEqual(peers, []string{"10.0.0.1:8008", "10.0.0.2:8008"}) {
+		t.Fatalf("unexpected peers %v", peers)
+	}
+	if peers := New(nil, "default", "gocache", WithPort("grpc")).peersOf([]*discoveryv1.EndpointSlice{s}); len(peers) != 0 {
+		t.Fatalf("slices without the named port should be skipped, got %v", peers)
+	}
+}
@@ -0,0 +1,156 @@
+// Package memberlist 基于 gossip 协议（hashicorp/memberlist）的节点发现，实现了 registry.Registrar 和 registry.Discovery。
+// 节点之间互相探测并传播成员变化，不依赖任何外部的注册中心，适合小规模集群。
+// 每个 gossip 成员的元数据中保存它的缓存服务地址。它是一个独立的 module：
+//
+//	g := memberlist.New(nil, "10.0.0.1:7946")
+//	go g.Register(ctx, "http://10.0.0.2:8008")
+//	go g.Watch(ctx, registry.OnChange(func(peers []string) { pool.Set(peers...) }))
+package memberlist
+
+import (
+	"context"
+	"errors"
+	"go-cache/registry"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+)
+
+// Gossip 基于 memberlist 的节点注册与发现，Register 加入集群，Watch 监听集群成员的变化
+type Gossip struct {
+	cfg   *memberlist.Config
+	seeds []string
+	// 加入集群失败后重试的间隔
+	retryInterval time.Duration
+
+	mu   sync.Mutex
+	list *memberlist.Memberlist
+	// 成员变化时关闭并替换，用于唤醒 Watch
+	changed chan struct{}
+}
+
+var (
+	_ registry.Registrar = (*Gossip)(nil)
+	_ registry.Discovery = (*Gossip)(nil)
+)
+
+// New 实例化 Gossip，cfg 为 nil 时使用 memberlist.DefaultLANConfig()，
+// seeds 为已知的其他成员的 gossip 地址，加入集群时至少需要连接上其中一个，第一个节点可以为空
+func New(cfg *memberlist.Config, seeds ...string) *Gossip {
+	if cfg == nil {
+		cfg = memberlist.DefaultLANConfig()
+	}
+	return &Gossip{
+		cfg:           cfg,
+		seeds:         seeds,
+		retryInterval: time.Second,
+		changed:       make(chan struct{}),
+	}
+}
+
+// Register 实现 registry.Registrar，以 addr 作为成员名和元数据加入 gossip 集群，
+// 连接 seeds 失败时持续重试，ctx 结束时通知其他成员离开并返回 ctx.Err()
+func (g *Gossip) Register(ctx context.Context, addr string) error {
+	cfg := *g.cfg
+	cfg.Name = addr
+	cfg.Delegate = meta(addr)
+	cfg.Events = &events{g}
+	list, err := memberlist.Create(&cfg)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	if g.list != nil {
+		g.mu.Unlock()
+		list.Shutdown()
+		return errors.New("memberlist: already registered")
+	}
+	g.list = list
+	g.mu.Unlock()
+	g.notify()
+
+	defer func() {
+		list.Leave(cfg.PushPullInterval)
+		list.Shutdown()
+		g.mu.Lock()
+		g.list = nil
+		g.mu.Unlock()
+		g.notify()
+	}()
+
+	for len(g.seeds) > 0 {
+		if _, err := list.Join(g.seeds); err == nil {
+			break
+		} else {
+			log.Println("[GeeCache] memberlist join failed:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(g.retryInterval):
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Watch 实现 registry.Discovery，成员加入、离开或被探测为失联时调用 update。
+// 在 Register 之前调用时先得到空的节点列表
+func (g *Gossip) Watch(ctx context.Context, update func(peers []string)) error {
+	for {
+		g.mu.Lock()
+		changed, list := g.changed, g.list
+		g.mu.Unlock()
+		// memberlist 持有自己的锁调用 events，所以不能在持有 g.mu 时调用 Members
+		var peers []string
+		if list != nil {
+			for _, m := range list.Members() {
+				if len(m.Meta) > 0 {
+					peers = append(peers, string(m.Meta))
+				}
+			}
+		}
+		update(peers)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// notify 唤醒所有的 Watch
+func (g *Gossip) notify() {
+	g.mu.Lock()
+	close(g.changed)
+	g.changed = make(chan struct{})
+	g.mu.Unlock()
+}
+
+// meta 实现 memberlist.Delegate，只用于在元数据中携带节点地址
+type meta string
+
+func (m meta) NodeMeta(limit int) []byte {
+	if len(m) > limit {
+		log.Printf("[GeeCache] memberlist node meta %q exceeds %d bytes", string(m), limit)
+		return nil
+	}
+	return []byte(m)
+}
+
+func (meta) NotifyMsg([]byte)                           {}
+func (meta) GetBroadcasts(overhead, limit int) [][]byte { return nil }
+func (meta) LocalState(join bool) []byte                { return nil }
+func (meta) MergeRemoteState(buf []byte, join bool)     {}
+
+// events 实现 memberlist.EventDelegate，成员变化时唤醒 Watch
+type events struct {
+	g *Gossip
+}
+
+func (e *events) NotifyJoin(*memberlist.Node)   { e.g.notify() }
+func (e *events) NotifyLeave(*memberlist.Node)  { e.g.notify() }
+func (e *events) NotifyUpdate(*memberlist.Node) { e.g.notify() }
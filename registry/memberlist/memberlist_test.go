@@ -0,0 +1,77 @@
+package memberlist
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+)
+
+func testConfig() *memberlist.Config {
+	cfg := memberlist.DefaultLocalConfig()
+	cfg.BindAddr = "127.0.0.1"
+	cfg.BindPort = 0
+	cfg.LogOutput = testWriter{}
+	return cfg
+}
+
+type testWriter struct{}
+
+func (testWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestGossip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := New(testConfig())
+	go a.Register(ctx, "http://a:8001")
+	var seed string
+	for i := 0; i < 100 && seed == ""; i++ {
+		a.mu.Lock()
+		if a.list != nil {
+			seed = a.list.LocalNode().Address()
+		}
+		a.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if seed == "" {
+		t.Fatalf("first node should start without seeds")
+	}
+
+	updates := make(chan []string, 64)
+	go a.Watch(ctx, func(peers []string) {
+		sort.Strings(peers)
+		updates <- peers
+	})
+	expect := func(want ...string) {
+		t.Helper()
+		deadline := time.After(10 * time.Second)
+		for {
+			select {
+			case got := <-updates:
+				if reflect.DeepEqual(got, want) {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for peers %v", want)
+			}
+		}
+	}
+	expect("http://a:8001")
+
+	ctxB, cancelB := context.WithCancel(ctx)
+	b := New(testConfig(), seed)
+	doneB := make(chan error)
+	go func() { doneB <- b.Register(ctxB, "http://b:8002") }()
+	expect("http://a:8001", "http://b:8002")
+
+	// 离开的节点通过 gossip 通知其他成员
+	cancelB()
+	if err := <-doneB; err != context.Canceled {
+		t.Fatalf("Register should return ctx.Err(), got %v", err)
+	}
+	expect("http://a:8001")
+}
@@ -0,0 +1,111 @@
+// Package registry 节点发现的公共接口，具体的注册中心位于子目录，例如 registry/etcd。
+// 发现的节点列表通常直接交给 HTTPPool.Set：
+//
+//	go reg.Register(ctx, self)
+//	go reg.Watch(ctx, registry.OnChange(func(peers []string) { pool.Set(peers...) }))
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Registrar 把本节点注册到注册中心
+type Registrar interface {
+	// Register 注册地址为 addr 的本节点并保持注册状态，直到 ctx 结束时注销并返回
+	Register(ctx context.Context, addr string) error
+}
+
+// Discovery 发现集群中的节点
+type Discovery interface {
+	// Watch 先用当前的全部节点调用一次 update，之后每次节点变化时用新的全部节点调用 update，
+	// 直到 ctx 结束时返回 ctx.Err()
+	Watch(ctx context.Context, update func(peers []string)) error
+}
+
+// OnChange 包装 update，传入的节点列表经过排序和去重，与上一次相同时不再调用 update，
+// 适合节点列表变化时才需要重建一致性哈希环的场景。
+// 排序后新节点可能插入到列表中间，按位置编号节点的跳跃一致性哈希（WithJumpHashing）会因此迁移大量的 key，
+// 这种情况应当使用 OnChangeAppend
+func OnChange(update func(peers []string)) func(peers []string) {
+	var (
+		mu   sync.Mutex
+		last []string
+		sent bool
+	)
+	return func(peers []string) {
+		peers = normalize(peers)
+		mu.Lock()
+		defer mu.Unlock()
+		if sent && equal(peers, last) {
+			return
+		}
+		last, sent = peers, true
+		update(peers)
+	}
+}
+
+// OnChangeAppend 与 OnChange 相同，但已有节点的顺序保持不变：删除的节点直接去掉，新的节点排序后追加在末尾，
+// 这样跳跃一致性哈希在末尾加入一个节点时只迁移大约 1/n 的 key。
+// 各节点得到的顺序取决于各自观察到的变化，只有从相同的节点列表开始并经历相同的变化时才一致
+func OnChangeAppend(update func(peers []string)) func(peers []string) {
+	var (
+		mu   sync.Mutex
+		last []string
+		sent bool
+	)
+	return func(peers []string) {
+		peers = normalize(peers)
+		mu.Lock()
+		defer mu.Unlock()
+		current := make(map[string]bool, len(peers))
+		for _, p := range peers {
+			current[p] = true
+		}
+		ordered := make([]string, 0, len(peers))
+		for _, p := range last {
+			if current[p] {
+				ordered = append(ordered, p)
+				delete(current, p)
+			}
+		}
+		// peers 已经排序，剩下的新节点按顺序追加
+		for _, p := range peers {
+			if current[p] {
+				ordered = append(ordered, p)
+			}
+		}
+		if sent && equal(ordered, last) {
+			return
+		}
+		last, sent = ordered, true
+		update(ordered)
+	}
+}
+
+// normalize 返回排序并去重之后的节点列表
+func normalize(peers []string) []string {
+	out := make([]string, 0, len(peers))
+	seen := make(map[string]bool, len(peers))
+	for _, p := range peers {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnChange(t *testing.T) {
+	var got [][]string
+	update := OnChange(func(peers []string) {
+		got = append(got, peers)
+	})
+	update([]string{"b", "a", "a"})
+	update([]string{"a", "b"})
+	update([]string{"a", "c", ""})
+	update(nil)
+	update(nil)
+
+	want := [][]string{{"a", "b"}, {"a", "c"}, {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but got %v", want, got)
+	}
+}
+
+func TestOnChangeAppend(t *testing.T) {
+	var got [][]string
+	update := OnChangeAppend(func(peers []string) {
+		got = append(got, peers)
+	})
+	update([]string{"c", "a", "a"})
+	update([]string{"b", "a", "c"})
+	update([]string{"c", "b", "a"})
+	update([]string{"b", "d"})
+
+	want := [][]string{{"a", "c"}, {"a", "c", "b"}, {"b", "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but got %v", want, got)
+	}
+}
@@ -0,0 +1,206 @@
+package singleflight
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrGoexit fn 中调用了 runtime.Goexit 时，等待的调用方收到的错误
+var ErrGoexit = errors.New("singleflight: fn called runtime.Goexit")
+
+// PanicError fn 发生 panic 时，所有调用方收到的错误
+type PanicError struct {
+	// panic 的值
+	Value interface{}
+	// 发生 panic 时的调用栈
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("singleflight: fn panicked: %v\n\n%s", p.Value, p.Stack)
+}
+
+// call 代表正在进行中，或已经结束的请求
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+	// 共享该请求结果的调用方数量（不含第一个）
+	dups int
+	// DoChan 的调用方
+	chans []chan<- Result
+}
+
+// Result DoChan 返回的结果
+type Result struct {
+	Val interface{}
+	Err error
+	// 结果是否与其他调用方共享
+	Shared bool
+}
+
+// Group 管理不同 key 的请求(call)，相同 key 的并发请求只会执行一次 fn
+type Group struct {
+	mu sync.Mutex
+	m  map[string]*call
+}
+
+// Do 针对相同的 key，无论 Do 被并发调用多少次，fn 都只会被调用一次，
+// 其他调用方等待 fn 结束后共享同一个结果。
+func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := new(call)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	g.doCall(c, key, fn)
+	return c.val, c.err
+}
+
+// DoChan 与 Do 相同，但不会阻塞，结果通过返回的 channel 送达。
+// 适合发起后不关心结果的后台刷新。
+func (g *Group) DoChan(key string, fn func() (interface{}, error)) <-chan Result {
+	ch := make(chan Result, 1)
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		c.dups++
+		c.chans = append(c.chans, ch)
+		g.mu.Unlock()
+		return ch
+	}
+	c := &call{chans: []chan<- Result{ch}}
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	go g.doCall(c, key, fn)
+	return ch
+}
+
+// DoMulti 批量版本的 Do：keys 中没有进行中请求的 key 合并为一次 fn 调用，fn 返回其中每个 key 的结果，
+// 已经有进行中请求（包括 Do 发起的）的 key 等待该请求，返回所有 key 的结果。
+// 批量请求的结果同样会共享给之后对其中某个 key 调用 Do 的调用方，fn 没有返回结果的 key 会收到空的 Result
+func (g *Group) DoMulti(keys []string, fn func(keys []string) map[string]Result) map[string]Result {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	owned := make(map[string]*call)
+	waiting := make(map[string]*call)
+	var ownedKeys []string
+	for _, key := range keys {
+		if _, ok := owned[key]; ok {
+			continue
+		}
+		if c, ok := g.m[key]; ok {
+			if _, ok := waiting[key]; !ok {
+				c.dups++
+				waiting[key] = c
+			}
+			continue
+		}
+		c := new(call)
+		c.wg.Add(1)
+		g.m[key] = c
+		owned[key] = c
+		ownedKeys = append(ownedKeys, key)
+	}
+	g.mu.Unlock()
+
+	results := make(map[string]Result, len(keys))
+	if len(ownedKeys) > 0 {
+		var res map[string]Result
+		// 复用 doCall 的 panic 与 Goexit 处理，fn 的结果再分发给各个 key
+		batch := new(call)
+		func() {
+			defer func() {
+				for key, c := range owned {
+					r := res[key]
+					c.val, c.err = r.Val, r.Err
+					if batch.err != nil {
+						c.val, c.err = nil, batch.err
+					}
+					g.finish(c, key)
+					results[key] = Result{Val: c.val, Err: c.err, Shared: c.dups > 0}
+				}
+			}()
+			batch.wg.Add(1)
+			g.doCall(batch, "", func() (interface{}, error) {
+				res = fn(ownedKeys)
+				return nil, nil
+			})
+		}()
+	}
+	for key, c := range waiting {
+		c.wg.Wait()
+		results[key] = Result{Val: c.val, Err: c.err, Shared: true}
+	}
+	return results
+}
+
+// Forget 丢弃 key 对应的进行中的请求，之后的 Do 会重新调用 fn 而不是等待它。
+// 已经在等待的调用方仍然会收到原请求的结果。
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+}
+
+// doCall 执行 fn 并把结果交给所有调用方。
+// fn 发生 panic 时所有调用方都会收到 *PanicError；
+// fn 调用 runtime.Goexit 时执行 fn 的协程照常退出，其他调用方收到 ErrGoexit，不会永远等待。
+func (g *Group) doCall(c *call, key string, fn func() (interface{}, error)) {
+	normalReturn, recovered := false, false
+	defer func() {
+		if !normalReturn && !recovered {
+			c.err = ErrGoexit
+		}
+		g.finish(c, key)
+	}()
+
+	func() {
+		defer func() {
+			if !normalReturn {
+				// Goexit 时 recover 返回 nil
+				if r := recover(); r != nil {
+					c.err = &PanicError{Value: r, Stack: debug.Stack()}
+				}
+			}
+		}()
+		c.val, c.err = fn()
+		normalReturn = true
+	}()
+	if !normalReturn {
+		recovered = true
+	}
+}
+
+// finish 结束请求 c，唤醒等待的调用方，并把结果发送给 DoChan 的调用方
+func (g *Group) finish(c *call, key string) {
+	c.wg.Done()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// 请求可能已经被 Forget，并且有新的请求占用了这个 key
+	if g.m[key] == c {
+		delete(g.m, key)
+	}
+	for _, ch := range c.chans {
+		ch <- Result{Val: c.val, Err: c.err, Shared: c.dups > 0}
+	}
+}
@@ -0,0 +1,192 @@
+package singleflight
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var g Group
+	v, err := g.Do("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+
+	if v != "bar" || err != nil {
+		t.Errorf("Do v = %v, error = %v", v, err)
+	}
+}
+
+func TestDoDupSuppress(t *testing.T) {
+	var g Group
+	var calls int32
+	release := make(chan struct{})
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "bar", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := g.Do("key", fn); v != "bar" || err != nil {
+				t.Errorf("Do v = %v, error = %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("number of calls = %d; want 1", calls)
+	}
+}
+
+func TestDoChan(t *testing.T) {
+	var g Group
+	ch := g.DoChan("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+
+	res := <-ch
+	if res.Val != "bar" || res.Err != nil || res.Shared {
+		t.Errorf("DoChan res = %+v", res)
+	}
+}
+
+func TestDoMulti(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan interface{})
+	go func() {
+		v, _ := g.Do("a", func() (interface{}, error) {
+			close(started)
+			<-release
+			return "A", nil
+		})
+		done <- v
+	}()
+	<-started
+
+	var got []string
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	results := g.DoMulti([]string{"a", "b", "b", "c"}, func(keys []string) map[string]Result {
+		got = keys
+		return map[string]Result{"b": {Val: "B"}, "c": {Err: errors.New("boom")}}
+	})
+	if len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Fatalf("in-flight key should not be loaded again, got %v", got)
+	}
+	if r := results["a"]; r.Val != "A" || !r.Shared {
+		t.Fatalf("key a should wait for the in-flight Do, got %+v", r)
+	}
+	if r := results["b"]; r.Val != "B" || r.Err != nil {
+		t.Fatalf("unexpected result for b: %+v", r)
+	}
+	if r := results["c"]; r.Err == nil {
+		t.Fatalf("error for c should be returned, got %+v", r)
+	}
+	if v := <-done; v != "A" {
+		t.Fatalf("Do should not be affected, got %v", v)
+	}
+
+	results = g.DoMulti([]string{"x"}, func(keys []string) map[string]Result {
+		panic("boom")
+	})
+	var pe *PanicError
+	if !errors.As(results["x"].Err, &pe) {
+		t.Fatalf("panic should be returned as PanicError, got %+v", results["x"])
+	}
+	if v, err := g.Do("x", func() (interface{}, error) { return "X", nil }); v != "X" || err != nil {
+		t.Fatalf("key should be released after a panic, got %v %v", v, err)
+	}
+}
+
+func TestForget(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	first := g.DoChan("key", func() (interface{}, error) {
+		<-release
+		return 1, nil
+	})
+
+	g.Forget("key")
+	v, err := g.Do("key", func() (interface{}, error) {
+		return 2, nil
+	})
+	if v != 2 || err != nil {
+		t.Fatalf("Do after Forget should call fn again, got %v", v)
+	}
+
+	close(release)
+	if res := <-first; res.Val != 1 {
+		t.Fatalf("forgotten call should still deliver its result, got %v", res.Val)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	waiter := make(chan error)
+	go func() {
+		// 等待 fn 开始执行后再加入
+		<-release
+		_, err := g.Do("key", func() (interface{}, error) {
+			return nil, nil
+		})
+		waiter <- err
+	}()
+
+	_, err := g.Do("key", func() (interface{}, error) {
+		close(release)
+		time.Sleep(50 * time.Millisecond)
+		panic("boom")
+	})
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Fatalf("expected PanicError but got %v", err)
+	}
+	if err := <-waiter; !errors.As(err, &perr) {
+		t.Fatalf("waiting caller should receive PanicError but got %v", err)
+	}
+}
+
+func TestGoexit(t *testing.T) {
+	var g Group
+	started := make(chan struct{})
+	go func() {
+		g.Do("key", func() (interface{}, error) {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			runtime.Goexit()
+			return nil, nil
+		})
+	}()
+
+	<-started
+	done := make(chan error)
+	go func() {
+		_, err := g.Do("key", func() (interface{}, error) {
+			return nil, nil
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != ErrGoexit && err != nil {
+			t.Fatalf("expected ErrGoexit but got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("waiting caller hangs after runtime.Goexit")
+	}
+}
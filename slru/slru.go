@@ -0,0 +1,133 @@
+package slru
+
+import "container/list"
+
+// Cache slru (Segmented LRU) 缓存淘汰策略。
+// 新记录先进入试用段 (probation)，再次命中时才晋升到保护段 (protected)，
+// 保护段超出限制时把最久未访问的记录降级回试用段。
+// 淘汰总是优先发生在试用段，因此一次性的大范围扫描不会冲掉经常访问的记录。
+// 与 lru.Cache 拥有相同的 Value 接口和内存预算，可以直接替换。
+type Cache struct {
+	// 允许使用的最大内存
+	maxBytes int64
+	// 保护段允许使用的最大内存
+	maxProtected int64
+	// 两个段各自占用的内存
+	probationBytes, protectedBytes int64
+	// 两个段的链表，头部是最近访问的记录
+	probation, protected *list.List
+	// 键是字符串，值是所在链表中节点型指针。
+	cache map[string]*list.Element
+	// 某条记录被移除时的回调函数，可以为 nil。
+	OnEvicted func(key string, value Value)
+}
+
+type entry struct {
+	key   string
+	value Value
+	// 记录所在的链表
+	ll *list.List
+}
+
+// Value 接口，与 lru.Value 相同，用于返回值所占用的内存大小。
+type Value interface {
+	Len() int
+}
+
+// ProtectedRatio 保护段占总内存的比例
+const ProtectedRatio = 0.8
+
+// New 方便实例化 Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:     maxBytes,
+		maxProtected: int64(float64(maxBytes) * ProtectedRatio),
+		probation:    list.New(),
+		protected:    list.New(),
+		cache:        make(map[string]*list.Element),
+		OnEvicted:    onEvicted,
+	}
+}
+
+// Len 返回缓存的记录数
+func (c *Cache) Len() int {
+	return len(c.cache)
+}
+
+// Add 新增/修改，修改已有记录视为一次命中
+func (c *Cache) Add(key string, value Value) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		*c.bytesOf(kv.ll) += int64(value.Len()) - int64(kv.value.Len())
+		kv.value = value
+		c.hit(ele)
+	} else {
+		c.push(c.probation, &entry{key: key, value: value})
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.probationBytes+c.protectedBytes {
+		c.RemoveOldest()
+	}
+}
+
+// Get 获取 value，试用段中的记录命中后晋升到保护段
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		c.hit(ele)
+		return c.cache[key].Value.(*entry).value, true
+	}
+	return
+}
+
+// RemoveOldest 移除试用段中最久未访问的值，试用段为空时移除保护段中最久未访问的值。
+// 方法名与 lru.Cache 保持一致，方便替换。
+func (c *Cache) RemoveOldest() {
+	ele := c.probation.Back()
+	if ele == nil {
+		ele = c.protected.Back()
+	}
+	if ele == nil {
+		return
+	}
+	kv := ele.Value.(*entry)
+	c.unlink(ele)
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// hit 记录一次命中：试用段晋升到保护段，保护段移动到头部
+func (c *Cache) hit(ele *list.Element) {
+	kv := ele.Value.(*entry)
+	if kv.ll == c.protected {
+		c.protected.MoveToFront(ele)
+		return
+	}
+	c.unlink(ele)
+	c.push(c.protected, kv)
+	for c.maxBytes != 0 && c.protectedBytes > c.maxProtected && c.protected.Len() > 1 {
+		back := c.protected.Back()
+		demoted := back.Value.(*entry)
+		c.unlink(back)
+		c.push(c.probation, demoted)
+	}
+}
+
+func (c *Cache) push(l *list.List, kv *entry) {
+	kv.ll = l
+	c.cache[kv.key] = l.PushFront(kv)
+	*c.bytesOf(l) += int64(len(kv.key)) + int64(kv.value.Len())
+}
+
+func (c *Cache) unlink(ele *list.Element) {
+	kv := ele.Value.(*entry)
+	kv.ll.Remove(ele)
+	*c.bytesOf(kv.ll) -= int64(len(kv.key)) + int64(kv.value.Len())
+	delete(c.cache, kv.key)
+}
+
+func (c *Cache) bytesOf(l *list.List) *int64 {
+	if l == c.protected {
+		return &c.protectedBytes
+	}
+	return &c.probationBytes
+}
@@ -0,0 +1,59 @@
+package slru
+
+import (
+	"reflect"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestGet(t *testing.T) {
+	slru := New(int64(0), nil)
+	slru.Add("key1", String("1234"))
+	if v, ok := slru.Get("key1"); !ok || string(v.(String)) != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	if _, ok := slru.Get("key2"); ok {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	slru := New(int64(12), callback)
+	slru.Add("k1", String("v1"))
+	slru.Get("k1")
+	slru.Add("k2", String("v2"))
+	slru.Add("k3", String("v3"))
+	slru.Add("k4", String("v4"))
+	slru.Add("k5", String("v5"))
+
+	expect := []string{"k2", "k3"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s but got %s", expect, keys)
+	}
+	if _, ok := slru.Get("k1"); !ok {
+		t.Fatalf("protected key k1 should survive the scan")
+	}
+}
+
+func TestDemote(t *testing.T) {
+	slru := New(int64(9), nil)
+	slru.Add("k1", String("v1"))
+	slru.Add("k2", String("v2"))
+	slru.Get("k1")
+	slru.Get("k2")
+	if slru.protected.Len() != 1 || slru.probation.Front().Value.(*entry).key != "k1" {
+		t.Fatalf("protected segment overflow should demote k1 to probation")
+	}
+	if slru.probationBytes+slru.protectedBytes != 8 {
+		t.Fatalf("expected 8 bytes but got %d", slru.probationBytes+slru.protectedBytes)
+	}
+}
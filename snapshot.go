@@ -0,0 +1,32 @@
+package go_cache
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Snapshot 把本节点负责的 key（mainCache）中所有未过期的值以及它们的过期时间写入 w，
+// 可以在重启之后通过 Restore 恢复，避免冷启动时大量的请求落到数据源上。
+// hotCache 中的值由其他节点负责，不会写入快照；标签和依赖关系也不会保存。
+// Snapshot 期间会持有缓存的锁，缓存较大时应当写入速度较快的 w。
+func (g *Group) Snapshot(w io.Writer) error {
+	if atomic.LoadInt32(&g.closed) == 1 {
+		return ErrGroupClosed
+	}
+	return g.mainCache.snapshot(w)
+}
+
+// Restore 从 r 读取 Snapshot 写入的值并添加到 mainCache，已有的同名值会被覆盖，
+// 快照之后已经过期的值会被跳过。快照中不保存版本，恢复的值使用新的版本。
+// 配置了 WithClientOnly 时不使用 mainCache，返回包装了 ErrClientOnly 的错误。
+func (g *Group) Restore(r io.Reader) error {
+	if atomic.LoadInt32(&g.closed) == 1 {
+		return ErrGroupClosed
+	}
+	if g.clientOnly {
+		return fmt.Errorf("go-cache: group %q: restore: %w", g.name, ErrClientOnly)
+	}
+	atomic.AddInt64(&g.writes, 1)
+	return g.mainCache.restore(r, g.nextVersion)
+}
@@ -0,0 +1,63 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	store := &memStore{m: map[string]string{"Tom": "630", "Jack": strings.Repeat("589", 100)}}
+	src := NewGroup("snapshot-src", 2<<10, store, WithTTL(time.Minute), WithCompression(64))
+	src.Get(context.Background(), "Tom")
+	src.Get(context.Background(), "Jack")
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loads := 0
+	dst := NewGroup("snapshot-dst", 2<<10, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		loads++
+		return []byte("reloaded"), nil
+	}))
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range store.m {
+		if v, err := dst.Get(context.Background(), key); err != nil || v.String() != want {
+			t.Fatalf("%s: got %q %v", key, v.String(), err)
+		}
+	}
+	if loads != 0 {
+		t.Fatalf("restored keys should not be loaded, got %d loads", loads)
+	}
+	if _, expire, _ := dst.mainCache.getWithExpire("Tom"); expire.IsZero() || time.Until(expire) > time.Minute {
+		t.Fatalf("expiry should be restored, got %v", expire)
+	}
+
+	if err := dst.Restore(strings.NewReader("garbage")); err == nil {
+		t.Fatalf("bad snapshot should be rejected")
+	}
+	dst.Close(context.Background())
+	if err := dst.Snapshot(&buf); err != ErrGroupClosed {
+		t.Fatalf("closed group should return ErrGroupClosed, got %v", err)
+	}
+}
+
+func TestRestoreClientOnly(t *testing.T) {
+	var buf bytes.Buffer
+	src := NewGroup("snapshot-client-src", 2<<10, &memStore{m: map[string]string{"Tom": "630"}})
+	src.Get(context.Background(), "Tom")
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewGroup("snapshot-client-dst", 2<<10, nil, WithClientOnly())
+	if err := dst.Restore(&buf); !errors.Is(err, ErrClientOnly) {
+		t.Fatalf("client-only group should reject Restore, got %v", err)
+	}
+}
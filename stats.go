@@ -0,0 +1,120 @@
+package go_cache
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// Stats Group 的统计信息，字段名与 groupcache 保持一致，方便复用已有的监控面板
+type Stats struct {
+	// Get 的调用次数（GetMulti 中每个 key 计一次）
+	Gets int64
+	// 缓存命中次数，包括 mainCache 和 hotCache
+	CacheHits int64
+	// 从其他节点加载成功的次数
+	PeerLoads int64
+	// 从其他节点加载失败的次数
+	PeerErrors int64
+	// 缓存未命中、需要加载的次数
+	Loads int64
+	// singleflight 合并之后实际执行加载的次数
+	LoadsDeduped int64
+	// 从本地数据源加载成功的次数
+	LocalLoads int64
+	// 从本地数据源加载失败的次数
+	LocalLoadErrs int64
+	// 超过 WithMaxValueSize 而没有写入缓存的值的个数
+	OversizedValues int64
+	// WithErrorBackoff 退避期间直接返回错误、没有访问数据源的次数
+	SuppressedLoads int64
+	// WithReadRepair 把最新的值写入不一致的副本的次数
+	ReadRepairs int64
+}
+
+// groupStats 统计计数器，使用原子操作维护
+type groupStats struct {
+	gets, cacheHits, loads, loadsDeduped int64
+	peerLoads, peerErrors                int64
+	localLoads, localLoadErrs            int64
+	oversizedValues, suppressedLoads     int64
+	readRepairs                          int64
+}
+
+func (s *groupStats) incr(p *int64) {
+	atomic.AddInt64(p, 1)
+}
+
+func (s *groupStats) add(p *int64, n int) {
+	atomic.AddInt64(p, int64(n))
+}
+
+func (s *groupStats) load() Stats {
+	return Stats{
+		Gets:            atomic.LoadInt64(&s.gets),
+		CacheHits:       atomic.LoadInt64(&s.cacheHits),
+		PeerLoads:       atomic.LoadInt64(&s.peerLoads),
+		PeerErrors:      atomic.LoadInt64(&s.peerErrors),
+		Loads:           atomic.LoadInt64(&s.loads),
+		LoadsDeduped:    atomic.LoadInt64(&s.loadsDeduped),
+		LocalLoads:      atomic.LoadInt64(&s.localLoads),
+		LocalLoadErrs:   atomic.LoadInt64(&s.localLoadErrs),
+		OversizedValues: atomic.LoadInt64(&s.oversizedValues),
+		SuppressedLoads: atomic.LoadInt64(&s.suppressedLoads),
+		ReadRepairs:     atomic.LoadInt64(&s.readRepairs),
+	}
+}
+
+// Stats 返回 Group 的统计信息
+func (g *Group) Stats() Stats {
+	return g.stats.load()
+}
+
+// CacheType Group 内部的缓存
+type CacheType int
+
+const (
+	// MainCache 本节点负责的 key
+	MainCache CacheType = iota + 1
+	// HotCache 由其他节点负责的热点 key
+	HotCache
+)
+
+// CacheStats 单个缓存的统计信息
+type CacheStats struct {
+	// 占用的内存
+	Bytes int64
+	// 记录数
+	Items int64
+	// 读取次数
+	Gets int64
+	// 命中次数
+	Hits int64
+	// 因内存不足被淘汰的记录数
+	Evictions int64
+}
+
+// CacheStats 返回 which 对应缓存的统计信息
+func (g *Group) CacheStats(which CacheType) CacheStats {
+	switch which {
+	case MainCache:
+		return g.mainCache.stats()
+	case HotCache:
+		return g.hotCache.stats()
+	default:
+		return CacheStats{}
+	}
+}
+
+// 通过 expvar 发布所有 Group 的统计信息，/debug/vars 中的格式为
+// "gocache": {"<group>": {"Gets": 0, "CacheHits": 0, ...}}
+func init() {
+	expvar.Publish("gocache", expvar.Func(func() interface{} {
+		mu.RLock()
+		defer mu.RUnlock()
+		all := make(map[string]Stats, len(groups))
+		for name, g := range groups {
+			all[name] = g.Stats()
+		}
+		return all
+	}))
+}
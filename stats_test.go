@@ -0,0 +1,35 @@
+package go_cache
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	gee := NewGroup("stats", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			if v, ok := db[key]; ok {
+				return []byte(v), nil
+			}
+			return nil, ErrNotFound
+		}))
+
+	gee.Get(context.Background(), "Tom")
+	gee.Get(context.Background(), "Tom")
+	gee.Get(context.Background(), "unknown")
+
+	want := Stats{Gets: 3, CacheHits: 1, Loads: 2, LoadsDeduped: 2, LocalLoads: 1, LocalLoadErrs: 1}
+	if s := gee.Stats(); s != want {
+		t.Fatalf("expected %+v, got %+v", want, s)
+	}
+
+	var all map[string]Stats
+	if err := json.Unmarshal([]byte(expvar.Get("gocache").String()), &all); err != nil {
+		t.Fatal(err)
+	}
+	if all["stats"] != want {
+		t.Fatalf("expvar should publish group stats, got %+v", all["stats"])
+	}
+}
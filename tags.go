@@ -0,0 +1,88 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tagIndex 标签与 key 之间的双向索引
+type tagIndex struct {
+	mu sync.Mutex
+	// 标签 -> 带有该标签的 key
+	keys map[string]map[string]struct{}
+	// key -> key 的标签
+	tags map[string][]string
+}
+
+// tag 把 key 的标签设置为 tags
+func (t *tagIndex) tag(key string, tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.untagLocked(key)
+	if len(tags) == 0 {
+		return
+	}
+	if t.keys == nil {
+		t.keys = make(map[string]map[string]struct{})
+		t.tags = make(map[string][]string)
+	}
+	for _, tag := range tags {
+		keys, ok := t.keys[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			t.keys[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	t.tags[key] = append([]string(nil), tags...)
+}
+
+// untag 删除 key 的所有标签，mainCache 中的记录被淘汰、过期或删除时调用
+func (t *tagIndex) untag(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.untagLocked(key)
+}
+
+func (t *tagIndex) untagLocked(key string) {
+	for _, tag := range t.tags[key] {
+		keys := t.keys[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(t.keys, tag)
+		}
+	}
+	delete(t.tags, key)
+}
+
+// keysOf 返回带有标签 tag 的所有 key
+func (t *tagIndex) keysOf(tag string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := make([]string, 0, len(t.keys[tag]))
+	for key := range t.keys[tag] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SetWithTags 与 Set 相同，同时把 key 的标签设置为 tags，之后可以通过 InvalidateTag 删除带有某个标签的所有 key。
+// 标签只在值保存在缓存中期间有效，普通的 Set 不会改变 key 已有的标签。
+func (g *Group) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := g.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	g.tags.tag(key, tags)
+	return nil
+}
+
+// InvalidateTag 从缓存中删除带有标签 tag 的所有 key，数据源中的数据不受影响
+func (g *Group) InvalidateTag(ctx context.Context, tag string) error {
+	for _, key := range g.tags.keysOf(tag) {
+		if err := g.Remove(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
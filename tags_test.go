@@ -0,0 +1,46 @@
+package go_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	store := &memStore{m: map[string]string{}}
+	gee := NewGroup("tags", 2<<10, store)
+	ctx := context.Background()
+
+	gee.SetWithTags(ctx, "user:123:profile", []byte("p"), 0, "user:123")
+	gee.SetWithTags(ctx, "user:123:orders", []byte("o"), 0, "user:123", "orders")
+	gee.SetWithTags(ctx, "user:456:orders", []byte("o"), 0, "user:456", "orders")
+
+	if err := gee.InvalidateTag(ctx, "user:123"); err != nil {
+		t.Fatal(err)
+	}
+	for key, cached := range map[string]bool{
+		"user:123:profile": false,
+		"user:123:orders":  false,
+		"user:456:orders":  true,
+	} {
+		if _, ok := gee.mainCache.get(key); ok != cached {
+			t.Fatalf("%s: expected cached=%v", key, cached)
+		}
+	}
+	if keys := gee.tags.keysOf("orders"); len(keys) != 1 || keys[0] != "user:456:orders" {
+		t.Fatalf("invalidated keys should be removed from other tags, got %v", keys)
+	}
+}
+
+func TestTagsCleanedOnExpire(t *testing.T) {
+	store := &memStore{m: map[string]string{}}
+	gee := NewGroup("tags-expire", 2<<10, store)
+	ctx := context.Background()
+
+	gee.SetWithTags(ctx, "k", []byte("v"), time.Millisecond, "t")
+	time.Sleep(5 * time.Millisecond)
+	gee.mainCache.get("k")
+	if keys := gee.tags.keysOf("t"); len(keys) != 0 {
+		t.Fatalf("expired key should be untagged, got %v", keys)
+	}
+}
@@ -0,0 +1,117 @@
+package tinylfu
+
+import "hash/fnv"
+
+// 计数器的最大值，与 4 bit 计数器一致
+const maxCount = 15
+
+// TinyLFU 准入策略，使用 count-min sketch 近似统计 key 的访问频次，
+// 在新记录需要挤掉旧记录时，只有新记录的频次更高才允许写入。
+// doorkeeper 是一个布隆过滤器，只访问过一次的 key 不会进入 sketch，
+// 从而过滤掉大量“一次性”访问，节省计数器空间。
+// TinyLFU 不是并发安全的。
+type TinyLFU struct {
+	// count-min sketch，depth 行 width 列
+	rows  [depth][]uint8
+	width uint64
+	// 布隆过滤器
+	door []uint64
+	// 累计记录次数，达到 sampleSize 时所有计数减半，使旧的热点逐渐冷却
+	additions  int
+	sampleSize int
+}
+
+const depth = 4
+
+// New 实例化 TinyLFU，counters 是预计需要统计的 key 数量
+func New(counters int) *TinyLFU {
+	width := uint64(1)
+	for width < uint64(counters) {
+		width <<= 1
+	}
+	t := &TinyLFU{
+		width:      width,
+		door:       make([]uint64, (width+63)/64),
+		sampleSize: 10 * int(width),
+	}
+	for i := range t.rows {
+		t.rows[i] = make([]uint8, width)
+	}
+	return t
+}
+
+// Record 记录一次 key 的访问
+func (t *TinyLFU) Record(key string) {
+	h1, h2 := hash(key)
+	if !t.doorkeeper(h1, h2, true) {
+		return
+	}
+	for i := range t.rows {
+		idx := (h1 + uint64(i)*h2) & (t.width - 1)
+		if t.rows[i][idx] < maxCount {
+			t.rows[i][idx]++
+		}
+	}
+	t.additions++
+	if t.additions >= t.sampleSize {
+		t.reset()
+	}
+}
+
+// Estimate 返回 key 访问频次的估计值
+func (t *TinyLFU) Estimate(key string) int {
+	h1, h2 := hash(key)
+	min := uint8(maxCount)
+	for i := range t.rows {
+		idx := (h1 + uint64(i)*h2) & (t.width - 1)
+		if t.rows[i][idx] < min {
+			min = t.rows[i][idx]
+		}
+	}
+	n := int(min)
+	if t.doorkeeper(h1, h2, false) {
+		n++
+	}
+	return n
+}
+
+// Admit 判断 candidate 是否可以挤掉 victim
+func (t *TinyLFU) Admit(candidate, victim string) bool {
+	return t.Estimate(candidate) > t.Estimate(victim)
+}
+
+// doorkeeper 判断 key 是否已经在布隆过滤器中，add 为 true 时顺便将其加入
+func (t *TinyLFU) doorkeeper(h1, h2 uint64, add bool) bool {
+	bits := uint64(len(t.door)) * 64
+	found := true
+	for i := uint64(0); i < 2; i++ {
+		bit := (h1 + i*h2 + i) % bits
+		if t.door[bit/64]&(1<<(bit%64)) == 0 {
+			found = false
+			if add {
+				t.door[bit/64] |= 1 << (bit % 64)
+			}
+		}
+	}
+	return found
+}
+
+func (t *TinyLFU) reset() {
+	t.additions = 0
+	for i := range t.rows {
+		for j := range t.rows[i] {
+			t.rows[i][j] >>= 1
+		}
+	}
+	for i := range t.door {
+		t.door[i] = 0
+	}
+}
+
+func hash(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	// 低位与高位作为两个独立的哈希值，h2 为奇数保证能遍历所有位置
+	return sum, (sum >> 32) | 1
+}
@@ -0,0 +1,31 @@
+package tinylfu
+
+import "testing"
+
+func TestEstimate(t *testing.T) {
+	lfu := New(64)
+	for i := 0; i < 5; i++ {
+		lfu.Record("hot")
+	}
+	lfu.Record("cold")
+	if lfu.Estimate("hot") <= lfu.Estimate("cold") {
+		t.Fatalf("hot should be estimated higher than cold")
+	}
+	if !lfu.Admit("hot", "cold") || lfu.Admit("cold", "hot") {
+		t.Fatalf("only hot should be admitted over cold")
+	}
+	if lfu.Estimate("unknown") != 0 {
+		t.Fatalf("unknown key should be estimated 0")
+	}
+}
+
+func TestReset(t *testing.T) {
+	lfu := New(1)
+	lfu.Record("key")
+	for i := 0; i < lfu.sampleSize; i++ {
+		lfu.Record("key")
+	}
+	if lfu.additions != 0 || lfu.Estimate("key") >= maxCount {
+		t.Fatalf("counters should be halved after sampleSize additions")
+	}
+}
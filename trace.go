@@ -0,0 +1,52 @@
+package go_cache
+
+import "context"
+
+// Source 值的来源
+type Source int
+
+const (
+	// SourceCache 来自本地的 mainCache，或缓存的 ErrNotFound 等错误
+	SourceCache Source = iota
+	// SourceHotCache 来自 hotCache，即其他节点负责的热点 key
+	SourceHotCache
+	// SourceBackend 缓存未命中，由本地的 Getter 加载
+	SourceBackend
+	// SourcePeer 缓存未命中，从其他节点加载
+	SourcePeer
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceCache:
+		return "cache"
+	case SourceHotCache:
+		return "hot_cache"
+	case SourceBackend:
+		return "backend"
+	case SourcePeer:
+		return "peer"
+	default:
+		return "unknown"
+	}
+}
+
+// Tracer 链路追踪的扩展点，Group.Get 开始时调用 StartGet，结束时调用返回的 GetSpan.End。
+// StartGet 返回的 ctx 会传给 Getter 和其他节点，用于传播追踪上下文。
+// go-cache/tracing 模块提供了基于 OpenTelemetry 的实现，核心包因此不依赖 OpenTelemetry。
+type Tracer interface {
+	StartGet(ctx context.Context, group, key string) (context.Context, GetSpan)
+}
+
+// GetSpan 一次 Group.Get 的追踪记录
+type GetSpan interface {
+	// End 结束记录，source 为值的来源，err 为 Get 返回的错误
+	End(source Source, err error)
+}
+
+// WithTracer 使用 t 追踪每次 Group.Get
+func WithTracer(t Tracer) GroupOption {
+	return func(g *Group) {
+		g.tracer = t
+	}
+}
@@ -0,0 +1,97 @@
+// Package tracing 基于 OpenTelemetry 的 go_cache.Tracer 实现。
+// 它是一个独立的 module，只有需要链路追踪的程序才会引入 OpenTelemetry 依赖：
+//
+//	g := gocache.NewGroup("scores", 2<<10, getter, gocache.WithTracer(tracing.New(nil)))
+package tracing
+
+import (
+	"context"
+	gocache "go-cache"
+	"hash/fnv"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "go-cache"
+
+// Tracer 为每次 Group.Get 创建一个名为 "gocache.Get" 的 span，
+// 属性包括 gocache.group、gocache.key_hash（key 的 FNV-1a 哈希，避免把 key 写入追踪系统）、
+// gocache.hit 和 gocache.source
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New 使用 tp 创建 Tracer，tp 为 nil 时使用 otel.GetTracerProvider()
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// StartGet 实现 go_cache.Tracer
+func (t *Tracer) StartGet(ctx context.Context, group, key string) (context.Context, gocache.GetSpan) {
+	ctx, span := t.tracer.Start(ctx, "gocache.Get",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("gocache.group", group),
+			attribute.Int64("gocache.key_hash", int64(keyHash(key))),
+		))
+	return ctx, getSpan{span}
+}
+
+type getSpan struct {
+	span trace.Span
+}
+
+// End 实现 go_cache.GetSpan
+func (s getSpan) End(source gocache.Source, err error) {
+	hit := (source == gocache.SourceCache || source == gocache.SourceHotCache) && err == nil
+	s.span.SetAttributes(
+		attribute.Bool("gocache.hit", hit),
+		attribute.String("gocache.source", source.String()),
+	)
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func keyHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Transport 包装 base，把 ctx 中的追踪上下文写入发往其他节点的请求头，base 为 nil 时使用 http.DefaultTransport
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return rt.base.RoundTrip(req)
+}
+
+// Handler 包装节点的 http.Handler，从请求头中恢复调用方的追踪上下文，
+// 之后的 Group.Get 以及 Getter 都在同一条链路中
+func Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	gocache "go-cache"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var getterSpan trace.SpanContext
+	g := gocache.NewGroup("tracing", 2<<10, gocache.GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			getterSpan = trace.SpanContextFromContext(ctx)
+			if key == "bad" {
+				return nil, errors.New("boom")
+			}
+			return []byte(key), nil
+		}), gocache.WithTracer(New(tp)))
+
+	g.Get(context.Background(), "Tom")
+	g.Get(context.Background(), "Tom")
+	g.Get(context.Background(), "bad")
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	if !getterSpan.IsValid() {
+		t.Fatalf("span context should be propagated into the Getter")
+	}
+	for i, want := range []struct {
+		source string
+		hit    bool
+	}{{"backend", false}, {"cache", true}, {"backend", false}} {
+		s := spans[i]
+		if s.Name() != "gocache.Get" || attr(s, "gocache.group").AsString() != "tracing" {
+			t.Fatalf("unexpected span %s %v", s.Name(), s.Attributes())
+		}
+		if attr(s, "gocache.source").AsString() != want.source || attr(s, "gocache.hit").AsBool() != want.hit {
+			t.Fatalf("span %d: unexpected attributes %v", i, s.Attributes())
+		}
+	}
+	if st := spans[2].Status(); st.Code != codes.Error || !strings.HasSuffix(st.Description, "boom") {
+		t.Fatalf("error should be recorded, got %v", spans[2].Status())
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "client")
+	defer span.End()
+
+	var got trace.SpanContext
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = trace.SpanContextFromContext(r.Context())
+	})))
+	defer srv.Close()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got.TraceID() != span.SpanContext().TraceID() {
+		t.Fatalf("trace context should be propagated to the peer")
+	}
+}
@@ -0,0 +1,92 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// TypedGetter 缓存未命中时加载 T 类型的源数据
+type TypedGetter[T any] interface {
+	Get(ctx context.Context, key string) (T, error)
+}
+
+// TypedGetterFunc 函数类型实现 TypedGetter 接口
+type TypedGetterFunc[T any] func(ctx context.Context, key string) (T, error)
+
+// Get 实现 TypedGetter 接口
+func (f TypedGetterFunc[T]) Get(ctx context.Context, key string) (T, error) {
+	return f(ctx, key)
+}
+
+// TypedSetter 可选接口，TypedGetter 同时实现 TypedSetter 时，TypedGroup.Set 先写入数据源再更新缓存
+type TypedSetter[T any] interface {
+	Set(ctx context.Context, key string, value T, ttl time.Duration) error
+}
+
+// TypedGroup 值类型为 T 的 Group，使用 Group 的 Codec（见 WithCodec）在 T 与缓存的字节之间转换
+type TypedGroup[T any] struct {
+	g *Group
+}
+
+// NewTypedGroup 创建名为 name 的 TypedGroup，参数与 NewGroup 相同
+func NewTypedGroup[T any](name string, cacheBytes int64, getter TypedGetter[T], opts ...GroupOption) *TypedGroup[T] {
+	if getter == nil {
+		panic("nil Getter")
+	}
+	a := &typedGetter[T]{getter: getter}
+	var bg Getter = a
+	if s, ok := getter.(TypedSetter[T]); ok {
+		bg = &typedGetterSetter[T]{typedGetter: a, setter: s}
+	}
+	a.g = NewGroup(name, cacheBytes, bg, opts...)
+	return &TypedGroup[T]{g: a.g}
+}
+
+// Group 返回底层的 Group
+func (t *TypedGroup[T]) Group() *Group {
+	return t.g
+}
+
+// Get 获取 key 对应的值
+func (t *TypedGroup[T]) Get(ctx context.Context, key string) (T, error) {
+	var v T
+	err := t.g.GetInto(ctx, key, &v)
+	return v, err
+}
+
+// Set 写入 key 对应的值，有效期与 WithTTL 相同，getter 需要实现 TypedSetter
+func (t *TypedGroup[T]) Set(ctx context.Context, key string, value T) error {
+	b, err := t.g.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return t.g.Set(ctx, key, b, 0)
+}
+
+// typedGetter 把 TypedGetter 适配为 Getter
+type typedGetter[T any] struct {
+	g      *Group
+	getter TypedGetter[T]
+}
+
+func (a *typedGetter[T]) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := a.getter.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return a.g.codec.Marshal(v)
+}
+
+// typedGetterSetter 把 TypedSetter 适配为 Setter
+type typedGetterSetter[T any] struct {
+	*typedGetter[T]
+	setter TypedSetter[T]
+}
+
+func (a *typedGetterSetter[T]) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var v T
+	if err := a.g.codec.Unmarshal(value, &v); err != nil {
+		return err
+	}
+	return a.setter.Set(ctx, key, v, ttl)
+}
@@ -0,0 +1,59 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type scoreStore struct {
+	mu sync.Mutex
+	m  map[string]score
+}
+
+func (s *scoreStore) Get(ctx context.Context, key string) (score, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[key]
+	if !ok {
+		return score{}, ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *scoreStore) Set(ctx context.Context, key string, value score, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+
+func TestTypedGroup(t *testing.T) {
+	store := &scoreStore{m: map[string]score{"Tom": {Name: "Tom", Score: 630}}}
+	tg := NewTypedGroup[score]("typed", 2<<10, store, WithCodec(GobCodec))
+
+	if v, err := tg.Get(context.Background(), "Tom"); err != nil || v.Score != 630 {
+		t.Fatalf("unexpected value %+v %v", v, err)
+	}
+	if err := tg.Set(context.Background(), "Jack", score{Name: "Jack", Score: 589}); err != nil {
+		t.Fatal(err)
+	}
+	if store.m["Jack"].Score != 589 {
+		t.Fatalf("value should be written to the backend")
+	}
+	if v, err := tg.Get(context.Background(), "Jack"); err != nil || v.Score != 589 {
+		t.Fatalf("unexpected value %+v %v", v, err)
+	}
+
+	readOnly := NewTypedGroup[int]("typed-readonly", 2<<10, TypedGetterFunc[int](
+		func(ctx context.Context, key string) (int, error) {
+			return len(key), nil
+		}))
+	if v, _ := readOnly.Get(context.Background(), "four"); v != 4 {
+		t.Fatalf("unexpected value %d", v)
+	}
+	if err := readOnly.Set(context.Background(), "k", 1); err != ErrNoSetter {
+		t.Fatalf("expected ErrNoSetter, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// keyLockStripes Group.keyLocks 的段数
+const keyLockStripes = 256
+
+// Cas 只有 key 在本节点缓存中的版本等于 expected 时才写入 value，返回新值的版本；
+// expected 为 0 表示 key 不在缓存中。版本不一致时返回 ErrVersionMismatch 以及当前的版本，
+// 调用方可以重新读取（GetWithInfo 返回的 ItemInfo.Version）之后重试。
+// 写入的过程与 Set 相同，但是不经过 WithSetMiddleware 添加的中间件。
+// 版本只在本节点上比较，集群中应当在负责 key 的节点上调用 Cas。
+func (g *Group) Cas(ctx context.Context, key string, expected int64, value []byte, ttl time.Duration) (int64, error) {
+	if err := g.checkKey(key); err != nil {
+		return 0, err
+	}
+	mu := g.keyLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	if current := g.mainCache.version(key); current != expected {
+		return current, ErrVersionMismatch
+	}
+	return g.setLocked(ctx, key, value, ttl)
+}
+
+// nextVersion 分配一个新的版本：当前时间（Unix 纳秒），不大于已经分配或者见过的版本时使用它加 1，
+// 保证本节点的版本单调递增，并且不低于从其他节点同步过来的版本
+func (g *Group) nextVersion() int64 {
+	for {
+		last := atomic.LoadInt64(&g.clock)
+		v := time.Now().UnixNano()
+		if v <= last {
+			v = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&g.clock, last, v) {
+			return v
+		}
+	}
+}
+
+// observeVersion 记录从其他节点同步过来的版本，之后分配的版本都比它大
+func (g *Group) observeVersion(v int64) {
+	for {
+		last := atomic.LoadInt64(&g.clock)
+		if v <= last || atomic.CompareAndSwapInt64(&g.clock, last, v) {
+			return
+		}
+	}
+}
+
+// keyLock 返回 key 所在段的锁
+func (g *Group) keyLock(key string) *sync.Mutex {
+	return &g.keyLocks[hashKey(key)%keyLockStripes]
+}
@@ -0,0 +1,94 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"go-cache/gocachepb"
+	"testing"
+)
+
+func TestCas(t *testing.T) {
+	store := &memStore{m: map[string]string{"Tom": "630"}}
+	gee := NewGroup("cas", 2<<10, store)
+	ctx := context.Background()
+
+	// 不在缓存中时 expected 为 0
+	if _, err := gee.Cas(ctx, "Tom", 1, []byte("631"), 0); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch for uncached key, got %v", err)
+	}
+	_, info, err := gee.GetWithInfo(ctx, "Tom")
+	if err != nil || info.Version == 0 {
+		t.Fatalf("loaded value should have a version, got %d, %v", info.Version, err)
+	}
+	version, err := gee.Cas(ctx, "Tom", info.Version, []byte("631"), 0)
+	if err != nil || version <= info.Version {
+		t.Fatalf("cas should succeed with a newer version, got %d, %v", version, err)
+	}
+	if v, _ := store.Get(ctx, "Tom"); string(v) != "631" {
+		t.Fatalf("cas should write through to the store, got %q", v)
+	}
+	current, err := gee.Cas(ctx, "Tom", info.Version, []byte("632"), 0)
+	if !errors.Is(err, ErrVersionMismatch) || current != version {
+		t.Fatalf("stale cas should fail with the current version, got %d, %v", current, err)
+	}
+	if v, _ := gee.Get(ctx, "Tom"); v.String() != "631" {
+		t.Fatalf("failed cas should not change the value, got %q", v)
+	}
+
+	if _, err := gee.Cas(ctx, "Jack", 0, []byte("589"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := gee.Get(ctx, "Jack"); v.String() != "589" {
+		t.Fatalf("cas with expected 0 should insert, got %q", v)
+	}
+}
+
+func TestServePeerSetVersion(t *testing.T) {
+	gee := NewGroup("peer-set-version", 2<<10, &memStore{m: map[string]string{}})
+	ctx := context.Background()
+	set := func(value string, version int64) {
+		t.Helper()
+		req := &gocachepb.SetRequest{Key: "Tom", Value: []byte(value), Version: version}
+		if err := gee.ServePeerSet(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set("new", 200)
+	set("old", 100)
+	if v, _ := gee.Peek("Tom"); v.String() != "new" {
+		t.Fatalf("out-of-order update should be ignored, got %q", v)
+	}
+	set("same", 200)
+	if v, _ := gee.Peek("Tom"); v.String() != "new" {
+		t.Fatalf("update with the same version should be ignored, got %q", v)
+	}
+	set("newer", 300)
+	if v, _ := gee.Peek("Tom"); v.String() != "newer" {
+		t.Fatalf("newer update should be applied, got %q", v)
+	}
+	// 没有版本的写入总是生效，并且分配的新版本大于见过的版本
+	set("unversioned", 0)
+	if v, _ := gee.Peek("Tom"); v.String() != "unversioned" {
+		t.Fatalf("unversioned update should be applied, got %q", v)
+	}
+	if version := gee.mainCache.version("Tom"); version <= 300 {
+		t.Fatalf("local version should be above observed versions, got %d", version)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	gee := NewGroup("next-version", 2<<10, &memStore{m: map[string]string{}})
+	gee.observeVersion(1 << 62)
+	last := gee.nextVersion()
+	if last <= 1<<62 {
+		t.Fatalf("version should exceed the observed clock, got %d", last)
+	}
+	for i := 0; i < 1000; i++ {
+		v := gee.nextVersion()
+		if v <= last {
+			t.Fatalf("versions should increase, got %d after %d", v, last)
+		}
+		last = v
+	}
+}
@@ -0,0 +1,97 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WarmResult Warm 的结果
+type WarmResult struct {
+	// 加载成功（或已经在缓存中）的 key 数量
+	Loaded int
+	// 数据源中不存在的 key 数量
+	NotFound int
+	// 加载失败的 key 数量
+	Failed int
+}
+
+// WithWarmProgress Warm 每处理完一个 key 调用一次 progress，done 为已处理的 key 数量，total 为总数
+func WithWarmProgress(progress func(done, total int)) GroupOption {
+	return func(g *Group) {
+		g.warmProgress = progress
+	}
+}
+
+// warmRetryInterval 加载被 WithMaxConcurrentLoads 限流时，Warm 重试的间隔
+const warmRetryInterval = 10 * time.Millisecond
+
+// Warm 使用 concurrency 个协程通过 Get 预先加载 keys，用于启动时预热缓存。
+// 与普通的 Get 一样会合并相同 key 的并发加载；被 WithMaxConcurrentLoads 限流时等待后重试，而不是算作失败。
+// 单个 key 加载失败不会中止预热，ctx 结束时停止并返回 ctx.Err()。
+func (g *Group) Warm(ctx context.Context, keys []string, concurrency int) (WarmResult, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var (
+		mu     sync.Mutex
+		result WarmResult
+		done   int
+		wg     sync.WaitGroup
+	)
+	ch := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range ch {
+				err := g.warmKey(ctx, key)
+				if ctx.Err() != nil {
+					continue
+				}
+				mu.Lock()
+				switch {
+				case err == nil:
+					result.Loaded++
+				case errors.Is(err, ErrNotFound):
+					result.NotFound++
+				default:
+					result.Failed++
+				}
+				done++
+				if g.warmProgress != nil {
+					g.warmProgress(done, len(keys))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		select {
+		case ch <- key:
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	close(ch)
+	wg.Wait()
+	return result, ctx.Err()
+}
+
+// warmKey 加载 key，被限流时等待后重试
+func (g *Group) warmKey(ctx context.Context, key string) error {
+	for {
+		_, err := g.Get(ctx, key)
+		if !errors.Is(err, ErrLoadThrottled) {
+			return err
+		}
+		select {
+		case <-time.After(warmRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarm(t *testing.T) {
+	var loads int32
+	var last int32
+	progress := func(done, total int) {
+		atomic.StoreInt32(&last, int32(done))
+	}
+	gee := NewGroup("warm", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			time.Sleep(time.Millisecond)
+			switch key {
+			case "missing":
+				return nil, ErrNotFound
+			case "broken":
+				return nil, errors.New("boom")
+			}
+			return []byte(key), nil
+		}), WithMaxConcurrentLoads(2), WithWarmProgress(progress))
+
+	keys := []string{"missing", "broken"}
+	for i := 0; i < 20; i++ {
+		keys = append(keys, fmt.Sprintf("key%d", i))
+	}
+	result, err := gee.Warm(context.Background(), keys, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (WarmResult{Loaded: 20, NotFound: 1, Failed: 1}) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if last != int32(len(keys)) {
+		t.Fatalf("progress should reach %d, got %d", len(keys), last)
+	}
+	if _, ok := gee.mainCache.get("key0"); !ok {
+		t.Fatalf("warmed keys should be cached")
+	}
+	if loads != int32(len(keys)) {
+		t.Fatalf("throttled loads should be retried, got %d loads", loads)
+	}
+}
+
+func TestWarmCanceled(t *testing.T) {
+	gee := NewGroup("warm-canceled", 2<<10, GetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := gee.Warm(ctx, []string{"Tom", "Jack"}, 1); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package go_cache
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteEntry 一条待写入数据源的记录
+type WriteEntry struct {
+	Key   string
+	Value []byte
+	TTL   time.Duration
+	// 写入的值在缓存中的版本
+	version int64
+}
+
+// BatchSetter 可选接口，write-behind 模式下 Getter 实现了 BatchSetter 时按批写入数据源，
+// 否则逐条调用 Setter.Set。write-behind 模式下只实现 BatchSetter、不实现 Setter 也可以
+type BatchSetter interface {
+	SetMulti(ctx context.Context, entries []WriteEntry) error
+}
+
+// WriteBehindConfig write-behind 模式的配置，零值字段使用默认值
+type WriteBehindConfig struct {
+	// 缓冲区最多容纳的写入条数，写满后 Set 返回 ErrWriteBufferFull，默认 1024
+	BufferSize int
+	// 每批写入的最大条数，默认 100
+	BatchSize int
+	// 缓冲区未攒满一批时，最长等待多久写入一次，默认 100ms
+	FlushInterval time.Duration
+	// 后台写入协程的数量，默认 1。每个 key 总是由同一个协程写入，保证同一个 key 的写入按顺序到达数据源，
+	// 缓冲区平均分给每个协程
+	Workers int
+	// 写入失败后的最大重试次数，默认 3
+	MaxRetries int
+	// 第一次重试前的等待时间，之后每次翻倍，默认 10ms
+	RetryBackoff time.Duration
+	// Close 时丢弃尚未写入的数据，默认会等待全部写完
+	DropOnClose bool
+	// 重试之后仍然写入失败时调用，缓存中对应的值此时已被删除（之后又有新的写入时保留新的值）
+	OnError func(key string, err error)
+}
+
+// WithWriteBehind Set 只更新缓存并把写入放入缓冲区，由后台协程批量写入数据源后立即返回，
+// 适合计数器之类写入频繁、不要求立即落盘的数据。同一批中相同 key 的多次写入只保留最后一次。
+// 代价是进程退出前未写入的数据可能丢失，使用完毕后需要调用 Group.Close。
+func WithWriteBehind(cfg WriteBehindConfig) GroupOption {
+	return func(g *Group) {
+		g.writeBehind = newWriteBehind(g, cfg)
+	}
+}
+
+// writeBehind 缓冲写入并在后台批量写入数据源
+type writeBehind struct {
+	g   *Group
+	cfg WriteBehindConfig
+	// 每个后台协程的缓冲区，key 按哈希值固定分配给其中一个
+	queues []chan WriteEntry
+	// 保护 queues 的关闭，Set 持有读锁写入，Close 持有写锁关闭
+	mu       sync.RWMutex
+	closed   bool
+	dropping int32
+	wg       sync.WaitGroup
+}
+
+func newWriteBehind(g *Group, cfg WriteBehindConfig) *writeBehind {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 100 * time.Millisecond
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 10 * time.Millisecond
+	}
+	w := &writeBehind{
+		g:      g,
+		cfg:    cfg,
+		queues: make([]chan WriteEntry, cfg.Workers),
+	}
+	size := (cfg.BufferSize + cfg.Workers - 1) / cfg.Workers
+	w.wg.Add(cfg.Workers)
+	for i := range w.queues {
+		w.queues[i] = make(chan WriteEntry, size)
+		go w.run(w.queues[i])
+	}
+	return w
+}
+
+// queue 返回负责 key 的后台协程的缓冲区
+func (w *writeBehind) queue(key string) chan WriteEntry {
+	if len(w.queues) == 1 {
+		return w.queues[0]
+	}
+	return w.queues[hashKey(key)%uint64(len(w.queues))]
+}
+
+// enqueue 把写入放入缓冲区，不会阻塞
+func (w *writeBehind) enqueue(e WriteEntry) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrGroupClosed
+	}
+	select {
+	case w.queue(e.Key) <- e:
+		return nil
+	default:
+		return ErrWriteBufferFull
+	}
+}
+
+func (w *writeBehind) run(queue <-chan WriteEntry) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]WriteEntry, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case e, ok := <-queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush 合并一批写入中相同的 key 后写入数据源，失败时按指数退避重试
+func (w *writeBehind) flush(batch []WriteEntry) {
+	if len(batch) == 0 || atomic.LoadInt32(&w.dropping) == 1 {
+		return
+	}
+	index := make(map[string]int, len(batch))
+	entries := make([]WriteEntry, 0, len(batch))
+	for _, e := range batch {
+		if i, ok := index[e.Key]; ok {
+			entries[i] = e
+			continue
+		}
+		index[e.Key] = len(entries)
+		entries = append(entries, e)
+	}
+
+	backoff := w.cfg.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		entries, err = w.write(entries)
+		if len(entries) == 0 {
+			return
+		}
+		if attempt == w.cfg.MaxRetries || atomic.LoadInt32(&w.dropping) == 1 {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	for _, e := range entries {
+		// 数据源中仍是旧值，删除缓存中的新值以免两者长期不一致，
+		// 这批数据取出之后又有新的写入时保留缓存中更新的值，它仍在缓冲区中等待写入
+		w.g.mainCache.removeUnlessNewer(e.Key, e.version)
+		w.g.hotCache.removeUnlessNewer(e.Key, e.version)
+		if w.cfg.OnError != nil {
+			w.cfg.OnError(e.Key, err)
+		} else {
+			log.Println("[GeeCache] write-behind failed for", e.Key, err)
+		}
+	}
+}
+
+// write 写入一批记录，返回写入失败的记录和最后一个错误
+func (w *writeBehind) write(entries []WriteEntry) ([]WriteEntry, error) {
+	ctx := context.Background()
+	if bs, ok := w.g.getter.(BatchSetter); ok {
+		if err := bs.SetMulti(ctx, entries); err != nil {
+			return entries, err
+		}
+		return nil, nil
+	}
+	setter := w.g.getter.(Setter)
+	var failed []WriteEntry
+	var lastErr error
+	for _, e := range entries {
+		if err := setter.Set(ctx, e.Key, e.Value, e.TTL); err != nil {
+			failed = append(failed, e)
+			lastErr = err
+		}
+	}
+	return failed, lastErr
+}
+
+// close 停止接收新的写入，等待后台协程处理完缓冲区，ctx 结束时提前返回 ctx.Err()
+func (w *writeBehind) close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		if w.cfg.DropOnClose {
+			atomic.StoreInt32(&w.dropping, 1)
+		}
+		for _, queue := range w.queues {
+			close(queue)
+		}
+	}
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,254 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchStore struct {
+	memStore
+	batches [][]WriteEntry
+	fail    int
+}
+
+func (s *batchStore) SetMulti(ctx context.Context, entries []WriteEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("backend unavailable")
+	}
+	s.batches = append(s.batches, entries)
+	for _, e := range entries {
+		s.m[e.Key] = string(e.Value)
+	}
+	return nil
+}
+
+func TestWriteBehind(t *testing.T) {
+	store := &batchStore{memStore: memStore{m: map[string]string{}}, fail: 1}
+	gee := NewGroup("write-behind", 2<<10, store, WithWriteBehind(WriteBehindConfig{
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	}))
+
+	for i := 0; i < 5; i++ {
+		if err := gee.Set(context.Background(), "counter", []byte{byte('0' + i)}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gee.Set(context.Background(), "other", []byte("x"), 0)
+	if v, _ := gee.Get(context.Background(), "counter"); v.String() != "4" {
+		t.Fatalf("cache should be updated immediately, got %q", v.String())
+	}
+	store.mu.Lock()
+	n := len(store.m)
+	store.mu.Unlock()
+	if n != 0 {
+		t.Fatalf("backend should not be written before flush")
+	}
+
+	if err := gee.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.batches) != 1 || len(store.batches[0]) != 2 {
+		t.Fatalf("writes should be coalesced into one batch, got %v", store.batches)
+	}
+	if store.m["counter"] != "4" || store.m["other"] != "x" {
+		t.Fatalf("unexpected backend state %v", store.m)
+	}
+	if err := gee.Set(context.Background(), "counter", []byte("5"), 0); err != ErrGroupClosed {
+		t.Fatalf("expected ErrGroupClosed, got %v", err)
+	}
+}
+
+func TestWriteBehindFailure(t *testing.T) {
+	store := &batchStore{memStore: memStore{m: map[string]string{}}, fail: 100}
+	var mu sync.Mutex
+	var failed []string
+	gee := NewGroup("write-behind-failure", 2<<10, store, WithWriteBehind(WriteBehindConfig{
+		BufferSize:    2,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+		RetryBackoff:  time.Millisecond,
+		OnError: func(key string, err error) {
+			mu.Lock()
+			failed = append(failed, key)
+			mu.Unlock()
+		},
+	}))
+
+	gee.Set(context.Background(), "k", []byte("v"), 0)
+	gee.Close(context.Background())
+	if len(failed) != 1 || failed[0] != "k" {
+		t.Fatalf("OnError should be called after retries, got %v", failed)
+	}
+	if _, ok := gee.mainCache.get("k"); ok {
+		t.Fatalf("unpersisted value should be removed from cache")
+	}
+	if store.fail != 97 {
+		t.Fatalf("expected 3 attempts, got %d", 100-store.fail)
+	}
+}
+
+// gatedStore 第一次写入时通知 started，并等待 release 之后再写入
+type gatedStore struct {
+	batchStore
+	once     sync.Once
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (s *gatedStore) SetMulti(ctx context.Context, entries []WriteEntry) error {
+	s.once.Do(func() {
+		close(s.started)
+		<-s.released
+	})
+	return s.batchStore.SetMulti(ctx, entries)
+}
+
+func TestWriteBehindFailureKeepsNewerValue(t *testing.T) {
+	store := &gatedStore{
+		batchStore: batchStore{memStore: memStore{m: map[string]string{}}, fail: 3},
+		started:    make(chan struct{}),
+		released:   make(chan struct{}),
+	}
+	gee := NewGroup("write-behind-newer", 2<<10, store, WithWriteBehind(WriteBehindConfig{
+		BatchSize:    1,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		OnError:      func(key string, err error) {},
+	}))
+
+	gee.Set(context.Background(), "k", []byte("v1"), 0)
+	<-store.started
+	// v1 正在写入时又有新的写入，v1 最终失败时不应删除缓存中的 v2
+	gee.Set(context.Background(), "k", []byte("v2"), 0)
+	close(store.released)
+	if err := gee.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := gee.mainCache.get("k"); !ok || v.String() != "v2" {
+		t.Fatalf("newer value should stay in cache, got %q, %v", v, ok)
+	}
+	if store.m["k"] != "v2" {
+		t.Fatalf("newer value should be written to the backend, got %q", store.m["k"])
+	}
+}
+
+// batchOnlyStore 只实现 Getter 和 BatchSetter
+type batchOnlyStore struct {
+	getter Getter
+	store  *batchStore
+}
+
+func (s batchOnlyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.getter.Get(ctx, key)
+}
+
+func (s batchOnlyStore) SetMulti(ctx context.Context, entries []WriteEntry) error {
+	return s.store.SetMulti(ctx, entries)
+}
+
+func TestWriteBehindBatchSetterOnly(t *testing.T) {
+	store := &batchStore{memStore: memStore{m: map[string]string{}}}
+	getter := batchOnlyStore{getter: &store.memStore, store: store}
+	gee := NewGroup("write-behind-batch-only", 2<<10, getter, WithWriteBehind(WriteBehindConfig{}))
+	if err := gee.Set(context.Background(), "k", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	gee.Close(context.Background())
+	if store.m["k"] != "v" {
+		t.Fatalf("BatchSetter should be enough in write-behind mode, got %v", store.m)
+	}
+
+	plain := NewGroup("batch-only", 2<<10, getter)
+	if err := plain.Set(context.Background(), "k", []byte("v"), 0); err != ErrNoSetter {
+		t.Fatalf("expected ErrNoSetter without write-behind, got %v", err)
+	}
+}
+
+func TestWriteBehindBufferFull(t *testing.T) {
+	store := &memStore{m: map[string]string{}}
+	block := make(chan struct{})
+	gee := NewGroup("write-behind-full", 2<<10, &blockingStore{store, block}, WithWriteBehind(WriteBehindConfig{
+		BufferSize:  1,
+		BatchSize:   1,
+		DropOnClose: true,
+	}))
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = gee.Set(context.Background(), "k", []byte("v"), 0)
+	}
+	if err != ErrWriteBufferFull {
+		t.Fatalf("expected ErrWriteBufferFull, got %v", err)
+	}
+	close(block)
+	gee.Close(context.Background())
+}
+
+type blockingStore struct {
+	*memStore
+	block chan struct{}
+}
+
+func (s *blockingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	<-s.block
+	return s.memStore.Set(ctx, key, value, ttl)
+}
+
+// historyStore 记录每个 key 按顺序写入的值，写入时随机等待一段时间以便暴露乱序
+type historyStore struct {
+	memStore
+	history map[string][]string
+}
+
+func (s *historyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = string(value)
+	s.history[key] = append(s.history[key], string(value))
+	return nil
+}
+
+func TestWriteBehindOrdering(t *testing.T) {
+	store := &historyStore{memStore: memStore{m: map[string]string{}}, history: map[string][]string{}}
+	gee := NewGroup("write-behind-ordering", 2<<10, store, WithWriteBehind(WriteBehindConfig{
+		BufferSize: 4096,
+		BatchSize:  1,
+		Workers:    4,
+	}))
+	const n = 200
+	for i := 0; i < n; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			if err := gee.Set(context.Background(), key, []byte(strconv.Itoa(i)), 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := gee.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		history := store.history[key]
+		for i := 1; i < len(history); i++ {
+			prev, _ := strconv.Atoi(history[i-1])
+			cur, _ := strconv.Atoi(history[i])
+			if cur <= prev {
+				t.Fatalf("%s: %d was written after %d", key, cur, prev)
+			}
+		}
+		if store.m[key] != strconv.Itoa(n-1) {
+			t.Fatalf("%s: backend should hold the last value, got %q", key, store.m[key])
+		}
+	}
+}